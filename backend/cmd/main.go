@@ -4,24 +4,38 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"t/internal/attendance"
+	"t/internal/audit"
 	"t/internal/auth"
+	"t/internal/availability"
 	"t/internal/booking"
+	"t/internal/closure"
 	"t/internal/config"
+	"t/internal/credit"
 	"t/internal/facility"
+	"t/internal/holiday"
+	"t/internal/mailer"
+	"t/internal/notification"
 	"t/internal/penalty"
+	"t/internal/policy"
+	"t/internal/push"
 	"t/internal/registration"
 	"t/internal/review"
 	"t/internal/schedule"
+	"t/internal/scheduler"
 	"t/internal/session"
+	"t/internal/sms"
 	"t/internal/trainer"
 	"t/internal/transport/http"
 	"t/internal/user"
 	pg "t/pkg/postgres"
+	"time"
+
+	"go.uber.org/zap"
 )
 
 func main() {
 	cfg := config.Load()
-	fmt.Println(cfg)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -29,48 +43,243 @@ func main() {
 	dbConnString := fmt.Sprintf("postgres://%s:%s@%s:%s/%s", cfg.DBUser, cfg.DBPass, cfg.DBHost, cfg.DBPort, cfg.DBName)
 	pGpool := pg.New(ctx, dbConnString)
 	log.Printf("connected to database")
+
+	logger, _ := zap.NewProduction()
+	defer logger.Sync()
+
+	//only settings that are safe to show, the config also holds the passwords, tokens and the JWT key
+	logger.Info("configuration loaded",
+		zap.String("db_host", cfg.DBHost),
+		zap.String("db_name", cfg.DBName),
+		zap.String("app_url", cfg.AppURL),
+		zap.String("mail_provider", cfg.MailProvider),
+		zap.String("sms_provider", cfg.SMSProvider),
+		zap.String("late_cancel_mode", cfg.LateCancelMode),
+	)
+
+	//every service writes its changes to the audit log
+	auditRep := audit.NewAuditRepositoryPostgres(pGpool)
+	auditSrv := audit.NewAuditService(auditRep, logger)
+
+	//emails go to the local outbox unless MAIL_PROVIDER=smtp
+	var mail mailer.Mailer
+	switch cfg.MailProvider {
+	case "smtp":
+		mail = mailer.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUser, cfg.SMTPPass, cfg.MailFrom)
+	default:
+		outbox, err := mailer.NewOutboxMailer(cfg.MailOutboxDir, cfg.MailFrom, logger)
+		if err != nil {
+			log.Fatal(err)
+		}
+		mail = outbox
+	}
+
+	//text messages go to the local outbox unless SMS_PROVIDER=http
+	var texts sms.Sender
+	switch cfg.SMSProvider {
+	case "http":
+		texts = sms.NewHTTPSender(cfg.SMSWebhookURL, cfg.SMSWebhookToken)
+	default:
+		outbox, err := sms.NewOutboxSender(cfg.SMSOutboxDir, logger)
+		if err != nil {
+			log.Fatal(err)
+		}
+		texts = outbox
+	}
+
+	//push notifications only go to the local outbox, there is no provider yet
+	pushes, err := push.NewOutboxSender(cfg.PushOutboxDir, logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	//in-app inbox, the services drop their messages here and the delivery job sends them on the other channels
+	notificationRep := notification.NewNotificationRepositoryPostgres(pGpool)
+	notificationSrv := notification.NewNotificationService(notificationRep, []notification.Channel{
+		notification.NewEmailChannel(mail),
+		notification.NewSMSChannel(texts),
+		notification.NewPushChannel(pushes),
+	}, notification.DeliveryConfig{
+		MaxAttempts:  cfg.NotifyMaxAttempts,
+		RetryBackoff: cfg.NotifyRetryBackoff,
+		MaxBackoff:   cfg.NotifyRetryMax,
+		BatchSize:    cfg.NotifyDeliveryBatch,
+		ClaimTimeout: cfg.NotifyClaimTimeout,
+		Defaults: map[notification.ChannelName]bool{
+			notification.ChannelEmail: cfg.NotifyEmailDefault,
+			notification.ChannelSMS:   cfg.NotifySMSDefault,
+			notification.ChannelPush:  cfg.NotifyPushDefault,
+		},
+	}, logger)
+
 	//creating user repo/service
 	userRepo := user.NewUserRepositotyPostgres(pGpool)
-	userSrvs := user.NewUserService(userRepo)
+	userSrvs := user.NewUserService(userRepo, auditSrv)
 
 	//creating auth service
-
-	//to-do  (change to read key from the .env)
-	authSrv := auth.NewAuthSerivce(cfg.JWTKey, userRepo)
+	sessionAuthRepo := auth.NewSessionRepositoryPostgres(pGpool)
+	userTokenRepo := auth.NewUserTokenRepositoryPostgres(pGpool)
+	authSrv := auth.NewAuthSerivce(cfg.JWTKey, userRepo, sessionAuthRepo, userTokenRepo, mail, cfg.AppURL, cfg.AccessTokenTTL, cfg.RefreshTokenTTL, cfg.RequireEmailVerification, auditSrv)
 
 	//create facility
 	facilRep := facility.NewFacilityRepositoryPostgres(pGpool)
-	facilSrv := facility.NewFacilityService(facilRep)
+	facilSrv := facility.NewFacilityService(facilRep, auditSrv)
+
+	//free and busy intervals of the facilities
+	availabilityRep := availability.NewAvailabilityRepositoryPostgres(pGpool)
+	availabilitySrv := availability.NewAvailabilityService(availabilityRep, facilRep, availability.Config{
+		SlotMinutes:  cfg.AvailabilitySlotMinutes,
+		MaxRangeDays: cfg.AvailabilityMaxRangeDays,
+	})
+
+	//create booking policies
+	policyRep := policy.NewPolicyRepositoryPostgres(pGpool)
+	policySrv := policy.NewPolicyService(policyRep, auditSrv)
+
+	//owners canceling late are refused or penalized, for bookings and registrations alike
+	penaltyRep := penalty.NewPenaltyRepositoryPostgres(pGpool)
+	lateCancelMode, err := penalty.ParseCancelMode(cfg.LateCancelMode)
+	if err != nil {
+		log.Fatal(err)
+	}
+	cancelPolicy := penalty.CancelPolicy{
+		Cutoff: cfg.CancelCutoff,
+		Mode:   lateCancelMode,
+		Points: cfg.LateCancelPenaltyPoints,
+	}
 
 	//create bookings
 	bookingRep := booking.NewBookingRepositoryPostgres(pGpool)
-	bookingSrv := booking.NewBookingService(bookingRep)
+	bookingSrv := booking.NewBookingService(bookingRep, facilRep, policyRep, penaltyRep, availabilityRep, cancelPolicy, auditSrv, notificationSrv)
 
 	//create reviews
 	reviewRep := review.NewReviewRepositoryPostgres(pGpool)
-	reviewSrv := review.NewReviewService(reviewRep)
+	reviewSrv := review.NewReviewService(reviewRep, auditSrv)
 
 	//create trainer
 	trainerRep := trainer.NewTrainerRepositoryPostgres(pGpool)
-	trainerSrv := trainer.NewTrainerService(trainerRep)
+	trainerSrv := trainer.NewTrainerService(trainerRep, auditSrv)
 
 	//create session
 	sessionRep := session.NewSessionRepositoryPostgres(pGpool)
-	sessionSrv := session.NewSessionService(sessionRep)
+	sessionSrv := session.NewSessionService(sessionRep, availabilityRep, cfg.SessionHorizonWeeks, auditSrv, notificationSrv)
 
 	//create schedule
 	scheduleRep := schedule.NewScheduleRepositoryPostgres(pGpool)
-	scheduleSrv := schedule.NewScheduleService(scheduleRep)
+	scheduleSrv := schedule.NewScheduleService(scheduleRep, availabilityRep, auditSrv)
 
 	//create registration
 	registrationRep := registration.NewRegistrationRepositoryPostgres(pGpool)
-	registrationSrv := registration.NewRegistrationService(registrationRep)
+	registrationSrv := registration.NewRegistrationService(registrationRep, penaltyRep, cancelPolicy, auditSrv, notificationSrv)
+
+	//facility closures cancel or report what is booked in them
+	closureSrv := closure.NewClosureService(availabilitySrv, bookingSrv, sessionSrv, auditSrv, notificationSrv)
+
+	//campus holidays cancel the sessions already generated on them
+	holidayRep := holiday.NewHolidayRepositoryPostgres(pGpool)
+	holidaySrv := holiday.NewHolidayService(holidayRep, sessionSrv, auditSrv)
 
 	//create penalty
-	penaltyRep := penalty.NewPenaltyRepositoryPostgres(pGpool)
-	penaltySrv := penalty.NewPenaltyService(penaltyRep)
+	penaltySrv := penalty.NewPenaltyService(penaltyRep, auditSrv, notificationSrv)
+
+	//create attendance
+	attendanceRep := attendance.NewAttendanceRepositoryPostgres(pGpool)
+	attendanceSrv := attendance.NewAttendanceService(attendanceRep, penaltyRep, attendance.Config{
+		OpensBefore:  cfg.CheckInOpensBefore,
+		LateAfter:    cfg.CheckInLateAfter,
+		CloseAfter:   cfg.AttendanceCloseAfter,
+		NoShowPoints: cfg.NoShowPenaltyPoints,
+		LatePoints:   cfg.LatePenaltyPoints,
+		TokenKey:     []byte(cfg.JWTKey),
+		TokenTTL:     cfg.CheckInTokenTTL,
+	}, auditSrv, notificationSrv)
+
+	//create credit history and recovery
+	creditRep := credit.NewCreditRepositoryPostgres(pGpool)
+	creditSrv := credit.NewCreditService(creditRep, credit.RecoveryConfig{
+		PenaltyExpiry: time.Duration(cfg.PenaltyExpiryDays) * 24 * time.Hour,
+		SessionReward: cfg.CreditSessionReward,
+		BookingReward: cfg.CreditBookingReward,
+		MaxScore:      cfg.CreditMaxScore,
+		Lookback:      time.Duration(cfg.CreditRewardLookbackDays) * 24 * time.Hour,
+	}, auditSrv)
+
+	//background jobs
+	jobs := scheduler.New(logger)
+	jobs.Register(scheduler.Job{
+		Name:     "session-generation",
+		Interval: cfg.SessionGenerationInterval,
+		Run: func(ctx context.Context) error {
+			report, err := sessionSrv.GenerateSessions(ctx)
+			if err != nil {
+				return err
+			}
+			logger.Info("session generation finished",
+				zap.Bool("lock_acquired", report.LockAcquired),
+				zap.Int("schedules_scanned", report.SchedulesScanned),
+				zap.Int("sessions_created", len(report.Created)),
+				zap.Int("sessions_skipped", len(report.Skipped)),
+				zap.Int("horizon_weeks", report.HorizonWeeks),
+			)
+			return nil
+		},
+	})
+	jobs.Register(scheduler.Job{
+		Name:     "credit-recovery",
+		Interval: cfg.CreditRecoveryInterval,
+		Run: func(ctx context.Context) error {
+			report, err := creditSrv.Recover(ctx)
+			if err != nil {
+				return err
+			}
+			logger.Info("credit recovery finished",
+				zap.Bool("lock_acquired", report.LockAcquired),
+				zap.Int("penalties_expired", report.PenaltiesExpired),
+				zap.Int("sessions_rewarded", report.SessionsRewarded),
+				zap.Int("bookings_rewarded", report.BookingsRewarded),
+				zap.Int("points_restored", report.PointsRestored),
+			)
+			return nil
+		},
+	})
+	jobs.Register(scheduler.Job{
+		Name:     "attendance-closing",
+		Interval: cfg.AttendanceCloseInterval,
+		Run: func(ctx context.Context) error {
+			report, err := attendanceSrv.CloseFinished(ctx)
+			if err != nil {
+				return err
+			}
+			logger.Info("attendance closing finished",
+				zap.Bool("lock_acquired", report.LockAcquired),
+				zap.Int("closed", report.Closed),
+				zap.Int("no_shows", report.NoShows),
+				zap.Int("penalties_issued", report.PenaltiesIssued),
+			)
+			return nil
+		},
+	})
+	jobs.Register(scheduler.Job{
+		Name:     "notification-delivery",
+		Interval: cfg.NotifyDeliveryInterval,
+		Run: func(ctx context.Context) error {
+			report, err := notificationSrv.DeliverPending(ctx)
+			if err != nil {
+				return err
+			}
+			if report.Sent+report.Retrying+report.Failed > 0 {
+				logger.Info("notification delivery finished",
+					zap.Int("sent", report.Sent),
+					zap.Int("retrying", report.Retrying),
+					zap.Int("failed", report.Failed),
+				)
+			}
+			return nil
+		},
+	})
+	jobs.Start(ctx)
 
-	srv := http.NewServer(":8080", userSrvs, authSrv, facilSrv, bookingSrv, reviewSrv, trainerSrv, sessionSrv, scheduleSrv, registrationSrv, penaltySrv)
+	srv := http.NewServer(":8080", userSrvs, authSrv, facilSrv, bookingSrv, reviewSrv, trainerSrv, sessionSrv, scheduleSrv, registrationSrv, penaltySrv, policySrv, creditSrv, attendanceSrv, auditSrv, notificationSrv, availabilitySrv, closureSrv, holidaySrv)
 
 	srv.Start()
 
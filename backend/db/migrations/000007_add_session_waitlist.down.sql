@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_session_register_waitlist;
+ALTER TABLE training_session_register
+    DROP COLUMN waitlisted_at,
+    DROP COLUMN is_waitlisted;
@@ -0,0 +1,9 @@
+-- registrations made while the session is full are kept in the same table, but flagged as waitlisted.
+-- the queue order is the waitlisted_at timestamp
+ALTER TABLE training_session_register
+    ADD COLUMN is_waitlisted BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN waitlisted_at TIMESTAMP;
+
+CREATE INDEX idx_session_register_waitlist
+    ON training_session_register (session_id, waitlisted_at)
+    WHERE is_waitlisted = TRUE AND is_canceled = FALSE;
@@ -0,0 +1,21 @@
+-- booking rules, scoped globally (facility_id and role NULL), per facility, per role or per facility+role.
+-- a NULL rule column means "inherit from the less specific policy"
+CREATE TABLE booking_policies (
+    policy_id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name                   TEXT NOT NULL,
+    facility_id            UUID REFERENCES facilities(facility_id) ON DELETE CASCADE,
+    role                   role,
+    max_upcoming_bookings  INT CHECK (max_upcoming_bookings >= 0),
+    min_credit_score       INT,
+    max_bookings_per_day   INT CHECK (max_bookings_per_day >= 0),  -- per user, per facility, per day
+    min_slot_minutes       INT CHECK (min_slot_minutes > 0),
+    max_slot_minutes       INT CHECK (max_slot_minutes > 0),
+    is_active              BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at             TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at             TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE NULLS NOT DISTINCT (facility_id, role)
+);
+
+-- the limits that used to be hardcoded in the booking repository
+INSERT INTO booking_policies (name, max_upcoming_bookings, min_credit_score, max_bookings_per_day, min_slot_minutes, max_slot_minutes)
+VALUES ('Default', 3, 50, 1, 30, 120);
@@ -0,0 +1,2 @@
+-- bumped whenever the role changes, tokens carry the version they were issued with
+ALTER TABLE users ADD COLUMN token_version INT NOT NULL DEFAULT 0;
@@ -0,0 +1,16 @@
+-- one row per login, the refresh token is rotated in place on every /auth/refresh.
+-- only sha256 hashes of the tokens are stored, the previous hash is kept to detect a replayed (stolen) refresh token
+CREATE TABLE auth_sessions (
+    session_id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id             UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    refresh_token_hash  TEXT NOT NULL UNIQUE,
+    previous_token_hash TEXT,
+    user_agent          TEXT,
+    expires_at          TIMESTAMPTZ NOT NULL,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    revoked_at          TIMESTAMPTZ
+);
+
+CREATE INDEX idx_auth_sessions_user ON auth_sessions (user_id) WHERE revoked_at IS NULL;
+CREATE INDEX idx_auth_sessions_previous_hash ON auth_sessions (previous_token_hash);
@@ -0,0 +1,16 @@
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMPTZ;
+-- accounts created before verification existed are trusted
+UPDATE users SET email_verified_at = COALESCE(created_at, NOW());
+
+-- single-use tokens sent by email, only the sha256 hash is stored
+CREATE TABLE user_tokens (
+    token_id    UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id     UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    purpose     TEXT NOT NULL CHECK (purpose IN ('password_reset', 'email_verification')),
+    token_hash  TEXT NOT NULL UNIQUE,
+    expires_at  TIMESTAMPTZ NOT NULL,
+    used_at     TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_user_tokens_user_purpose ON user_tokens (user_id, purpose) WHERE used_at IS NULL;
@@ -0,0 +1,8 @@
+-- rows that point at the placeholder cannot be restored, they go away with it
+DELETE FROM bookings WHERE user_id = '00000000-0000-0000-0000-000000000000';
+DELETE FROM user_penalties WHERE user_id = '00000000-0000-0000-0000-000000000000' OR given_by_id = '00000000-0000-0000-0000-000000000000';
+DELETE FROM facility_review WHERE user_id = '00000000-0000-0000-0000-000000000000';
+DELETE FROM users WHERE user_id = '00000000-0000-0000-0000-000000000000';
+
+ALTER TABLE user_penalties DROP CONSTRAINT IF EXISTS user_penalties_check;
+ALTER TABLE user_penalties ADD CONSTRAINT user_penalties_check CHECK (user_id <> given_by_id);
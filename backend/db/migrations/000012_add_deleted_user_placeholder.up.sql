@@ -0,0 +1,10 @@
+-- when a user is erased, their bookings, penalties and reviews are moved to this placeholder
+-- instead of being cascaded away, so facility usage and rating statistics stay intact
+INSERT INTO users (user_id, email, first_name, last_name, password, role, credit_score, is_active)
+VALUES ('00000000-0000-0000-0000-000000000000', 'deleted-user@invalid', 'Deleted', 'User', '!', 'student', 0, FALSE)
+ON CONFLICT (user_id) DO NOTHING;
+
+-- both sides of a penalty can end up being the placeholder
+ALTER TABLE user_penalties DROP CONSTRAINT IF EXISTS user_penalties_check;
+ALTER TABLE user_penalties ADD CONSTRAINT user_penalties_check
+    CHECK (user_id <> given_by_id OR user_id = '00000000-0000-0000-0000-000000000000');
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_user_penalties_unrestored;
+ALTER TABLE user_penalties DROP COLUMN IF EXISTS restored_at;
+
+DROP TABLE IF EXISTS credit_history;
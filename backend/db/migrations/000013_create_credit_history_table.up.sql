@@ -0,0 +1,26 @@
+-- every change of users.credit_score gets a row here, balance is the score right after the change.
+-- penalty/session/booking ids are plain references without FKs, the ledger outlives the rows it talks about
+CREATE TABLE credit_history (
+    entry_id    UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id     UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    delta       INT NOT NULL,
+    balance     INT NOT NULL,
+    reason      TEXT NOT NULL,
+    penalty_id  UUID,
+    session_id  UUID,
+    booking_id  UUID,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_credit_history_user ON credit_history (user_id, created_at DESC);
+
+-- a session or a booking is rewarded at most once, even if the recovery job runs on several replicas
+CREATE UNIQUE INDEX uq_credit_history_session_reward
+    ON credit_history (user_id, session_id) WHERE reason = 'session_attended';
+CREATE UNIQUE INDEX uq_credit_history_booking_reward
+    ON credit_history (booking_id) WHERE reason = 'booking_completed';
+
+-- set when the points of the penalty were given back because it expired
+ALTER TABLE user_penalties ADD COLUMN restored_at TIMESTAMP;
+
+CREATE INDEX idx_user_penalties_unrestored ON user_penalties (created_at) WHERE restored_at IS NULL;
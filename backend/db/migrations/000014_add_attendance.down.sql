@@ -0,0 +1,13 @@
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS attendance_status,
+    DROP COLUMN IF EXISTS checked_in_at,
+    DROP COLUMN IF EXISTS checked_out_at,
+    DROP COLUMN IF EXISTS checked_in_by;
+
+ALTER TABLE training_session_register
+    DROP COLUMN IF EXISTS attendance_status,
+    DROP COLUMN IF EXISTS checked_in_at,
+    DROP COLUMN IF EXISTS checked_out_at,
+    DROP COLUMN IF EXISTS checked_in_by;
+
+DROP TYPE IF EXISTS attendance_status;
@@ -0,0 +1,14 @@
+CREATE TYPE attendance_status AS ENUM ('pending', 'attended', 'late', 'no_show');
+
+-- pending until someone checks the user in or marks the attendance by hand
+ALTER TABLE training_session_register
+    ADD COLUMN attendance_status attendance_status NOT NULL DEFAULT 'pending',
+    ADD COLUMN checked_in_at     TIMESTAMPTZ,
+    ADD COLUMN checked_out_at    TIMESTAMPTZ,
+    ADD COLUMN checked_in_by     UUID REFERENCES users(user_id) ON DELETE SET NULL;
+
+ALTER TABLE bookings
+    ADD COLUMN attendance_status attendance_status NOT NULL DEFAULT 'pending',
+    ADD COLUMN checked_in_at     TIMESTAMPTZ,
+    ADD COLUMN checked_out_at    TIMESTAMPTZ,
+    ADD COLUMN checked_in_by     UUID REFERENCES users(user_id) ON DELETE SET NULL;
@@ -0,0 +1,9 @@
+ALTER TABLE bookings DROP COLUMN IF EXISTS attendance_closed_at;
+ALTER TABLE training_session_register DROP COLUMN IF EXISTS attendance_closed_at;
+
+DROP INDEX IF EXISTS uq_user_penalties_auto_booking;
+DROP INDEX IF EXISTS uq_user_penalties_auto_session;
+ALTER TABLE user_penalties DROP COLUMN IF EXISTS is_automatic;
+
+DELETE FROM user_penalties WHERE given_by_id = '00000000-0000-0000-0000-000000000001';
+DELETE FROM users WHERE user_id = '00000000-0000-0000-0000-000000000001';
@@ -0,0 +1,23 @@
+-- automatic penalties for bookings have no trainer to give them, they are given in the name of this account
+INSERT INTO users (user_id, email, first_name, last_name, password, role, credit_score, is_active)
+VALUES ('00000000-0000-0000-0000-000000000001', 'system@invalid', 'System', 'Account', '!', 'staff', 0, FALSE)
+ON CONFLICT (user_id) DO NOTHING;
+
+ALTER TABLE user_penalties ADD COLUMN is_automatic BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- the job never penalizes the same registration/booking and penalty type twice
+CREATE UNIQUE INDEX uq_user_penalties_auto_session
+    ON user_penalties (user_id, session_id, penalty_type) WHERE is_automatic AND session_id IS NOT NULL;
+CREATE UNIQUE INDEX uq_user_penalties_auto_booking
+    ON user_penalties (booking_id, penalty_type) WHERE is_automatic AND booking_id IS NOT NULL;
+
+-- set once the job looked at the finished slot, a penalty removed afterwards is not given again
+ALTER TABLE training_session_register ADD COLUMN attendance_closed_at TIMESTAMPTZ;
+ALTER TABLE bookings ADD COLUMN attendance_closed_at TIMESTAMPTZ;
+
+-- nobody could check in before attendance existed, so slots that already ended are not penalized
+UPDATE training_session_register r SET attendance_closed_at = NOW()
+FROM trainer_sessions ts
+WHERE ts.session_id = r.session_id AND ts.date + ts.end_time < NOW();
+
+UPDATE bookings SET attendance_closed_at = NOW() WHERE date + end_time < NOW();
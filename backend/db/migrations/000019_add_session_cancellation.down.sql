@@ -0,0 +1,7 @@
+ALTER TABLE training_session_register
+    DROP COLUMN IF EXISTS canceled_at,
+    DROP COLUMN IF EXISTS cancel_reason;
+
+ALTER TABLE trainer_sessions
+    DROP COLUMN IF EXISTS canceled_at,
+    DROP COLUMN IF EXISTS cancel_reason;
@@ -0,0 +1,14 @@
+-- why and when a session was canceled, copied to every registration it canceled
+ALTER TABLE trainer_sessions
+    ADD COLUMN cancel_reason TEXT,
+    ADD COLUMN canceled_at   TIMESTAMPTZ;
+
+ALTER TABLE training_session_register
+    ADD COLUMN cancel_reason TEXT,
+    ADD COLUMN canceled_at   TIMESTAMPTZ;
+
+-- sessions canceled before the cascade existed left their registrations active
+UPDATE training_session_register r
+SET is_canceled = TRUE, cancel_reason = 'session canceled', canceled_at = NOW(), updated_at = NOW()
+FROM trainer_sessions ts
+WHERE ts.session_id = r.session_id AND ts.is_canceled = TRUE AND r.is_canceled = FALSE;
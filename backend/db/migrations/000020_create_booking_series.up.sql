@@ -0,0 +1,23 @@
+-- a recurring booking, the occurrences are ordinary rows in bookings pointing back here
+CREATE TABLE booking_series (
+    series_id    UUID PRIMARY KEY,
+    user_id      UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    facility_id  UUID NOT NULL REFERENCES facilities(facility_id) ON DELETE CASCADE,
+    rrule        TEXT NOT NULL,
+    start_date   DATE NOT NULL,
+    until        DATE NOT NULL,
+    start_time   TIME NOT NULL,
+    end_time     TIME NOT NULL,
+    note         TEXT,
+    is_canceled  BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (until >= start_date),
+    CHECK (end_time > start_time)
+);
+
+CREATE INDEX idx_booking_series_user ON booking_series (user_id);
+
+ALTER TABLE bookings ADD COLUMN series_id UUID REFERENCES booking_series(series_id) ON DELETE SET NULL;
+
+CREATE INDEX idx_bookings_series ON bookings (series_id, date) WHERE series_id IS NOT NULL;
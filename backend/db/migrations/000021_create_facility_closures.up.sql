@@ -0,0 +1,20 @@
+-- maintenance and blackout windows. without times the whole day is closed, without rrule every day from
+-- start_date to end_date is, with rrule only its occurrences are (end_date NULL keeps a recurring closure open ended)
+CREATE TABLE facility_closures (
+    closure_id   UUID PRIMARY KEY,
+    facility_id  UUID NOT NULL REFERENCES facilities(facility_id) ON DELETE CASCADE,
+    reason       TEXT NOT NULL,
+    start_date   DATE NOT NULL,
+    end_date     DATE,
+    start_time   TIME,
+    end_time     TIME,
+    rrule        TEXT,
+    created_by   UUID REFERENCES users(user_id) ON DELETE SET NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (end_date IS NULL OR end_date >= start_date),
+    CHECK (end_date IS NOT NULL OR rrule IS NOT NULL),
+    CHECK ((start_time IS NULL) = (end_time IS NULL)),
+    CHECK (end_time IS NULL OR end_time > start_time)
+);
+
+CREATE INDEX idx_facility_closures_facility ON facility_closures (facility_id, start_date);
@@ -0,0 +1,15 @@
+-- campus holidays and breaks, no sessions and no bookings on these days. uid is the UID of the imported
+-- iCalendar event, so importing the same file again updates the holidays instead of duplicating them
+CREATE TABLE holidays (
+    holiday_id  UUID PRIMARY KEY,
+    name        TEXT NOT NULL,
+    start_date  DATE NOT NULL,
+    end_date    DATE NOT NULL,
+    uid         TEXT UNIQUE,
+    created_by  UUID REFERENCES users(user_id) ON DELETE SET NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (end_date >= start_date)
+);
+
+CREATE INDEX idx_holidays_dates ON holidays (start_date, end_date);
@@ -0,0 +1,14 @@
+ALTER TABLE facilities ADD COLUMN open_time TIME, ADD COLUMN close_time TIME;
+
+-- the regular week collapses to its earliest opening and latest closing
+UPDATE facilities f
+SET open_time = h.open_time, close_time = h.close_time
+FROM (SELECT facility_id, MIN(open_time) AS open_time, MAX(close_time) AS close_time
+      FROM facility_opening_hours WHERE season_id IS NULL GROUP BY facility_id) h
+WHERE h.facility_id = f.facility_id;
+
+UPDATE facilities SET open_time = '08:00', close_time = '22:00' WHERE open_time IS NULL;
+ALTER TABLE facilities ALTER COLUMN open_time SET NOT NULL, ALTER COLUMN close_time SET NOT NULL;
+
+DROP TABLE IF EXISTS facility_opening_hours;
+DROP TABLE IF EXISTS facility_seasons;
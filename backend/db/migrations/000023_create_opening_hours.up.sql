@@ -0,0 +1,32 @@
+-- seasonal overrides of the regular week, on their dates only the season's hours count
+CREATE TABLE facility_seasons (
+    season_id    UUID PRIMARY KEY,
+    facility_id  UUID NOT NULL REFERENCES facilities(facility_id) ON DELETE CASCADE,
+    name         TEXT NOT NULL,
+    start_date   DATE NOT NULL,
+    end_date     DATE NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (end_date >= start_date)
+);
+
+CREATE INDEX idx_facility_seasons_facility ON facility_seasons (facility_id, start_date);
+
+-- opening intervals per weekday (0 is sunday), several per day allowed. season_id NULL is the regular week
+CREATE TABLE facility_opening_hours (
+    facility_id  UUID NOT NULL REFERENCES facilities(facility_id) ON DELETE CASCADE,
+    season_id    UUID REFERENCES facility_seasons(season_id) ON DELETE CASCADE,
+    weekday      SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+    open_time    TIME NOT NULL,
+    close_time   TIME NOT NULL,
+    CHECK (close_time > open_time)
+);
+
+CREATE INDEX idx_facility_opening_hours_facility ON facility_opening_hours (facility_id, season_id);
+
+-- the old single pair becomes the same hours on every weekday
+INSERT INTO facility_opening_hours (facility_id, weekday, open_time, close_time)
+SELECT f.facility_id, d, f.open_time, f.close_time
+FROM facilities f, generate_series(0, 6) d
+WHERE f.close_time > f.open_time;
+
+ALTER TABLE facilities DROP COLUMN open_time, DROP COLUMN close_time;
@@ -0,0 +1,7 @@
+-- fails while facilities use a type the enum does not know, change or delete them first
+CREATE TYPE sport_type AS ENUM ('football', 'basketball', 'tennis');
+
+ALTER TABLE facilities DROP CONSTRAINT IF EXISTS facilities_type_fkey;
+ALTER TABLE facilities ALTER COLUMN type TYPE sport_type USING type::sport_type;
+
+DROP TABLE IF EXISTS facility_types;
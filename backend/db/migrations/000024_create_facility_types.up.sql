@@ -0,0 +1,28 @@
+-- facility categories managed by admins instead of the sport_type enum. the booking rules are defaults for every
+-- facility of the type, booking policies of the facility itself still win over them
+CREATE TABLE facility_types (
+    type_id                UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name                   TEXT NOT NULL UNIQUE,
+    icon                   TEXT NOT NULL DEFAULT '',
+    description            TEXT NOT NULL DEFAULT '',
+    max_upcoming_bookings  INTEGER CHECK (max_upcoming_bookings >= 0),
+    min_credit_score       INTEGER,
+    max_bookings_per_day   INTEGER CHECK (max_bookings_per_day >= 0),
+    min_slot_minutes       INTEGER CHECK (min_slot_minutes >= 1),
+    max_slot_minutes       INTEGER CHECK (max_slot_minutes >= 1),
+    created_at             TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at             TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (min_slot_minutes IS NULL OR max_slot_minutes IS NULL OR min_slot_minutes <= max_slot_minutes)
+);
+
+INSERT INTO facility_types (name, icon, description) VALUES
+    ('football', 'football', 'Football pitches'),
+    ('basketball', 'basketball', 'Basketball courts'),
+    ('tennis', 'tennis', 'Tennis courts');
+
+-- facilities keep the type name, renaming a type renames it on its facilities too
+ALTER TABLE facilities ALTER COLUMN type TYPE TEXT USING type::text;
+ALTER TABLE facilities ADD CONSTRAINT facilities_type_fkey
+    FOREIGN KEY (type) REFERENCES facility_types(name) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+DROP TYPE sport_type;
@@ -0,0 +1,25 @@
+-- the audit log stays append-only, except that the ip and user_agent of a row can be cleared:
+-- they are personal data and are scrubbed when the user is erased
+DROP TRIGGER trg_audit_log_append_only ON audit_log;
+
+CREATE TRIGGER trg_audit_log_append_only
+    BEFORE DELETE OR TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
+
+CREATE FUNCTION audit_log_scrub_only() RETURNS trigger AS $$
+BEGIN
+    IF NEW.ip <> '' OR NEW.user_agent <> ''
+        OR (NEW.entry_id, NEW.actor_id, NEW.actor_role, NEW.action, NEW.entity_type, NEW.entity_id,
+            NEW.before, NEW.after, NEW.request_id, NEW.created_at)
+        IS DISTINCT FROM
+           (OLD.entry_id, OLD.actor_id, OLD.actor_role, OLD.action, OLD.entity_type, OLD.entity_id,
+            OLD.before, OLD.after, OLD.request_id, OLD.created_at) THEN
+        RAISE EXCEPTION 'audit_log is append-only, only ip and user_agent can be cleared';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_audit_log_scrub_only
+    BEFORE UPDATE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_scrub_only();
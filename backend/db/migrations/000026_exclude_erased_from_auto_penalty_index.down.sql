@@ -0,0 +1,4 @@
+-- fails once two erased users had the same automatic penalty on a session
+DROP INDEX IF EXISTS uq_user_penalties_auto_session;
+CREATE UNIQUE INDEX uq_user_penalties_auto_session
+    ON user_penalties (user_id, session_id, penalty_type) WHERE is_automatic AND session_id IS NOT NULL;
@@ -0,0 +1,6 @@
+-- erasure moves the penalties of every erased user to the placeholder account, so two erased users with the same
+-- automatic penalty on one session would collide there. the placeholder is left out of the index
+DROP INDEX IF EXISTS uq_user_penalties_auto_session;
+CREATE UNIQUE INDEX uq_user_penalties_auto_session
+    ON user_penalties (user_id, session_id, penalty_type)
+    WHERE is_automatic AND session_id IS NOT NULL AND user_id <> '00000000-0000-0000-0000-000000000000';
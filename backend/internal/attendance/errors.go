@@ -0,0 +1,16 @@
+package attendance
+
+import "errors"
+
+var (
+	ErrTargetNotFound    = errors.New("registration or booking not found")
+	ErrNotCheckable      = errors.New("registration or booking is canceled or waitlisted")
+	ErrCheckInClosed     = errors.New("check-in is not open for this slot")
+	ErrAlreadyCheckedIn  = errors.New("already checked in")
+	ErrNotCheckedIn      = errors.New("not checked in yet")
+	ErrAlreadyCheckedOut = errors.New("already checked out")
+	ErrInvalidStatus     = errors.New("invalid attendance status")
+	ErrNotPrivileged     = errors.New("only the trainer, staff or admins can set the attendance")
+	ErrInvalidToken      = errors.New("invalid check-in token")
+	ErrTokenExpired      = errors.New("check-in token expired")
+)
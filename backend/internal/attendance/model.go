@@ -0,0 +1,116 @@
+package attendance
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusAttended Status = "attended"
+	StatusLate     Status = "late"
+	StatusNoShow   Status = "no_show"
+)
+
+// Kind is what the user checks in to
+type Kind string
+
+const (
+	KindRegistration Kind = "registration"
+	KindBooking      Kind = "booking"
+)
+
+// Record is the attendance state of one registration or booking
+type Record struct {
+	Kind         Kind
+	ID           uuid.UUID // register_id or booking_id
+	UserID       uuid.UUID
+	Status       Status
+	CheckedInAt  *time.Time
+	CheckedOutAt *time.Time
+	CheckedInBy  *uuid.UUID
+}
+
+// Target is a registration or booking together with the time slot it is for
+type Target struct {
+	Record
+	TrainerID  uuid.UUID // only for registrations
+	Start      time.Time
+	End        time.Time
+	IsCanceled bool // the registration/booking or its session was canceled
+	Waitlisted bool
+}
+
+// Actor is whoever performs the check-in, privileged actors (trainer of the session, staff, admin)
+// are not bound to the self check-in window
+type Actor struct {
+	UserID     uuid.UUID
+	Privileged bool
+}
+
+// Config is the self check-in window around the start of the slot, the automatic penalties after it
+// and the signing of the check-in tokens
+type Config struct {
+	OpensBefore  time.Duration // self check-in opens this long before the start
+	LateAfter    time.Duration // checking in later than start+LateAfter marks the user as late
+	CloseAfter   time.Duration // the attendance of a slot is closed (and penalized) this long after its end
+	NoShowPoints int           // points of the automatic absence penalty, 0 disables it
+	LatePoints   int           // points of the automatic late penalty, 0 disables it
+	TokenKey     []byte        // signs the check-in tokens
+	TokenTTL     time.Duration // how long a check-in token is valid after it was issued
+}
+
+// Token is a signed check-in token for one registration or booking, shown to the front desk as a QR code
+type Token struct {
+	Kind      Kind
+	ID        uuid.UUID
+	Value     string
+	ExpiresAt time.Time
+}
+
+// Unclosed is a finished registration or booking the attendance job did not look at yet
+type Unclosed struct {
+	Record
+	GivenByID        uuid.UUID // trainer of the session, the system account for bookings
+	SessionID        uuid.UUID
+	BookingID        uuid.UUID
+	Start            time.Time
+	AlreadyPenalized bool // someone already gave a penalty for this slot by hand
+}
+
+// ClosingReport describes one run of the attendance job
+type ClosingReport struct {
+	StartedAt       time.Time
+	FinishedAt      time.Time
+	LockAcquired    bool // false when another replica was running the job at the same time
+	Closed          int
+	NoShows         int
+	PenaltiesIssued int
+}
+
+type RosterEntry struct {
+	RegistrationID uuid.UUID
+	UserID         uuid.UUID
+	FirstName      string
+	LastName       string
+	Email          string
+	Status         Status
+	CheckedInAt    *time.Time
+	CheckedOutAt   *time.Time
+}
+
+// Roster is the attendance sheet of one session
+type Roster struct {
+	SessionID uuid.UUID
+	TrainerID uuid.UUID
+	Start     time.Time
+	End       time.Time
+	Entries   []RosterEntry
+	Attended  int
+	Late      int
+	NoShow    int
+	Pending   int
+}
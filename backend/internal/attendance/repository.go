@@ -0,0 +1,233 @@
+package attendance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"t/internal/session"
+	"t/internal/user"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AttendanceRepository interface {
+	BeginTx(ctx context.Context) (pgx.Tx, error)
+	// GetTarget loads and locks the registration or booking
+	GetTarget(ctx context.Context, tx pgx.Tx, kind Kind, id uuid.UUID) (Target, error)
+	SaveRecord(ctx context.Context, tx pgx.Tx, rec Record) error
+	GetRoster(ctx context.Context, sessionID uuid.UUID) (Roster, error)
+
+	// ListUnclosed locks the registrations or bookings that ended more than afterSecs ago and were not closed yet
+	ListUnclosed(ctx context.Context, tx pgx.Tx, kind Kind, afterSecs float64) ([]Unclosed, error)
+	// CloseAttendance stores the final status, closed rows are never looked at by the job again
+	CloseAttendance(ctx context.Context, tx pgx.Tx, kind Kind, id uuid.UUID, status Status) error
+}
+
+type AttendanceRepositoryPostgres struct {
+	pool *pgxpool.Pool
+}
+
+func NewAttendanceRepositoryPostgres(pool *pgxpool.Pool) *AttendanceRepositoryPostgres {
+	return &AttendanceRepositoryPostgres{pool: pool}
+}
+
+func (r *AttendanceRepositoryPostgres) BeginTx(ctx context.Context) (pgx.Tx, error) {
+	return r.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+}
+
+func (r *AttendanceRepositoryPostgres) GetTarget(ctx context.Context, tx pgx.Tx, kind Kind, id uuid.UUID) (Target, error) {
+	var query string
+	switch kind {
+	case KindRegistration:
+		query = `SELECT r.register_id, r.user_id, r.attendance_status, r.checked_in_at, r.checked_out_at, r.checked_in_by,
+				        ts.trainer_id, ts.date, ts.start_time, ts.end_time,
+				        r.is_canceled OR COALESCE(ts.is_canceled, FALSE), r.is_waitlisted
+				 FROM training_session_register r
+				 JOIN trainer_sessions ts ON ts.session_id = r.session_id
+				 WHERE r.register_id = $1
+				 FOR UPDATE OF r`
+	case KindBooking:
+		query = `SELECT booking_id, user_id, attendance_status, checked_in_at, checked_out_at, checked_in_by,
+				        '00000000-0000-0000-0000-000000000000'::uuid, date, start_time, end_time,
+				        is_canceled, FALSE
+				 FROM bookings
+				 WHERE booking_id = $1
+				 FOR UPDATE`
+	default:
+		return Target{}, fmt.Errorf("GetTarget: unknown kind %q", kind)
+	}
+
+	var t Target
+	var date, start, end time.Time
+	err := tx.QueryRow(ctx, query, id).Scan(
+		&t.ID, &t.UserID, &t.Status, &t.CheckedInAt, &t.CheckedOutAt, &t.CheckedInBy,
+		&t.TrainerID, &date, &start, &end,
+		&t.IsCanceled, &t.Waitlisted,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Target{}, ErrTargetNotFound
+		}
+		return Target{}, fmt.Errorf("GetTarget: Failed to SELECT: %w", err)
+	}
+	t.Kind = kind
+	t.Start = combine(date, start)
+	t.End = combine(date, end)
+	return t, nil
+}
+
+func (r *AttendanceRepositoryPostgres) SaveRecord(ctx context.Context, tx pgx.Tx, rec Record) error {
+	var query string
+	switch rec.Kind {
+	case KindRegistration:
+		query = `UPDATE training_session_register
+				 SET attendance_status = $2, checked_in_at = $3, checked_out_at = $4, checked_in_by = $5, updated_at = NOW()
+				 WHERE register_id = $1`
+	case KindBooking:
+		query = `UPDATE bookings
+				 SET attendance_status = $2, checked_in_at = $3, checked_out_at = $4, checked_in_by = $5, updated_at = NOW()
+				 WHERE booking_id = $1`
+	default:
+		return fmt.Errorf("SaveRecord: unknown kind %q", rec.Kind)
+	}
+
+	tag, err := tx.Exec(ctx, query, rec.ID, rec.Status, rec.CheckedInAt, rec.CheckedOutAt, rec.CheckedInBy)
+	if err != nil {
+		return fmt.Errorf("SaveRecord: Failed to UPDATE: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrTargetNotFound
+	}
+	return nil
+}
+
+// GetRoster lists the registered (not canceled, not waitlisted) users of the session with their attendance
+func (r *AttendanceRepositoryPostgres) GetRoster(ctx context.Context, sessionID uuid.UUID) (Roster, error) {
+	query := `SELECT session_id, trainer_id, date, start_time, end_time FROM trainer_sessions WHERE session_id = $1`
+
+	var roster Roster
+	var date, start, end time.Time
+	err := r.pool.QueryRow(ctx, query, sessionID).Scan(&roster.SessionID, &roster.TrainerID, &date, &start, &end)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Roster{}, session.ErrSessionNotFound
+		}
+		return Roster{}, fmt.Errorf("GetRoster: Failed to SELECT session: %w", err)
+	}
+	roster.Start = combine(date, start)
+	roster.End = combine(date, end)
+
+	query = `SELECT r.register_id, r.user_id, u.first_name, u.last_name, u.email,
+			        r.attendance_status, r.checked_in_at, r.checked_out_at
+			 FROM training_session_register r
+			 JOIN users u ON u.user_id = r.user_id
+			 WHERE r.session_id = $1 AND r.is_canceled = FALSE AND r.is_waitlisted = FALSE
+			 ORDER BY u.last_name, u.first_name`
+
+	rows, err := r.pool.Query(ctx, query, sessionID)
+	if err != nil {
+		return Roster{}, fmt.Errorf("GetRoster: Failed to SELECT: %w", err)
+	}
+	defer rows.Close()
+
+	roster.Entries = make([]RosterEntry, 0)
+	for rows.Next() {
+		var e RosterEntry
+		err := rows.Scan(&e.RegistrationID, &e.UserID, &e.FirstName, &e.LastName, &e.Email,
+			&e.Status, &e.CheckedInAt, &e.CheckedOutAt)
+		if err != nil {
+			return Roster{}, fmt.Errorf("GetRoster: Failed to SCAN: %w", err)
+		}
+
+		switch e.Status {
+		case StatusAttended:
+			roster.Attended++
+		case StatusLate:
+			roster.Late++
+		case StatusNoShow:
+			roster.NoShow++
+		default:
+			roster.Pending++
+		}
+		roster.Entries = append(roster.Entries, e)
+	}
+	return roster, rows.Err()
+}
+
+func (r *AttendanceRepositoryPostgres) ListUnclosed(ctx context.Context, tx pgx.Tx, kind Kind, afterSecs float64) ([]Unclosed, error) {
+	var query string
+	switch kind {
+	case KindRegistration:
+		query = `SELECT r.register_id, r.user_id, r.attendance_status,
+				        CASE WHEN ts.trainer_id IS NULL OR ts.trainer_id = r.user_id THEN $2 ELSE ts.trainer_id END,
+				        r.session_id, '00000000-0000-0000-0000-000000000000'::uuid, ts.date, ts.start_time,
+				        EXISTS (SELECT 1 FROM user_penalties p WHERE p.session_id = r.session_id AND p.user_id = r.user_id)
+				 FROM training_session_register r
+				 JOIN trainer_sessions ts ON ts.session_id = r.session_id
+				 WHERE r.attendance_closed_at IS NULL
+				   AND r.is_canceled = FALSE
+				   AND r.is_waitlisted = FALSE
+				   AND ts.is_canceled = FALSE
+				   AND r.user_id <> $3
+				   AND ts.date + ts.end_time < NOW() - make_interval(secs => $1)
+				 FOR UPDATE OF r SKIP LOCKED`
+	case KindBooking:
+		query = `SELECT b.booking_id, b.user_id, b.attendance_status,
+				        $2::uuid,
+				        '00000000-0000-0000-0000-000000000000'::uuid, b.booking_id, b.date, b.start_time,
+				        EXISTS (SELECT 1 FROM user_penalties p WHERE p.booking_id = b.booking_id)
+				 FROM bookings b
+				 WHERE b.attendance_closed_at IS NULL
+				   AND b.is_canceled = FALSE
+				   AND b.user_id <> $3
+				   AND b.date + b.end_time < NOW() - make_interval(secs => $1)
+				 FOR UPDATE SKIP LOCKED`
+	default:
+		return nil, fmt.Errorf("ListUnclosed: unknown kind %q", kind)
+	}
+
+	rows, err := tx.Query(ctx, query, afterSecs, user.SystemUserID, user.DeletedUserID)
+	if err != nil {
+		return nil, fmt.Errorf("ListUnclosed: Failed to SELECT: %w", err)
+	}
+	defer rows.Close()
+
+	resp := make([]Unclosed, 0)
+	for rows.Next() {
+		var u Unclosed
+		var date, start time.Time
+		err := rows.Scan(&u.ID, &u.UserID, &u.Status, &u.GivenByID, &u.SessionID, &u.BookingID, &date, &start, &u.AlreadyPenalized)
+		if err != nil {
+			return nil, fmt.Errorf("ListUnclosed: Failed to SCAN: %w", err)
+		}
+		u.Kind = kind
+		u.Start = combine(date, start)
+		resp = append(resp, u)
+	}
+	return resp, rows.Err()
+}
+
+func (r *AttendanceRepositoryPostgres) CloseAttendance(ctx context.Context, tx pgx.Tx, kind Kind, id uuid.UUID, status Status) error {
+	var query string
+	switch kind {
+	case KindRegistration:
+		query = `UPDATE training_session_register SET attendance_status = $2, attendance_closed_at = NOW(), updated_at = NOW() WHERE register_id = $1`
+	case KindBooking:
+		query = `UPDATE bookings SET attendance_status = $2, attendance_closed_at = NOW(), updated_at = NOW() WHERE booking_id = $1`
+	default:
+		return fmt.Errorf("CloseAttendance: unknown kind %q", kind)
+	}
+
+	if _, err := tx.Exec(ctx, query, id, status); err != nil {
+		return fmt.Errorf("CloseAttendance: Failed to UPDATE: %w", err)
+	}
+	return nil
+}
+
+// DATE and TIME columns come back as separate values, the slot is in the server's local time
+func combine(date time.Time, clock time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), 0, 0, time.Local)
+}
@@ -0,0 +1,284 @@
+package attendance
+
+import (
+	"context"
+	"fmt"
+	"t/internal/audit"
+	"t/internal/notification"
+	"t/internal/penalty"
+	pg "t/pkg/postgres"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// key of the advisory lock, so only one replica closes the attendance at a time
+const closingLockKey int64 = 0xA77E4D
+
+type AttendanceService struct {
+	attendanceRepo AttendanceRepository
+	penaltyRepo    penalty.PenaltyRepository
+	cfg            Config
+	audit          audit.Recorder
+	notifier       notification.Notifier
+}
+
+func NewAttendanceService(r AttendanceRepository, penaltyRepo penalty.PenaltyRepository, cfg Config, auditRec audit.Recorder, notifier notification.Notifier) *AttendanceService {
+	return &AttendanceService{
+		attendanceRepo: r,
+		penaltyRepo:    penaltyRepo,
+		cfg:            cfg,
+		audit:          auditRec,
+		notifier:       notifier,
+	}
+}
+
+// CheckIn marks the user as present, late if the check-in happens after start+LateAfter.
+// users checking themselves in have to do it between start-OpensBefore and the end of the slot
+func (s *AttendanceService) CheckIn(ctx context.Context, kind Kind, id uuid.UUID, actor Actor) (Record, error) {
+	return s.update(ctx, "attendance.checkin", kind, id, func(t *Target, now time.Time) error {
+		return s.checkIn(t, now, actor.UserID, !actor.Privileged)
+	})
+}
+
+// IssueToken signs a check-in token for the registration or booking, valid for TokenTTL but never past the end of the slot
+func (s *AttendanceService) IssueToken(ctx context.Context, kind Kind, id uuid.UUID) (Token, error) {
+	tx, err := s.attendanceRepo.BeginTx(ctx)
+	if err != nil {
+		return Token{}, fmt.Errorf("IssueToken: Failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	t, err := s.attendanceRepo.GetTarget(ctx, tx, kind, id)
+	if err != nil {
+		return Token{}, err
+	}
+	if t.IsCanceled || t.Waitlisted {
+		return Token{}, ErrNotCheckable
+	}
+
+	now := time.Now()
+	if now.After(t.End) {
+		return Token{}, ErrCheckInClosed
+	}
+	exp := now.Add(s.cfg.TokenTTL)
+	if exp.After(t.End) {
+		exp = t.End
+	}
+
+	return Token{
+		Kind:      kind,
+		ID:        id,
+		Value:     signToken(s.cfg.TokenKey, kind, id, exp),
+		ExpiresAt: exp,
+	}, nil
+}
+
+// CheckInWithToken checks in whoever the scanned token belongs to. the check-in window is always enforced,
+// scanning a QR code is the regular way in, not an override. the scanner is recorded as checked_in_by and has to be
+// staff/admin (scanner.Privileged) or the trainer of the session
+func (s *AttendanceService) CheckInWithToken(ctx context.Context, token string, scanner Actor) (Record, error) {
+	kind, id, err := parseToken(s.cfg.TokenKey, token, time.Now())
+	if err != nil {
+		return Record{}, err
+	}
+
+	return s.update(ctx, "attendance.checkin", kind, id, func(t *Target, now time.Time) error {
+		if !scanner.Privileged && (kind != KindRegistration || t.TrainerID != scanner.UserID) {
+			return ErrNotPrivileged
+		}
+		return s.checkIn(t, now, scanner.UserID, true)
+	})
+}
+
+// checkIn marks the target as attended or late, checkedInBy is whoever did the check-in
+func (s *AttendanceService) checkIn(t *Target, now time.Time, checkedInBy uuid.UUID, enforceWindow bool) error {
+	if t.CheckedInAt != nil {
+		return ErrAlreadyCheckedIn
+	}
+	if enforceWindow && (now.Before(t.Start.Add(-s.cfg.OpensBefore)) || now.After(t.End)) {
+		return ErrCheckInClosed
+	}
+
+	t.Status = StatusAttended
+	if now.After(t.Start.Add(s.cfg.LateAfter)) {
+		t.Status = StatusLate
+	}
+	t.CheckedInAt = &now
+	t.CheckedInBy = &checkedInBy
+	return nil
+}
+
+// CheckOut records when the user left, only after a check-in
+func (s *AttendanceService) CheckOut(ctx context.Context, kind Kind, id uuid.UUID, actor Actor) (Record, error) {
+	return s.update(ctx, "attendance.checkout", kind, id, func(t *Target, now time.Time) error {
+		if t.CheckedInAt == nil {
+			return ErrNotCheckedIn
+		}
+		if t.CheckedOutAt != nil {
+			return ErrAlreadyCheckedOut
+		}
+		t.CheckedOutAt = &now
+		return nil
+	})
+}
+
+// SetStatus lets the trainer, staff or admins override the attendance by hand, any time
+func (s *AttendanceService) SetStatus(ctx context.Context, kind Kind, id uuid.UUID, status Status, actor Actor) (Record, error) {
+	if !actor.Privileged {
+		return Record{}, ErrNotPrivileged
+	}
+
+	return s.update(ctx, "attendance.set", kind, id, func(t *Target, now time.Time) error {
+		switch status {
+		case StatusAttended, StatusLate:
+			if t.CheckedInAt == nil {
+				t.CheckedInAt = &now
+			}
+			t.CheckedInBy = &actor.UserID
+		case StatusNoShow, StatusPending:
+			t.CheckedInAt = nil
+			t.CheckedOutAt = nil
+			t.CheckedInBy = nil
+		default:
+			return ErrInvalidStatus
+		}
+		t.Status = status
+		return nil
+	})
+}
+
+func (s *AttendanceService) GetRoster(ctx context.Context, sessionID uuid.UUID) (Roster, error) {
+	return s.attendanceRepo.GetRoster(ctx, sessionID)
+}
+
+// update loads and locks the target, lets change modify it and saves the record in one transaction.
+// the change is written to the audit log as action
+func (s *AttendanceService) update(ctx context.Context, action string, kind Kind, id uuid.UUID, change func(t *Target, now time.Time) error) (Record, error) {
+	tx, err := s.attendanceRepo.BeginTx(ctx)
+	if err != nil {
+		return Record{}, fmt.Errorf("update attendance: Failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	t, err := s.attendanceRepo.GetTarget(ctx, tx, kind, id)
+	if err != nil {
+		return Record{}, err
+	}
+	if t.IsCanceled || t.Waitlisted {
+		return Record{}, ErrNotCheckable
+	}
+	before := t.Record
+
+	if err := change(&t, time.Now()); err != nil {
+		return Record{}, err
+	}
+
+	if err := s.attendanceRepo.SaveRecord(ctx, tx, t.Record); err != nil {
+		return Record{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return Record{}, fmt.Errorf("update attendance: Failed to commit: %w", err)
+	}
+	s.audit.Record(ctx, action, string(kind), id, before, t.Record)
+	return t.Record, nil
+}
+
+// CloseFinished closes the attendance of every registration and booking that ended more than CloseAfter ago.
+// nobody checked in: the slot becomes a no-show and gets an absence penalty, checked in late: a late penalty.
+// the penalty and the closing are written in one transaction and closed slots are never visited again,
+// so a penalty removed through DeletePenalty stays removed
+func (s *AttendanceService) CloseFinished(ctx context.Context) (ClosingReport, error) {
+	report := ClosingReport{StartedAt: time.Now()}
+
+	tx, err := s.attendanceRepo.BeginTx(ctx)
+	if err != nil {
+		return report, fmt.Errorf("CloseFinished: Failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	locked, err := pg.TryAdvisoryXactLock(ctx, tx, closingLockKey)
+	if err != nil {
+		return report, err
+	}
+	if !locked {
+		report.FinishedAt = time.Now()
+		return report, nil
+	}
+	report.LockAcquired = true
+
+	var issued []penalty.Penalty
+	for _, kind := range []Kind{KindRegistration, KindBooking} {
+		unclosed, err := s.attendanceRepo.ListUnclosed(ctx, tx, kind, s.cfg.CloseAfter.Seconds())
+		if err != nil {
+			return report, err
+		}
+
+		for _, u := range unclosed {
+			final, p := s.judge(u)
+			if p != nil {
+				if err := s.penaltyRepo.CreatePenaltyTx(ctx, tx, *p); err != nil {
+					return report, err
+				}
+				issued = append(issued, *p)
+				report.PenaltiesIssued++
+			}
+			if err := s.attendanceRepo.CloseAttendance(ctx, tx, u.Kind, u.ID, final); err != nil {
+				return report, err
+			}
+			if final == StatusNoShow {
+				report.NoShows++
+			}
+			report.Closed++
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return report, fmt.Errorf("CloseFinished: Failed to commit: %w", err)
+	}
+	report.FinishedAt = time.Now()
+	if report.Closed > 0 {
+		s.audit.Record(ctx, "attendance.close", "attendance", uuid.Nil, nil, report)
+	}
+	for _, p := range issued {
+		s.notifier.Notify(ctx, penalty.GivenNotification(p))
+	}
+	return report, nil
+}
+
+// judge returns the final status of the slot and the penalty to issue, if any
+func (s *AttendanceService) judge(u Unclosed) (Status, *penalty.Penalty) {
+	final := u.Status
+	if final == StatusPending {
+		final = StatusNoShow
+	}
+
+	var penaltyType, reason string
+	var points int
+	switch final {
+	case StatusNoShow:
+		penaltyType, points = "absence", s.cfg.NoShowPoints
+		reason = fmt.Sprintf("did not show up for the %s on %s", u.Kind, u.Start.Format("2006-01-02 15:04"))
+	case StatusLate:
+		penaltyType, points = "late", s.cfg.LatePoints
+		reason = fmt.Sprintf("checked in late for the %s on %s", u.Kind, u.Start.Format("2006-01-02 15:04"))
+	default:
+		return final, nil
+	}
+
+	if points <= 0 || u.AlreadyPenalized {
+		return final, nil
+	}
+
+	return final, &penalty.Penalty{
+		ID:          uuid.New(),
+		UserID:      u.UserID,
+		GivenByID:   u.GivenByID,
+		SessionID:   u.SessionID,
+		BookingID:   u.BookingID,
+		Reason:      "automatic: " + reason,
+		Points:      points,
+		PenaltyType: penaltyType,
+		IsAutomatic: true,
+	}
+}
@@ -0,0 +1,70 @@
+package attendance
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+//check-in tokens are what the QR codes carry: base64url(payload) "." base64url(HMAC-SHA256(payload)),
+//signed with the server key like the JWTs. the payload is binary to keep the code small:
+//1 byte kind, 16 bytes id, 8 bytes expiry (unix seconds, big endian)
+
+const tokenPayloadLen = 1 + 16 + 8
+
+var tokenKinds = map[Kind]byte{KindRegistration: 'r', KindBooking: 'b'}
+
+func signToken(key []byte, kind Kind, id uuid.UUID, exp time.Time) string {
+	payload := make([]byte, 0, tokenPayloadLen)
+	payload = append(payload, tokenKinds[kind])
+	payload = append(payload, id[:]...)
+	payload = binary.BigEndian.AppendUint64(payload, uint64(exp.Unix()))
+
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(tokenMAC(key, payload))
+}
+
+// parseToken checks the signature and the expiry and returns what the token is for
+func parseToken(key []byte, token string, now time.Time) (Kind, uuid.UUID, error) {
+	encPayload, encMAC, ok := strings.Cut(strings.TrimSpace(token), ".")
+	if !ok {
+		return "", uuid.Nil, ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encPayload)
+	if err != nil || len(payload) != tokenPayloadLen {
+		return "", uuid.Nil, ErrInvalidToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(encMAC)
+	if err != nil || !hmac.Equal(mac, tokenMAC(key, payload)) {
+		return "", uuid.Nil, ErrInvalidToken
+	}
+
+	var kind Kind
+	for k, b := range tokenKinds {
+		if payload[0] == b {
+			kind = k
+		}
+	}
+	if kind == "" {
+		return "", uuid.Nil, ErrInvalidToken
+	}
+
+	id, err := uuid.FromBytes(payload[1:17])
+	if err != nil {
+		return "", uuid.Nil, ErrInvalidToken
+	}
+	if now.Unix() > int64(binary.BigEndian.Uint64(payload[17:])) {
+		return "", uuid.Nil, ErrTokenExpired
+	}
+	return kind, id, nil
+}
+
+func tokenMAC(key []byte, payload []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(payload)
+	return h.Sum(nil)
+}
@@ -0,0 +1,76 @@
+package attendance
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestToken(t *testing.T) {
+	key := []byte("test-key")
+	id := uuid.MustParse("5f0c6a52-8d0e-4b7f-9c1a-2e3d4f5a6b7c")
+	exp := time.Date(2025, 3, 7, 18, 0, 0, 0, time.UTC)
+	valid := signToken(key, KindBooking, id, exp)
+
+	payload, mac, _ := strings.Cut(valid, ".")
+	raw, _ := base64.RawURLEncoding.DecodeString(payload)
+	flip := func(i int) string {
+		b := append([]byte(nil), raw...)
+		b[i] ^= 0x01
+		return base64.RawURLEncoding.EncodeToString(b) + "." + mac
+	}
+
+	tests := []struct {
+		name     string
+		key      []byte
+		token    string
+		now      time.Time
+		wantKind Kind
+		wantErr  error
+	}{
+		{name: "valid", key: key, token: valid, now: exp.Add(-time.Hour), wantKind: KindBooking},
+		{name: "at expiry", key: key, token: valid, now: exp, wantKind: KindBooking},
+		{name: "surrounding whitespace", key: key, token: " " + valid + "\n", now: exp.Add(-time.Hour), wantKind: KindBooking},
+		{name: "registration", key: key, token: signToken(key, KindRegistration, id, exp), now: exp.Add(-time.Hour), wantKind: KindRegistration},
+		{name: "expired", key: key, token: valid, now: exp.Add(time.Second), wantErr: ErrTokenExpired},
+		{name: "other key", key: []byte("other-key"), token: valid, now: exp.Add(-time.Hour), wantErr: ErrInvalidToken},
+		{name: "tampered kind", key: key, token: flip(0), now: exp.Add(-time.Hour), wantErr: ErrInvalidToken},
+		{name: "tampered id", key: key, token: flip(5), now: exp.Add(-time.Hour), wantErr: ErrInvalidToken},
+		{name: "tampered expiry", key: key, token: flip(24), now: exp.Add(-time.Hour), wantErr: ErrInvalidToken},
+		{name: "tampered mac", key: key, token: payload + "." + base64.RawURLEncoding.EncodeToString(make([]byte, 32)), now: exp.Add(-time.Hour), wantErr: ErrInvalidToken},
+		{name: "no separator", key: key, token: payload + mac, now: exp.Add(-time.Hour), wantErr: ErrInvalidToken},
+		{name: "short payload", key: key, token: base64.RawURLEncoding.EncodeToString(raw[:10]) + "." + mac, now: exp.Add(-time.Hour), wantErr: ErrInvalidToken},
+		{name: "not base64", key: key, token: "!!!." + mac, now: exp.Add(-time.Hour), wantErr: ErrInvalidToken},
+		{name: "empty", key: key, token: "", now: exp.Add(-time.Hour), wantErr: ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kind, gotID, err := parseToken(tt.key, tt.token, tt.now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if kind != tt.wantKind || gotID != id {
+				t.Errorf("got %s %s, want %s %s", kind, gotID, tt.wantKind, id)
+			}
+		})
+	}
+}
+
+func TestTokenUnknownKind(t *testing.T) {
+	//a correctly signed payload with a kind byte the parser does not know
+	key := []byte("test-key")
+	payload := make([]byte, tokenPayloadLen)
+	payload[0] = 'x'
+	token := base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(tokenMAC(key, payload))
+
+	if _, _, err := parseToken(key, token, time.Unix(0, 0)); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("err = %v, want ErrInvalidToken", err)
+	}
+}
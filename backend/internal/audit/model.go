@@ -0,0 +1,57 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Entry is one row of the audit log, Before/After are JSON snapshots of the entity (null on create/delete)
+type Entry struct {
+	ID         uuid.UUID
+	ActorID    *uuid.UUID // nil for background jobs and anonymous requests
+	ActorRole  string
+	Action     string // <entity>.<verb>, e.g. booking.cancel
+	EntityType string
+	EntityID   *uuid.UUID
+	Before     json.RawMessage
+	After      json.RawMessage
+	IP         string
+	UserAgent  string
+	RequestID  string
+	CreatedAt  time.Time
+}
+
+// Meta is the request metadata the transport layer puts into the context, the services never pass it around by hand
+type Meta struct {
+	ActorID   *uuid.UUID
+	ActorRole string
+	IP        string
+	UserAgent string
+	RequestID string
+}
+
+type metaKey struct{}
+
+func WithMeta(ctx context.Context, m Meta) context.Context {
+	return context.WithValue(ctx, metaKey{}, m)
+}
+
+// MetaFrom returns the metadata of the request, the zero Meta outside of a request (background jobs)
+func MetaFrom(ctx context.Context) Meta {
+	m, _ := ctx.Value(metaKey{}).(Meta)
+	return m
+}
+
+// Filter for the admin query, zero fields are not filtered on
+type Filter struct {
+	ActorID    *uuid.UUID
+	EntityType string
+	EntityID   *uuid.UUID
+	Action     string
+	From       *time.Time
+	To         *time.Time
+	Offset     int
+}
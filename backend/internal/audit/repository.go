@@ -0,0 +1,98 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AuditRepository interface {
+	Insert(ctx context.Context, e Entry) error
+	List(ctx context.Context, f Filter) ([]Entry, error)
+}
+
+type AuditRepositoryPostgres struct {
+	pool *pgxpool.Pool
+}
+
+func NewAuditRepositoryPostgres(pool *pgxpool.Pool) *AuditRepositoryPostgres {
+	return &AuditRepositoryPostgres{pool: pool}
+}
+
+func (r *AuditRepositoryPostgres) Insert(ctx context.Context, e Entry) error {
+	query := `INSERT INTO audit_log (actor_id, actor_role, action, entity_type, entity_id, before, after, ip, user_agent, request_id)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	_, err := r.pool.Exec(ctx, query, e.ActorID, e.ActorRole, e.Action, e.EntityType, e.EntityID,
+		nullJSON(e.Before), nullJSON(e.After), e.IP, e.UserAgent, e.RequestID)
+	if err != nil {
+		return fmt.Errorf("Insert: Failed to INSERT: %w", err)
+	}
+	return nil
+}
+
+func (r *AuditRepositoryPostgres) List(ctx context.Context, f Filter) ([]Entry, error) {
+	var where []string
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+
+	if f.ActorID != nil {
+		add("actor_id = $%d", *f.ActorID)
+	}
+	if f.EntityType != "" {
+		add("entity_type = $%d", f.EntityType)
+	}
+	if f.EntityID != nil {
+		add("entity_id = $%d", *f.EntityID)
+	}
+	if f.Action != "" {
+		add("action = $%d", f.Action)
+	}
+	if f.From != nil {
+		add("created_at >= $%d", *f.From)
+	}
+	if f.To != nil {
+		add("created_at < $%d", *f.To)
+	}
+
+	query := `SELECT entry_id, actor_id, actor_role, action, entity_type, entity_id, before, after, ip, user_agent, request_id, created_at
+			  FROM audit_log`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	args = append(args, f.Offset)
+	query += fmt.Sprintf(" ORDER BY created_at DESC OFFSET $%d LIMIT 50", len(args))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("List: Failed to SELECT: %w", err)
+	}
+	defer rows.Close()
+
+	resp := make([]Entry, 0)
+	for rows.Next() {
+		var e Entry
+		var before, after []byte
+		err := rows.Scan(&e.ID, &e.ActorID, &e.ActorRole, &e.Action, &e.EntityType, &e.EntityID,
+			&before, &after, &e.IP, &e.UserAgent, &e.RequestID, &e.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("List: Failed to SCAN: %w", err)
+		}
+		e.Before, e.After = before, after
+		resp = append(resp, e)
+	}
+	return resp, rows.Err()
+}
+
+// nullJSON stores a missing snapshot as SQL NULL instead of the JSON null
+func nullJSON(raw []byte) any {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	return string(raw)
+}
@@ -0,0 +1,69 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Recorder is what the other services depend on, every mutating service method calls Record once it succeeded
+type Recorder interface {
+	Record(ctx context.Context, action, entityType string, entityID uuid.UUID, before, after any)
+}
+
+type AuditService struct {
+	auditRepo AuditRepository
+	logger    *zap.Logger
+}
+
+func NewAuditService(r AuditRepository, logger *zap.Logger) *AuditService {
+	return &AuditService{
+		auditRepo: r,
+		logger:    logger,
+	}
+}
+
+// Record writes the entry with the actor and the request metadata from ctx. before/after are marshalled to JSON,
+// nil means there is no snapshot. a failed write is logged and swallowed: the change it describes is already committed
+// and the caller must not report it as failed
+func (s *AuditService) Record(ctx context.Context, action, entityType string, entityID uuid.UUID, before, after any) {
+	meta := MetaFrom(ctx)
+	e := Entry{
+		ActorID:    meta.ActorID,
+		ActorRole:  meta.ActorRole,
+		Action:     action,
+		EntityType: entityType,
+		IP:         meta.IP,
+		UserAgent:  meta.UserAgent,
+		RequestID:  meta.RequestID,
+	}
+	if entityID != uuid.Nil {
+		e.EntityID = &entityID
+	}
+
+	var err error
+	if e.Before, err = snapshot(before); err == nil {
+		e.After, err = snapshot(after)
+	}
+	if err == nil {
+		// the request may be gone by now, the entry still has to be written
+		err = s.auditRepo.Insert(context.WithoutCancel(ctx), e)
+	}
+	if err != nil {
+		s.logger.Error("failed to write the audit log",
+			zap.String("action", action), zap.String("entity_type", entityType), zap.Stringer("entity_id", entityID), zap.Error(err))
+	}
+}
+
+func (s *AuditService) List(ctx context.Context, f Filter) ([]Entry, error) {
+	return s.auditRepo.List(ctx, f)
+}
+
+func snapshot(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
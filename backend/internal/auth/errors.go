@@ -0,0 +1,15 @@
+package auth
+
+import "errors"
+
+var (
+	ErrInvalidCredentials  = errors.New("invalid email or password")
+	ErrWrongPassword       = errors.New("current password is wrong")
+	ErrUserInactive        = errors.New("user is deactivated")
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token was already used, session revoked")
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrInvalidToken        = errors.New("invalid, expired or already used token")
+	ErrEmailNotVerified    = errors.New("email is not verified")
+	ErrAlreadyVerified     = errors.New("email is already verified")
+)
@@ -4,18 +4,33 @@ package auth
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
-func createNewJWT(userID string, key string) (string, error) {
+// keys under which the middleware stores the token data in the request context
+const (
+	CtxUserID       = "userID"
+	CtxRole         = "role"
+	CtxTokenVersion = "tokenVersion"
+	CtxSessionID    = "sessionID"
+)
+
+func createNewJWT(userID string, role string, tokenVersion int, sessionID string, exp time.Time, key string) (string, error) {
 	claims := jwt.MapClaims{
-		"sub": userID,
-		"exp": time.Now().Add(24 * time.Hour).Unix(),
+		"sub":  userID,
+		"role": role,
+		"tv":   tokenVersion,
+		"sid":  sessionID,
+		"exp":  exp.Unix(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -32,13 +47,13 @@ func (s *AuthService) JWTMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
-			http.Error(w, "missing token", 400)
+			authError(w, http.StatusUnauthorized, "missing_token", "missing token")
 			return
 		}
 
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			http.Error(w, "invalid token format", 400)
+			authError(w, http.StatusUnauthorized, "invalid_token", "invalid token format")
 			return
 		}
 
@@ -52,23 +67,109 @@ func (s *AuthService) JWTMiddleware(next http.Handler) http.Handler {
 		})
 
 		if err != nil || !token.Valid {
-			http.Error(w, "invalid token", http.StatusUnauthorized)
+			authError(w, http.StatusUnauthorized, "invalid_token", "invalid token")
 			return
 		}
 
 		claims, ok := token.Claims.(jwt.MapClaims)
 		if !ok {
-			http.Error(w, "invalid token claims", http.StatusUnauthorized)
+			authError(w, http.StatusUnauthorized, "invalid_token", "invalid token claims")
 			return
 		}
 
 		userId, ok := claims["sub"].(string)
 		if !ok {
-			http.Error(w, "invalid token payload", http.StatusUnauthorized)
+			authError(w, http.StatusUnauthorized, "invalid_token", "invalid token payload")
+			return
+		}
+
+		role, ok := claims["role"].(string)
+		if !ok {
+			authError(w, http.StatusUnauthorized, "invalid_token", "invalid token payload")
+			return
+		}
+
+		// json numbers are decoded as float64
+		tv, ok := claims["tv"].(float64)
+		if !ok {
+			authError(w, http.StatusUnauthorized, "invalid_token", "invalid token payload")
 			return
 		}
 
-		ctx := context.WithValue(r.Context(), "userID", userId)
+		sid, ok := claims["sid"].(string)
+		if !ok {
+			authError(w, http.StatusUnauthorized, "invalid_token", "invalid token payload")
+			return
+		}
+
+		//the token is signed, but the session might be revoked, the user deactivated or the role changed since then
+		if err := s.checkSession(r.Context(), sid, userId, int(tv)); err != nil {
+			authError(w, http.StatusUnauthorized, "session_revoked", err.Error())
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), CtxUserID, userId)
+		ctx = context.WithValue(ctx, CtxRole, role)
+		ctx = context.WithValue(ctx, CtxTokenVersion, int(tv))
+		ctx = context.WithValue(ctx, CtxSessionID, sid)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// authError writes the same json envelope the http package uses, the middleware can't import it
+func authError(w http.ResponseWriter, status int, code string, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{
+		"success": false,
+		"code":    code,
+		"message": message,
+	})
+}
+
+// SessionIDFromContext returns the session the access token belongs to
+func SessionIDFromContext(ctx context.Context) (uuid.UUID, error) {
+	sid, ok := ctx.Value(CtxSessionID).(string)
+	if !ok {
+		return uuid.Nil, errors.New("no sessionID in context")
+	}
+	return uuid.Parse(sid)
+}
+
+// RoleFromContext returns the role the JWT was issued with, empty if the request did not pass the JWTMiddleware
+func RoleFromContext(ctx context.Context) string {
+	role, _ := ctx.Value(CtxRole).(string)
+	return role
+}
+
+func (s *AuthService) checkSession(ctx context.Context, sid, userID string, tokenVersion int) error {
+	sessionID, err := uuid.Parse(sid)
+	if err != nil {
+		return errors.New("invalid token payload")
+	}
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return errors.New("invalid token payload")
+	}
+
+	st, err := s.sessions.GetSessionState(ctx, sessionID, uid)
+	if err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			return errors.New("session not found")
+		}
+		log.Println("failed to check the session:", err)
+		return errors.New("failed to check the session")
+	}
+
+	switch {
+	case !st.UserActive:
+		return ErrUserInactive
+	case st.Revoked:
+		return errors.New("session was revoked")
+	case st.Expired:
+		return errors.New("session expired")
+	case st.TokenVersion != tokenVersion:
+		return errors.New("token is outdated, refresh it")
+	}
+	return nil
+}
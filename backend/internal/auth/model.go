@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session is one login of a user, it lives as long as its refresh token keeps being rotated
+type Session struct {
+	ID                uuid.UUID
+	UserID            uuid.UUID
+	RefreshTokenHash  string
+	PreviousTokenHash *string
+	UserAgent         string
+	ExpiresAt         time.Time
+	CreatedAt         time.Time
+	LastUsedAt        time.Time
+	RevokedAt         *time.Time
+}
+
+// SessionState is what the middleware needs to know on every request
+type SessionState struct {
+	UserActive   bool
+	TokenVersion int
+	Revoked      bool
+	Expired      bool
+}
+
+// token purposes, stored in user_tokens.purpose
+const (
+	PurposePasswordReset     = "password_reset"
+	PurposeEmailVerification = "email_verification"
+)
+
+// UserToken is a single-use token sent by email, the plain token is never stored
+type UserToken struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Purpose   string
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// TokenPair is returned on login and refresh
+type TokenPair struct {
+	AccessToken      string
+	RefreshToken     string
+	AccessExpiresAt  time.Time
+	RefreshExpiresAt time.Time
+}
@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SessionRepository interface {
+	CreateSession(ctx context.Context, s Session) (uuid.UUID, error)
+	// GetByRefreshHash finds the session by its current refresh token, reused is true if the hash matched the previous token
+	GetByRefreshHash(ctx context.Context, hash string) (s Session, reused bool, err error)
+	// RotateRefreshToken swaps the refresh token only if oldHash is still the current one, false means someone else rotated it first
+	RotateRefreshToken(ctx context.Context, sessionID uuid.UUID, oldHash, newHash string, expiresAt time.Time) (bool, error)
+	RevokeSession(ctx context.Context, sessionID uuid.UUID) error
+	RevokeUserSessions(ctx context.Context, userID uuid.UUID) error
+	RevokeOtherSessions(ctx context.Context, userID, keep uuid.UUID) error
+	GetSessionState(ctx context.Context, sessionID, userID uuid.UUID) (SessionState, error)
+}
+
+type SessionRepositoryPostgres struct {
+	pool *pgxpool.Pool
+}
+
+func NewSessionRepositoryPostgres(pool *pgxpool.Pool) *SessionRepositoryPostgres {
+	return &SessionRepositoryPostgres{pool: pool}
+}
+
+func (r *SessionRepositoryPostgres) CreateSession(ctx context.Context, s Session) (uuid.UUID, error) {
+	query := `INSERT INTO auth_sessions (user_id, refresh_token_hash, user_agent, expires_at)
+			  VALUES ($1, $2, $3, $4)
+			  RETURNING session_id`
+
+	var id uuid.UUID
+	if err := r.pool.QueryRow(ctx, query, s.UserID, s.RefreshTokenHash, s.UserAgent, s.ExpiresAt).Scan(&id); err != nil {
+		return uuid.Nil, fmt.Errorf("repository.CreateSession: %w", err)
+	}
+	return id, nil
+}
+
+func (r *SessionRepositoryPostgres) GetByRefreshHash(ctx context.Context, hash string) (Session, bool, error) {
+	query := `SELECT session_id, user_id, refresh_token_hash, previous_token_hash, COALESCE(user_agent, ''),
+				expires_at, created_at, last_used_at, revoked_at
+			  FROM auth_sessions
+			  WHERE refresh_token_hash = $1 OR previous_token_hash = $1
+			  LIMIT 1`
+
+	var s Session
+	err := r.pool.QueryRow(ctx, query, hash).Scan(&s.ID, &s.UserID, &s.RefreshTokenHash, &s.PreviousTokenHash, &s.UserAgent,
+		&s.ExpiresAt, &s.CreatedAt, &s.LastUsedAt, &s.RevokedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Session{}, false, ErrSessionNotFound
+		}
+		return Session{}, false, fmt.Errorf("repository.GetByRefreshHash: %w", err)
+	}
+	return s, s.RefreshTokenHash != hash, nil
+}
+
+func (r *SessionRepositoryPostgres) RotateRefreshToken(ctx context.Context, sessionID uuid.UUID, oldHash, newHash string, expiresAt time.Time) (bool, error) {
+	query := `UPDATE auth_sessions
+			  SET previous_token_hash = refresh_token_hash, refresh_token_hash = $3, expires_at = $4, last_used_at = NOW()
+			  WHERE session_id = $1 AND refresh_token_hash = $2 AND revoked_at IS NULL`
+
+	tag, err := r.pool.Exec(ctx, query, sessionID, oldHash, newHash, expiresAt)
+	if err != nil {
+		return false, fmt.Errorf("repository.RotateRefreshToken: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *SessionRepositoryPostgres) RevokeSession(ctx context.Context, sessionID uuid.UUID) error {
+	query := `UPDATE auth_sessions SET revoked_at = NOW() WHERE session_id = $1 AND revoked_at IS NULL`
+
+	if _, err := r.pool.Exec(ctx, query, sessionID); err != nil {
+		return fmt.Errorf("repository.RevokeSession: %w", err)
+	}
+	return nil
+}
+
+func (r *SessionRepositoryPostgres) RevokeUserSessions(ctx context.Context, userID uuid.UUID) error {
+	query := `UPDATE auth_sessions SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`
+
+	if _, err := r.pool.Exec(ctx, query, userID); err != nil {
+		return fmt.Errorf("repository.RevokeUserSessions: %w", err)
+	}
+	return nil
+}
+
+func (r *SessionRepositoryPostgres) RevokeOtherSessions(ctx context.Context, userID, keep uuid.UUID) error {
+	query := `UPDATE auth_sessions SET revoked_at = NOW() WHERE user_id = $1 AND session_id <> $2 AND revoked_at IS NULL`
+
+	if _, err := r.pool.Exec(ctx, query, userID, keep); err != nil {
+		return fmt.Errorf("repository.RevokeOtherSessions: %w", err)
+	}
+	return nil
+}
+
+func (r *SessionRepositoryPostgres) GetSessionState(ctx context.Context, sessionID, userID uuid.UUID) (SessionState, error) {
+	query := `SELECT COALESCE(u.is_active, TRUE), u.token_version, s.revoked_at IS NOT NULL, s.expires_at <= NOW()
+			  FROM auth_sessions s
+			  JOIN users u ON u.user_id = s.user_id
+			  WHERE s.session_id = $1 AND s.user_id = $2`
+
+	var st SessionState
+	err := r.pool.QueryRow(ctx, query, sessionID, userID).Scan(&st.UserActive, &st.TokenVersion, &st.Revoked, &st.Expired)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return SessionState{}, ErrSessionNotFound
+		}
+		return SessionState{}, fmt.Errorf("repository.GetSessionState: %w", err)
+	}
+	return st, nil
+}
+
+type UserTokenRepository interface {
+	// CreateToken stores the token and invalidates the previous unused tokens of the same user and purpose
+	CreateToken(ctx context.Context, t UserToken) error
+	// ConsumeToken marks the token as used and returns its owner, it fails with ErrInvalidToken if it was used or expired
+	ConsumeToken(ctx context.Context, hash, purpose string) (uuid.UUID, error)
+}
+
+type UserTokenRepositoryPostgres struct {
+	pool *pgxpool.Pool
+}
+
+func NewUserTokenRepositoryPostgres(pool *pgxpool.Pool) *UserTokenRepositoryPostgres {
+	return &UserTokenRepositoryPostgres{pool: pool}
+}
+
+func (r *UserTokenRepositoryPostgres) CreateToken(ctx context.Context, t UserToken) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("repository.CreateToken: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	//only the newest link in the inbox should work
+	_, err = tx.Exec(ctx, `UPDATE user_tokens SET used_at = NOW() WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`, t.UserID, t.Purpose)
+	if err != nil {
+		return fmt.Errorf("repository.CreateToken: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `INSERT INTO user_tokens (user_id, purpose, token_hash, expires_at) VALUES ($1, $2, $3, $4)`,
+		t.UserID, t.Purpose, t.TokenHash, t.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("repository.CreateToken: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *UserTokenRepositoryPostgres) ConsumeToken(ctx context.Context, hash, purpose string) (uuid.UUID, error) {
+	query := `UPDATE user_tokens SET used_at = NOW()
+			  WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+			  RETURNING user_id`
+
+	var userID uuid.UUID
+	if err := r.pool.QueryRow(ctx, query, hash, purpose).Scan(&userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, ErrInvalidToken
+		}
+		return uuid.Nil, fmt.Errorf("repository.ConsumeToken: %w", err)
+	}
+	return userID, nil
+}
@@ -2,71 +2,348 @@ package auth
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"log"
+	"net/url"
+	"t/internal/audit"
+	"t/internal/mailer"
 	"t/internal/user"
+	"time"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthService struct {
-	jwtKey   string
-	userRepo user.UserRepostiory
+	jwtKey     string
+	userRepo   user.UserRepostiory
+	sessions   SessionRepository
+	tokens     UserTokenRepository
+	mail       mailer.Mailer
+	appURL     string
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	// when set, users have to verify their email before they can log in
+	requireVerified bool
+	audit           audit.Recorder
 }
 
 const (
 	ADMIN   = "admin"
 	STAFF   = "staff"
 	TRAINER = "trainer"
+	STUDENT = "student"
 )
 
-func NewAuthSerivce(key string, userRep user.UserRepostiory) *AuthService {
+const (
+	passwordResetTTL     = time.Hour
+	emailVerificationTTL = 48 * time.Hour
+)
+
+func NewAuthSerivce(key string, userRep user.UserRepostiory, sessionRep SessionRepository, tokenRep UserTokenRepository, mail mailer.Mailer, appURL string, accessTTL, refreshTTL time.Duration, requireVerified bool, auditRec audit.Recorder) *AuthService {
 	return &AuthService{
-		jwtKey:   key,
-		userRepo: userRep,
+		jwtKey:          key,
+		userRepo:        userRep,
+		sessions:        sessionRep,
+		tokens:          tokenRep,
+		mail:            mail,
+		appURL:          appURL,
+		accessTTL:       accessTTL,
+		refreshTTL:      refreshTTL,
+		requireVerified: requireVerified,
+		audit:           auditRec,
 	}
 }
 
-// this func will handle the login functionality, will return the JWT
-func (s *AuthService) LoginUser(ctx context.Context, email, password string) (string, error) {
+// this func will handle the login functionality, it opens a new session and returns the access and refresh tokens
+func (s *AuthService) LoginUser(ctx context.Context, email, password, userAgent string) (TokenPair, error) {
 
 	//get the user from the repo
 	userID, hash, err := s.userRepo.GetByEmail(ctx, email)
 	if err != nil {
-		return "", err
+		log.Println("login failed:", err)
+		return TokenPair{}, ErrInvalidCredentials
 	}
 
 	//check for the password
 	if !CheckPasswordHash(password, hash) {
-		return "", fmt.Errorf("invalid password was provided")
+		return TokenPair{}, ErrInvalidCredentials
 	}
 
-	//create a JWT
+	//role and token version go into the claims, so handlers dont need to ask the DB for the role
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return TokenPair{}, fmt.Errorf("LoginUser: %w", err)
+	}
+	if !u.IsActive {
+		return TokenPair{}, ErrUserInactive
+	}
+	if s.requireVerified && u.EmailVerifiedAt == nil {
+		return TokenPair{}, ErrEmailNotVerified
+	}
 
-	jwt, err := createNewJWT(userID.String(), s.jwtKey)
+	refresh, refreshHash, err := newOpaqueToken()
 	if err != nil {
-		log.Println("fucked")
-		return "", err
+		return TokenPair{}, fmt.Errorf("LoginUser: %w", err)
+	}
+
+	refreshExp := time.Now().Add(s.refreshTTL)
+	sessionID, err := s.sessions.CreateSession(ctx, Session{
+		UserID:           userID,
+		RefreshTokenHash: refreshHash,
+		UserAgent:        userAgent,
+		ExpiresAt:        refreshExp,
+	})
+	if err != nil {
+		return TokenPair{}, fmt.Errorf("LoginUser: %w", err)
+	}
+
+	return s.issue(u, sessionID, refresh, refreshExp)
+}
+
+// Refresh rotates the refresh token and returns a fresh pair. Presenting an already rotated token
+// means it leaked, so the whole session is revoked.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (TokenPair, error) {
+	oldHash := hashToken(refreshToken)
+
+	sess, reused, err := s.sessions.GetByRefreshHash(ctx, oldHash)
+	if err != nil {
+		if err == ErrSessionNotFound {
+			return TokenPair{}, ErrInvalidRefreshToken
+		}
+		return TokenPair{}, fmt.Errorf("Refresh: %w", err)
+	}
+
+	if reused {
+		if err := s.sessions.RevokeSession(ctx, sess.ID); err != nil {
+			return TokenPair{}, fmt.Errorf("Refresh: %w", err)
+		}
+		return TokenPair{}, ErrRefreshTokenReused
+	}
+
+	if sess.RevokedAt != nil || time.Now().After(sess.ExpiresAt) {
+		return TokenPair{}, ErrInvalidRefreshToken
+	}
+
+	u, err := s.userRepo.GetByID(ctx, sess.UserID)
+	if err != nil {
+		return TokenPair{}, fmt.Errorf("Refresh: %w", err)
+	}
+	if !u.IsActive {
+		if err := s.sessions.RevokeSession(ctx, sess.ID); err != nil {
+			return TokenPair{}, fmt.Errorf("Refresh: %w", err)
+		}
+		return TokenPair{}, ErrUserInactive
+	}
+
+	refresh, newHash, err := newOpaqueToken()
+	if err != nil {
+		return TokenPair{}, fmt.Errorf("Refresh: %w", err)
+	}
+
+	refreshExp := time.Now().Add(s.refreshTTL)
+	rotated, err := s.sessions.RotateRefreshToken(ctx, sess.ID, oldHash, newHash, refreshExp)
+	if err != nil {
+		return TokenPair{}, fmt.Errorf("Refresh: %w", err)
+	}
+	// a concurrent refresh with the same token won the race
+	if !rotated {
+		return TokenPair{}, ErrInvalidRefreshToken
+	}
+
+	return s.issue(u, sess.ID, refresh, refreshExp)
+}
+
+// Logout revokes the session the refresh token belongs to, unknown tokens are ignored
+func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
+	sess, _, err := s.sessions.GetByRefreshHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		if err == ErrSessionNotFound {
+			return nil
+		}
+		return fmt.Errorf("Logout: %w", err)
+	}
+	return s.sessions.RevokeSession(ctx, sess.ID)
+}
+
+// LogoutAll revokes every session of the user, e.g. after a password change or deactivation
+func (s *AuthService) LogoutAll(ctx context.Context, userID uuid.UUID) error {
+	if err := s.sessions.RevokeUserSessions(ctx, userID); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "user.logout_all", "user", userID, nil, nil)
+	return nil
+}
+
+func (s *AuthService) issue(u user.User, sessionID uuid.UUID, refresh string, refreshExp time.Time) (TokenPair, error) {
+	accessExp := time.Now().Add(s.accessTTL)
+	jwt, err := createNewJWT(u.ID.String(), u.Role, u.TokenVersion, sessionID.String(), accessExp, s.jwtKey)
+	if err != nil {
+		log.Println("failed to sign the JWT", err)
+		return TokenPair{}, err
+	}
+
+	return TokenPair{
+		AccessToken:      jwt,
+		RefreshToken:     refresh,
+		AccessExpiresAt:  accessExp,
+		RefreshExpiresAt: refreshExp,
+	}, nil
+}
+
+// ChangePassword sets a new password after checking the current one, every other session of the user is logged out
+func (s *AuthService) ChangePassword(ctx context.Context, userID, sessionID uuid.UUID, current, newPassword string) error {
+	if err := s.CheckPassword(ctx, userID, current); err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("ChangePassword: %w", err)
 	}
+	if err := s.userRepo.UpdatePassword(ctx, userID, string(hash)); err != nil {
+		return fmt.Errorf("ChangePassword: %w", err)
+	}
+
+	if err := s.sessions.RevokeOtherSessions(ctx, userID, sessionID); err != nil {
+		return fmt.Errorf("ChangePassword: %w", err)
+	}
+	s.audit.Record(ctx, "user.password_change", "user", userID, nil, nil)
+	return nil
+}
+
+// CheckPassword re-authenticates the user before sensitive actions
+func (s *AuthService) CheckPassword(ctx context.Context, userID uuid.UUID, password string) error {
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("CheckPassword: %w", err)
+	}
+	if !CheckPasswordHash(password, u.Password) {
+		return ErrWrongPassword
+	}
+	return nil
+}
+
+// RequestPasswordReset mails a reset link. Unknown emails are not reported, so the endpoint cannot be used to probe accounts.
+func (s *AuthService) RequestPasswordReset(ctx context.Context, email string) error {
+	userID, _, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		log.Println("password reset for unknown email:", err)
+		return nil
+	}
+
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("RequestPasswordReset: %w", err)
+	}
+	if !u.IsActive {
+		return nil
+	}
+
+	token, err := s.newUserToken(ctx, userID, PurposePasswordReset, passwordResetTTL)
+	if err != nil {
+		return fmt.Errorf("RequestPasswordReset: %w", err)
+	}
+
+	body := fmt.Sprintf("Hi %s,\n\nsomeone asked to reset the password of your account. If it was you, open the link below within %s:\n\n%s\n\nIf it wasn't you, ignore this email.\n",
+		u.FirstName, passwordResetTTL, s.link("/reset-password", token))
+	return s.mail.Send(ctx, mailer.Message{To: u.Email, Subject: "Reset your password", Body: body})
+}
+
+// ResetPassword sets a new password with a token from the reset email and logs the user out everywhere
+func (s *AuthService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	userID, err := s.tokens.ConsumeToken(ctx, hashToken(token), PurposePasswordReset)
+	if err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("ResetPassword: %w", err)
+	}
+	if err := s.userRepo.UpdatePassword(ctx, userID, string(hash)); err != nil {
+		return fmt.Errorf("ResetPassword: %w", err)
+	}
+
+	//whoever had the old password might still hold a session
+	if err := s.sessions.RevokeUserSessions(ctx, userID); err != nil {
+		return fmt.Errorf("ResetPassword: %w", err)
+	}
+	s.audit.Record(ctx, "user.password_reset", "user", userID, nil, nil)
+	return nil
+}
+
+// SendVerificationEmail mails a new verification link, older links stop working
+func (s *AuthService) SendVerificationEmail(ctx context.Context, userID uuid.UUID) error {
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("SendVerificationEmail: %w", err)
+	}
+	if u.EmailVerifiedAt != nil {
+		return ErrAlreadyVerified
+	}
+
+	token, err := s.newUserToken(ctx, userID, PurposeEmailVerification, emailVerificationTTL)
+	if err != nil {
+		return fmt.Errorf("SendVerificationEmail: %w", err)
+	}
+
+	body := fmt.Sprintf("Hi %s,\n\nplease confirm your email address by opening the link below within %s:\n\n%s\n",
+		u.FirstName, emailVerificationTTL, s.link("/verify-email", token))
+	return s.mail.Send(ctx, mailer.Message{To: u.Email, Subject: "Confirm your email", Body: body})
+}
 
-	return jwt, nil
+// VerifyEmail marks the email of the token owner as verified
+func (s *AuthService) VerifyEmail(ctx context.Context, token string) error {
+	userID, err := s.tokens.ConsumeToken(ctx, hashToken(token), PurposeEmailVerification)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.MarkEmailVerified(ctx, userID); err != nil {
+		return fmt.Errorf("VerifyEmail: %w", err)
+	}
+	return nil
 }
 
-func (s *AuthService) IsAdmin(ctx context.Context, id uuid.UUID) bool {
-	role, err := s.userRepo.GetRole(ctx, id)
-	if err != nil || role != ADMIN {
-		return false
+func (s *AuthService) newUserToken(ctx context.Context, userID uuid.UUID, purpose string, ttl time.Duration) (string, error) {
+	token, hash, err := newOpaqueToken()
+	if err != nil {
+		return "", err
 	}
-	return true
+	err = s.tokens.CreateToken(ctx, UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// link builds the frontend url the email points to
+func (s *AuthService) link(path, token string) string {
+	return s.appURL + path + "?token=" + url.QueryEscape(token)
 }
 
-func (s *AuthService) IsTrainer(ctx context.Context, id uuid.UUID) bool {
-	role, err := s.userRepo.GetRole(ctx, id)
-	if err != nil || role != TRAINER {
-		return false
+// newOpaqueToken returns a random token and the hash that is stored in the DB
+func newOpaqueToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
 	}
-	return true
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
 // CheckPasswordHash compares a plaintext password with a bcrypt hash.
@@ -0,0 +1,78 @@
+package availability
+
+import (
+	"fmt"
+	"t/pkg/rrule"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// wholeDay is the closed interval of a closure without times
+var wholeDay = Interval{Start: 0, End: 24 * 60}
+
+// Closure closes the facility for maintenance or an event. one-off closures cover every date from StartDate to EndDate,
+// recurring ones (Rule set) only the occurrences of the rule, open ended when EndDate is nil.
+// without Window the whole day is closed
+type Closure struct {
+	ID         uuid.UUID
+	FacilityID uuid.UUID
+	Reason     string
+	StartDate  time.Time
+	EndDate    *time.Time
+	Window     *Interval
+	Rule       string
+	CreatedBy  *uuid.UUID
+	CreatedAt  time.Time
+}
+
+// Validate checks the closure before it is stored, one-off closures without EndDate last one day
+func (c *Closure) Validate() error {
+	if c.Reason == "" {
+		return fmt.Errorf("%w: reason is required", ErrInvalidClosure)
+	}
+	if c.EndDate != nil && c.EndDate.Before(c.StartDate) {
+		return fmt.Errorf("%w: end_date is before start_date", ErrInvalidClosure)
+	}
+	if c.Window != nil && c.Window.Empty() {
+		return fmt.Errorf("%w: end_time must be after start_time", ErrInvalidClosure)
+	}
+	if c.Rule != "" {
+		if _, err := rrule.Parse(c.Rule); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidClosure, err)
+		}
+	} else if c.EndDate == nil {
+		end := c.StartDate
+		c.EndDate = &end
+	}
+	return nil
+}
+
+// On returns the interval the closure keeps the facility closed on date, if any
+func (c Closure) On(date time.Time) (Interval, bool) {
+	date = dateOnly(date)
+	if date.Before(dateOnly(c.StartDate)) || (c.EndDate != nil && date.After(dateOnly(*c.EndDate))) {
+		return Interval{}, false
+	}
+	if c.Rule != "" {
+		r, err := rrule.Parse(c.Rule)
+		if err != nil || !r.Occurs(c.StartDate, date) {
+			return Interval{}, false
+		}
+	}
+	if c.Window == nil {
+		return wholeDay, true
+	}
+	return *c.Window, true
+}
+
+// closedOn turns the closures active on date into busy intervals
+func closedOn(closures []Closure, date time.Time) []Busy {
+	busy := make([]Busy, 0)
+	for _, c := range closures {
+		if i, ok := c.On(date); ok {
+			busy = append(busy, Busy{Interval: i, Date: date, Kind: KindClosure, RefID: c.ID, Reason: c.Reason})
+		}
+	}
+	return busy
+}
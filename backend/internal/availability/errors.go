@@ -0,0 +1,14 @@
+package availability
+
+import "errors"
+
+var (
+	ErrInvalidRange = errors.New("invalid date range")
+	ErrInvalidSlot  = errors.New("invalid slot length")
+	// ErrFacilityOccupied is returned wrapped together with the conflicting bookings and sessions
+	ErrFacilityOccupied = errors.New("facility is occupied during this time")
+	ErrFacilityClosed   = errors.New("facility is closed during this time")
+	ErrHoliday          = errors.New("date is a campus holiday")
+	ErrInvalidClosure   = errors.New("invalid closure")
+	ErrClosureNotFound  = errors.New("closure not found")
+)
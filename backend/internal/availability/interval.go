@@ -0,0 +1,111 @@
+package availability
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Interval is the half-open range [Start, End) in minutes since midnight
+type Interval struct {
+	Start int
+	End   int
+}
+
+// ClockInterval is the interval between two time-of-day values
+func ClockInterval(start, end time.Time) Interval {
+	return Interval{Start: Minutes(start), End: Minutes(end)}
+}
+
+// Clock is the time-of-day value of m minutes since midnight, the form the time columns are written in
+func Clock(m int) time.Time {
+	return time.Date(0, 1, 1, m/60, m%60, 0, 0, time.UTC)
+}
+
+func Minutes(clock time.Time) int {
+	return clock.Hour()*60 + clock.Minute()
+}
+
+func (i Interval) Empty() bool {
+	return i.End <= i.Start
+}
+
+// Overlaps is true when the intervals share at least one minute, touching ends do not overlap
+func (i Interval) Overlaps(o Interval) bool {
+	return i.Start < o.End && o.Start < i.End
+}
+
+func (i Interval) Contains(o Interval) bool {
+	return i.Start <= o.Start && o.End <= i.End
+}
+
+func (i Interval) String() string {
+	return fmt.Sprintf("%s-%s", FormatMinutes(i.Start), FormatMinutes(i.End))
+}
+
+// FormatMinutes prints minutes since midnight as "15:04", 1440 is "24:00"
+func FormatMinutes(m int) string {
+	return fmt.Sprintf("%02d:%02d", m/60, m%60)
+}
+
+// Merge sorts the intervals and joins the ones that overlap or touch, the input is left alone
+func Merge(in []Interval) []Interval {
+	sorted := make([]Interval, 0, len(in))
+	for _, i := range in {
+		if !i.Empty() {
+			sorted = append(sorted, i)
+		}
+	}
+	sort.Slice(sorted, func(a, b int) bool { return sorted[a].Start < sorted[b].Start })
+
+	merged := make([]Interval, 0, len(sorted))
+	for _, i := range sorted {
+		if n := len(merged); n > 0 && i.Start <= merged[n-1].End {
+			if i.End > merged[n-1].End {
+				merged[n-1].End = i.End
+			}
+			continue
+		}
+		merged = append(merged, i)
+	}
+	return merged
+}
+
+// Subtract returns the parts of base not covered by any of busy
+func Subtract(base Interval, busy []Interval) []Interval {
+	free := make([]Interval, 0)
+	cur := base.Start
+	for _, b := range Merge(busy) {
+		if b.End <= cur {
+			continue
+		}
+		if b.Start >= base.End {
+			break
+		}
+		if b.Start > cur {
+			free = append(free, Interval{Start: cur, End: b.Start})
+		}
+		cur = b.End
+	}
+	if cur < base.End {
+		free = append(free, Interval{Start: cur, End: base.End})
+	}
+	return free
+}
+
+// Slots cuts base into steps of step minutes from its start, a last step that does not fit is dropped
+func Slots(base Interval, busy []Interval, step int) []Slot {
+	merged := Merge(busy)
+	slots := make([]Slot, 0)
+	for start := base.Start; start+step <= base.End; start += step {
+		s := Slot{Interval: Interval{Start: start, End: start + step}, Free: true}
+		for _, b := range merged {
+			if b.Overlaps(s.Interval) {
+				s.Free = false
+				break
+			}
+		}
+		slots = append(slots, s)
+	}
+	return slots
+}
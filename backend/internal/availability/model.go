@@ -0,0 +1,91 @@
+package availability
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kind is what keeps an interval of the facility busy
+type Kind string
+
+const (
+	KindBooking Kind = "booking"
+	KindSession Kind = "session"
+	KindClosure Kind = "closure"
+	KindHoliday Kind = "holiday"
+	KindPast    Kind = "past" // the part of today that is already over
+)
+
+// Busy is one interval of a day in which the facility cannot be booked
+type Busy struct {
+	Interval
+	Date   time.Time
+	Kind   Kind
+	RefID  uuid.UUID // booking_id, session_id, closure_id or holiday_id, nil for past
+	Reason string    // closures and holidays only
+}
+
+func (b Busy) String() string {
+	if b.Reason != "" {
+		return fmt.Sprintf("%s on %s %s (%s)", b.Kind, b.Date.Format("2006-01-02"), b.Interval, b.Reason)
+	}
+	return fmt.Sprintf("%s on %s %s", b.Kind, b.Date.Format("2006-01-02"), b.Interval)
+}
+
+// Claim is a slot someone wants to occupy on the facility
+type Claim struct {
+	FacilityID uuid.UUID
+	Date       time.Time
+	Interval
+	ScheduleID uuid.UUID // the sessions of this schedule are the claim itself, not conflicts
+}
+
+// WeeklyClaim is the same slot every week on WeekDay (0 is sunday) from From on
+type WeeklyClaim struct {
+	FacilityID uuid.UUID
+	WeekDay    int
+	Interval
+	From       time.Time
+	ScheduleID uuid.UUID
+}
+
+// Slot is one step of the slot grid, free when nothing busy overlaps it
+type Slot struct {
+	Interval
+	Free bool
+}
+
+// Day is the availability of the facility on one date. a closed day (inactive facility, no opening hours)
+// has no free intervals and no slots
+type Day struct {
+	Date   time.Time
+	Open   []Interval // the opening intervals of the date, seasonal hours included
+	Closed bool
+	Busy   []Busy
+	Free   []Interval
+	Slots  []Slot
+}
+
+type Availability struct {
+	FacilityID  uuid.UUID
+	SlotMinutes int
+	Days        []Day
+}
+
+// Config is the default slot granularity and the longest date range one request may ask for
+type Config struct {
+	SlotMinutes  int
+	MaxRangeDays int
+}
+
+// Describe lists the conflicts for an error message
+func Describe(conflicts []Busy) string {
+	parts := make([]string, 0, len(conflicts))
+	for _, c := range conflicts {
+		parts = append(parts, c.String())
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,281 @@
+package availability
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	pg "t/pkg/postgres"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Occupancy is the one facility occupancy check, spanning bookings and trainer sessions. bookings, schedules and the
+// session generator all take the facility lock and check inside their own transaction, so two of them cannot
+// claim the same slot at the same time
+type Occupancy interface {
+	// LockFacility serializes the claims on the facility until the end of tx
+	LockFacility(ctx context.Context, tx pgx.Tx, facilityID uuid.UUID) error
+	// Conflicts returns the non-canceled bookings and sessions, the closures and the holidays overlapping the claim
+	Conflicts(ctx context.Context, tx pgx.Tx, c Claim) ([]Busy, error)
+	// WeeklyConflicts returns the non-canceled bookings and sessions from c.From on that overlap the weekly slot
+	WeeklyConflicts(ctx context.Context, tx pgx.Tx, c WeeklyClaim) ([]Busy, error)
+}
+
+type AvailabilityRepository interface {
+	Occupancy
+	BeginTx(ctx context.Context) (pgx.Tx, error)
+
+	// ListClosures returns the closures of the facility that may be active between from and to
+	ListClosures(ctx context.Context, tx pgx.Tx, facilityID uuid.UUID, from, to time.Time) ([]Closure, error)
+	GetClosure(ctx context.Context, id uuid.UUID) (Closure, error)
+	CreateClosure(ctx context.Context, tx pgx.Tx, c Closure) error
+	DeleteClosure(ctx context.Context, id uuid.UUID) error
+
+	// ListHolidayDays returns one whole-day busy interval for every holiday date between from and to
+	ListHolidayDays(ctx context.Context, tx pgx.Tx, from, to time.Time) ([]Busy, error)
+
+	// ListBusy returns the non-canceled bookings and trainer sessions of the facility between from and to, both inclusive
+	ListBusy(ctx context.Context, facilityID uuid.UUID, from, to time.Time) ([]Busy, error)
+}
+
+type AvailabilityRepositoryPostgres struct {
+	pool *pgxpool.Pool
+}
+
+func NewAvailabilityRepositoryPostgres(pool *pgxpool.Pool) *AvailabilityRepositoryPostgres {
+	return &AvailabilityRepositoryPostgres{pool: pool}
+}
+
+func (r *AvailabilityRepositoryPostgres) BeginTx(ctx context.Context) (pgx.Tx, error) {
+	return r.pool.Begin(ctx)
+}
+
+// nil tx runs on the pool
+func (r *AvailabilityRepositoryPostgres) execRow(ctx context.Context, tx pgx.Tx, q string, args ...any) pgx.Row {
+	if tx != nil {
+		return tx.QueryRow(ctx, q, args...)
+	}
+	return r.pool.QueryRow(ctx, q, args...)
+}
+
+func (r *AvailabilityRepositoryPostgres) execRows(ctx context.Context, tx pgx.Tx, q string, args ...any) (pgx.Rows, error) {
+	if tx != nil {
+		return tx.Query(ctx, q, args...)
+	}
+	return r.pool.Query(ctx, q, args...)
+}
+
+func (r *AvailabilityRepositoryPostgres) ListBusy(ctx context.Context, facilityID uuid.UUID, from, to time.Time) ([]Busy, error) {
+	query := `SELECT 'booking', booking_id, date, start_time, end_time FROM bookings
+			  WHERE facility_id = $1 AND date BETWEEN $2 AND $3 AND is_canceled = FALSE
+			  UNION ALL
+			  SELECT 'session', session_id, date, start_time, end_time FROM trainer_sessions
+			  WHERE facility_id = $1 AND date BETWEEN $2 AND $3 AND is_canceled = FALSE
+			  ORDER BY 3, 4`
+
+	rows, err := r.pool.Query(ctx, query, facilityID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("ListBusy: Failed to query: %w", err)
+	}
+	return scanBusy("ListBusy", rows)
+}
+
+func (r *AvailabilityRepositoryPostgres) LockFacility(ctx context.Context, tx pgx.Tx, facilityID uuid.UUID) error {
+	return pg.AdvisoryXactLock(ctx, tx, facilityLockKey(facilityID))
+}
+
+func (r *AvailabilityRepositoryPostgres) Conflicts(ctx context.Context, tx pgx.Tx, c Claim) ([]Busy, error) {
+	query := `SELECT 'booking', booking_id, date, start_time, end_time FROM bookings
+			  WHERE facility_id = $1 AND date = $2 AND is_canceled = FALSE
+			    AND start_time < $4 AND end_time > $3
+			  UNION ALL
+			  SELECT 'session', session_id, date, start_time, end_time FROM trainer_sessions
+			  WHERE facility_id = $1 AND date = $2 AND is_canceled = FALSE
+			    AND start_time < $4 AND end_time > $3
+			    AND schedule_id IS DISTINCT FROM $5
+			  ORDER BY 3, 4`
+
+	rows, err := tx.Query(ctx, query, c.FacilityID, c.Date, Clock(c.Start), Clock(c.End), nullableID(c.ScheduleID))
+	if err != nil {
+		return nil, fmt.Errorf("Conflicts: Failed to query: %w", err)
+	}
+	conflicts, err := scanBusy("Conflicts", rows)
+	if err != nil {
+		return nil, err
+	}
+
+	closures, err := r.ListClosures(ctx, tx, c.FacilityID, c.Date, c.Date)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range closedOn(closures, dateOnly(c.Date)) {
+		if b.Overlaps(c.Interval) {
+			conflicts = append(conflicts, b)
+		}
+	}
+
+	holidays, err := r.ListHolidayDays(ctx, tx, c.Date, c.Date)
+	if err != nil {
+		return nil, err
+	}
+	return append(conflicts, holidays...), nil
+}
+
+func (r *AvailabilityRepositoryPostgres) ListHolidayDays(ctx context.Context, tx pgx.Tx, from, to time.Time) ([]Busy, error) {
+	query := `SELECT h.holiday_id, h.name, d::date
+			  FROM holidays h, generate_series(GREATEST(h.start_date, $1::date), LEAST(h.end_date, $2::date), INTERVAL '1 day') d
+			  WHERE h.start_date <= $2 AND h.end_date >= $1
+			  ORDER BY 3`
+
+	rows, err := r.execRows(ctx, tx, query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("ListHolidayDays: Failed to query: %w", err)
+	}
+	defer rows.Close()
+
+	busy := make([]Busy, 0)
+	for rows.Next() {
+		b := Busy{Interval: wholeDay, Kind: KindHoliday}
+		if err := rows.Scan(&b.RefID, &b.Reason, &b.Date); err != nil {
+			return nil, fmt.Errorf("ListHolidayDays: Failed to scan: %w", err)
+		}
+		busy = append(busy, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ListHolidayDays: %w", err)
+	}
+	return busy, nil
+}
+
+func (r *AvailabilityRepositoryPostgres) WeeklyConflicts(ctx context.Context, tx pgx.Tx, c WeeklyClaim) ([]Busy, error) {
+	query := `SELECT 'booking', booking_id, date, start_time, end_time FROM bookings
+			  WHERE facility_id = $1 AND date >= $2 AND EXTRACT(DOW FROM date) = $3 AND is_canceled = FALSE
+			    AND start_time < $5 AND end_time > $4
+			  UNION ALL
+			  SELECT 'session', session_id, date, start_time, end_time FROM trainer_sessions
+			  WHERE facility_id = $1 AND date >= $2 AND EXTRACT(DOW FROM date) = $3 AND is_canceled = FALSE
+			    AND start_time < $5 AND end_time > $4
+			    AND schedule_id IS DISTINCT FROM $6
+			  ORDER BY 3, 4`
+
+	rows, err := tx.Query(ctx, query, c.FacilityID, c.From, c.WeekDay%7, Clock(c.Start), Clock(c.End), nullableID(c.ScheduleID))
+	if err != nil {
+		return nil, fmt.Errorf("WeeklyConflicts: Failed to query: %w", err)
+	}
+	return scanBusy("WeeklyConflicts", rows)
+}
+
+func scanBusy(method string, rows pgx.Rows) ([]Busy, error) {
+	defer rows.Close()
+
+	busy := make([]Busy, 0)
+	for rows.Next() {
+		var b Busy
+		var start, end time.Time
+		if err := rows.Scan(&b.Kind, &b.RefID, &b.Date, &start, &end); err != nil {
+			return nil, fmt.Errorf("%s: Failed to scan: %w", method, err)
+		}
+		b.Interval = ClockInterval(start, end)
+		busy = append(busy, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", method, err)
+	}
+	return busy, nil
+}
+
+// facilityLockKey derives the advisory lock key of the facility from its id
+func facilityLockKey(id uuid.UUID) int64 {
+	return int64(binary.BigEndian.Uint64(id[:8]))
+}
+
+func nullableID(id uuid.UUID) *uuid.UUID {
+	if id == uuid.Nil {
+		return nil
+	}
+	return &id
+}
+
+const closureColumns = `closure_id, facility_id, reason, start_date, end_date, start_time, end_time, COALESCE(rrule, ''), created_by, created_at`
+
+func scanClosure(row pgx.Row) (Closure, error) {
+	var c Closure
+	var start, end *time.Time
+	if err := row.Scan(&c.ID, &c.FacilityID, &c.Reason, &c.StartDate, &c.EndDate, &start, &end, &c.Rule, &c.CreatedBy, &c.CreatedAt); err != nil {
+		return Closure{}, err
+	}
+	if start != nil && end != nil {
+		w := ClockInterval(*start, *end)
+		c.Window = &w
+	}
+	return c, nil
+}
+
+func (r *AvailabilityRepositoryPostgres) ListClosures(ctx context.Context, tx pgx.Tx, facilityID uuid.UUID, from, to time.Time) ([]Closure, error) {
+	query := `SELECT ` + closureColumns + ` FROM facility_closures
+			  WHERE facility_id = $1 AND start_date <= $3 AND (end_date IS NULL OR end_date >= $2)
+			  ORDER BY start_date`
+
+	rows, err := r.execRows(ctx, tx, query, facilityID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("ListClosures: Failed to query: %w", err)
+	}
+	defer rows.Close()
+
+	closures := make([]Closure, 0)
+	for rows.Next() {
+		c, err := scanClosure(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ListClosures: Failed to scan: %w", err)
+		}
+		closures = append(closures, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ListClosures: %w", err)
+	}
+	return closures, nil
+}
+
+func (r *AvailabilityRepositoryPostgres) GetClosure(ctx context.Context, id uuid.UUID) (Closure, error) {
+	query := `SELECT ` + closureColumns + ` FROM facility_closures WHERE closure_id = $1`
+
+	c, err := scanClosure(r.execRow(ctx, nil, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Closure{}, ErrClosureNotFound
+		}
+		return Closure{}, fmt.Errorf("GetClosure: Failed to scan: %w", err)
+	}
+	return c, nil
+}
+
+func (r *AvailabilityRepositoryPostgres) CreateClosure(ctx context.Context, tx pgx.Tx, c Closure) error {
+	query := `INSERT INTO facility_closures (closure_id, facility_id, reason, start_date, end_date, start_time, end_time, rrule, created_by)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9)`
+
+	var start, end *time.Time
+	if c.Window != nil {
+		s, e := Clock(c.Window.Start), Clock(c.Window.End)
+		start, end = &s, &e
+	}
+	if _, err := tx.Exec(ctx, query, c.ID, c.FacilityID, c.Reason, c.StartDate, c.EndDate, start, end, c.Rule, c.CreatedBy); err != nil {
+		return fmt.Errorf("CreateClosure: Failed to insert: %w", err)
+	}
+	return nil
+}
+
+func (r *AvailabilityRepositoryPostgres) DeleteClosure(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM facility_closures WHERE closure_id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("DeleteClosure: Failed to delete: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrClosureNotFound
+	}
+	return nil
+}
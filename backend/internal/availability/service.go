@@ -0,0 +1,211 @@
+package availability
+
+import (
+	"context"
+	"fmt"
+	"t/internal/facility"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AvailabilityService struct {
+	repo         AvailabilityRepository
+	facilityRepo facility.FacilityRepository
+	cfg          Config
+}
+
+func NewAvailabilityService(r AvailabilityRepository, facilityRepo facility.FacilityRepository, cfg Config) *AvailabilityService {
+	return &AvailabilityService{
+		repo:         r,
+		facilityRepo: facilityRepo,
+		cfg:          cfg,
+	}
+}
+
+// ForRange computes the free and busy intervals of the facility for every date between from and to, both inclusive.
+// slotMinutes is the granularity of the slot grid, 0 uses the configured default
+func (s *AvailabilityService) ForRange(ctx context.Context, facilityID uuid.UUID, from, to time.Time, slotMinutes int) (Availability, error) {
+	from, to = dateOnly(from), dateOnly(to)
+	if to.Before(from) {
+		return Availability{}, fmt.Errorf("%w: to is before from", ErrInvalidRange)
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; s.cfg.MaxRangeDays > 0 && days > s.cfg.MaxRangeDays {
+		return Availability{}, fmt.Errorf("%w: at most %d days", ErrInvalidRange, s.cfg.MaxRangeDays)
+	}
+	if slotMinutes == 0 {
+		slotMinutes = s.cfg.SlotMinutes
+	}
+	if slotMinutes < 5 || slotMinutes > 24*60 {
+		return Availability{}, fmt.Errorf("%w: must be between 5 and 1440 minutes", ErrInvalidSlot)
+	}
+
+	f, err := s.facilityRepo.GetFacility(ctx, facilityID)
+	if err != nil {
+		return Availability{}, err
+	}
+
+	busy, err := s.repo.ListBusy(ctx, facilityID, from, to)
+	if err != nil {
+		return Availability{}, err
+	}
+	byDate := make(map[time.Time][]Busy)
+	for _, b := range busy {
+		d := dateOnly(b.Date)
+		byDate[d] = append(byDate[d], b)
+	}
+	closures, err := s.repo.ListClosures(ctx, nil, facilityID, from, to)
+	if err != nil {
+		return Availability{}, err
+	}
+	holidays, err := s.repo.ListHolidayDays(ctx, nil, from, to)
+	if err != nil {
+		return Availability{}, err
+	}
+	for _, h := range holidays {
+		d := dateOnly(h.Date)
+		byDate[d] = append(byDate[d], h)
+	}
+
+	result := Availability{FacilityID: facilityID, SlotMinutes: slotMinutes, Days: make([]Day, 0)}
+	now := time.Now()
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		dayBusy := append(closedOn(closures, d), byDate[d]...)
+		result.Days = append(result.Days, computeDay(d, f, dayBusy, slotMinutes, now))
+	}
+	return result, nil
+}
+
+// computeDay merges the opening hours of the facility on that date and the busy intervals of one date. the elapsed
+// part of today and whole past days are busy too, a booking cannot start in the past
+func computeDay(date time.Time, f facility.Facility, busy []Busy, slotMinutes int, now time.Time) Day {
+	day := Day{
+		Date: date,
+		Open: make([]Interval, 0),
+		Busy: make([]Busy, 0, len(busy)+1),
+	}
+	for _, h := range f.Hours.On(date) {
+		if i := ClockInterval(h.Open, h.Close); !i.Empty() {
+			day.Open = append(day.Open, i)
+		}
+	}
+	if !f.IsActive || len(day.Open) == 0 {
+		day.Closed = true
+		day.Free = make([]Interval, 0)
+		day.Slots = make([]Slot, 0)
+		return day
+	}
+
+	today := dateOnly(now)
+	for _, open := range day.Open {
+		switch {
+		case date.Before(today):
+			day.Busy = append(day.Busy, Busy{Interval: open, Date: date, Kind: KindPast})
+		case date.Equal(today) && Minutes(now) > open.Start:
+			day.Busy = append(day.Busy, Busy{Interval: Interval{Start: open.Start, End: min(Minutes(now), open.End)}, Date: date, Kind: KindPast})
+		}
+	}
+	day.Busy = append(day.Busy, busy...)
+
+	intervals := make([]Interval, 0, len(day.Busy))
+	for _, b := range day.Busy {
+		intervals = append(intervals, b.Interval)
+	}
+	day.Free = make([]Interval, 0)
+	day.Slots = make([]Slot, 0)
+	for _, open := range day.Open {
+		day.Free = append(day.Free, Subtract(open, intervals)...)
+		day.Slots = append(day.Slots, Slots(open, intervals, slotMinutes)...)
+	}
+	return day
+}
+
+// farFuture bounds the search of open ended closures
+var farFuture = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+// dateOnly keeps the calendar date, in the same UTC form the dates come back from postgres
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// CreateClosure stores the closure and returns the bookings and sessions it overlaps from today on.
+// the facility is locked while the closure is written, so the list is complete: whatever is booked later sees the closure
+func (s *AvailabilityService) CreateClosure(ctx context.Context, c Closure) (Closure, []Busy, error) {
+	if err := c.Validate(); err != nil {
+		return Closure{}, nil, err
+	}
+	if _, err := s.facilityRepo.GetFacility(ctx, c.FacilityID); err != nil {
+		return Closure{}, nil, err
+	}
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return Closure{}, nil, fmt.Errorf("CreateClosure: Failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := s.repo.LockFacility(ctx, tx, c.FacilityID); err != nil {
+		return Closure{}, nil, err
+	}
+	if err := s.repo.CreateClosure(ctx, tx, c); err != nil {
+		return Closure{}, nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return Closure{}, nil, fmt.Errorf("CreateClosure: Failed to commit: %w", err)
+	}
+
+	affected, err := s.Affected(ctx, c)
+	if err != nil {
+		return c, nil, err
+	}
+	return c, affected, nil
+}
+
+// Affected returns the bookings and sessions the closure overlaps that have not started yet
+func (s *AvailabilityService) Affected(ctx context.Context, c Closure) ([]Busy, error) {
+	now := time.Now()
+	from := dateOnly(now)
+	if c.StartDate.After(from) {
+		from = dateOnly(c.StartDate)
+	}
+	to := farFuture
+	if c.EndDate != nil {
+		to = dateOnly(*c.EndDate)
+	}
+	if to.Before(from) {
+		return []Busy{}, nil
+	}
+
+	busy, err := s.repo.ListBusy(ctx, c.FacilityID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	affected := make([]Busy, 0)
+	for _, b := range busy {
+		closed, ok := c.On(b.Date)
+		if !ok || !closed.Overlaps(b.Interval) {
+			continue
+		}
+		if b.Date.Equal(dateOnly(now)) && b.Start <= Minutes(now) {
+			continue
+		}
+		affected = append(affected, b)
+	}
+	return affected, nil
+}
+
+func (s *AvailabilityService) ListClosures(ctx context.Context, facilityID uuid.UUID, from, to time.Time) ([]Closure, error) {
+	return s.repo.ListClosures(ctx, nil, facilityID, dateOnly(from), dateOnly(to))
+}
+
+func (s *AvailabilityService) GetClosure(ctx context.Context, id uuid.UUID) (Closure, error) {
+	return s.repo.GetClosure(ctx, id)
+}
+
+// DeleteClosure reopens the facility, whatever was canceled because of the closure stays canceled
+func (s *AvailabilityService) DeleteClosure(ctx context.Context, id uuid.UUID) error {
+	return s.repo.DeleteClosure(ctx, id)
+}
@@ -0,0 +1,38 @@
+package booking
+
+import (
+	"errors"
+	"t/internal/availability"
+	"t/internal/facility"
+)
+
+var (
+	ErrBookingNotFound        = errors.New("booking not found")
+	ErrBookingAlreadyCanceled = errors.New("booking already canceled")
+	ErrSeriesNotFound         = errors.New("booking series not found")
+	ErrSeriesAlreadyCanceled  = errors.New("booking series already canceled")
+)
+
+// validation errors, the booking request itself is wrong
+var (
+	ErrFacilityNotFound    = facility.ErrFacilityNotFound
+	ErrFacilityInactive    = errors.New("facility is not active")
+	ErrInvalidInterval     = errors.New("end_time must be after start_time")
+	ErrBookingInPast       = errors.New("booking cannot start in the past")
+	ErrOutsideOpeningHours = errors.New("booking is outside of the facility opening hours")
+	ErrSlotTooShort        = errors.New("booking is shorter than the minimum slot length")
+	ErrSlotTooLong         = errors.New("booking is longer than the maximum slot length")
+	ErrInvalidSeries       = errors.New("invalid booking series")
+)
+
+// conflict errors, the request is fine but clashes with the current state.
+// the policy ones are returned wrapped together with the *policy.Violation that explains the rule
+var (
+	ErrNotEnoughPoints      = errors.New("user does not have enough points")
+	ErrAlreadyBookedThatDay = errors.New("user reached the bookings limit for this facility on this day")
+	ErrUserOverlap          = errors.New("user has another booking during this time")
+	ErrFacilityOverlap      = errors.New("facility is already booked for this interval")
+	ErrFacilityClosed       = availability.ErrFacilityClosed
+	ErrHoliday              = availability.ErrHoliday
+	ErrTooManyBookings      = errors.New("user has too many upcoming bookings")
+)
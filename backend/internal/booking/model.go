@@ -17,6 +17,58 @@ type Booking struct {
 	Note       string
 	IsCanceled bool
 	AdminNote  string
+	SeriesID   *uuid.UUID // set for the occurrences of a recurring booking
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
 }
+
+// Series is a recurring booking, Rule is the RRULE subset understood by pkg/rrule.
+// the occurrences are stored as ordinary bookings with SeriesID set
+type Series struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	FacilityID uuid.UUID
+	Rule       string
+	StartDate  time.Time // first possible occurrence
+	Until      time.Time // last possible occurrence, moved back when the rest of the series is canceled
+	StartTime  time.Time // time-only
+	EndTime    time.Time // time-only
+	Note       string
+	IsCanceled bool
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+type OccurrenceStatus string
+
+const (
+	OccurrenceBooked   OccurrenceStatus = "booked"
+	OccurrenceConflict OccurrenceStatus = "conflict" // Err tells which rule rejected it
+	OccurrenceCanceled OccurrenceStatus = "canceled"
+)
+
+// Occurrence is one date of a series, with the booking made for it if any
+type Occurrence struct {
+	Date      time.Time
+	BookingID *uuid.UUID
+	Status    OccurrenceStatus
+	Err       error
+}
+
+// SeriesReport is the per-occurrence result of creating a series
+type SeriesReport struct {
+	Series      Series
+	Created     bool // false when there were conflicts and the caller did not ask to skip them
+	Booked      int
+	Conflicts   int
+	Occurrences []Occurrence
+}
+
+// SeriesCancellation is what canceling the rest of a series did. occurrences inside the cancel cutoff of the owner
+// are kept, they have to be canceled one by one under the cancel policy
+type SeriesCancellation struct {
+	SeriesID uuid.UUID
+	From     time.Time
+	Canceled []Booking
+	Kept     []Booking
+}
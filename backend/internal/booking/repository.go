@@ -2,6 +2,7 @@ package booking
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -11,18 +12,26 @@ import (
 )
 
 type BookingRepository interface {
-	UserHasBooking(ctx context.Context, tx pgx.Tx, userID uuid.UUID, facilID uuid.UUID, date time.Time) (bool, error)                  //checks user has booking in that facility in that day
-	UserHasOverlap(ctx context.Context, tx pgx.Tx, userID uuid.UUID, start time.Time, end time.Time, date time.Time) (bool, error)     //checks user has overalp of bookings with other facilities
-	BookingHasOverlap(ctx context.Context, tx pgx.Tx, facilID uuid.UUID, start time.Time, end time.Time, date time.Time) (bool, error) //checks if the booking on that facility has not  overalp on that time interval
-	HasTooManyBookings(ctx context.Context, tx pgx.Tx, userID uuid.UUID) (bool, error)
-	UserHasEnoughPoints(ctx context.Context, tx pgx.Tx, userID uuid.UUID) (bool, error)
+	CountUserBookingsOnDay(ctx context.Context, tx pgx.Tx, userID uuid.UUID, facilID uuid.UUID, date time.Time) (int, error)       //how many bookings user has in that facility in that day
+	UserHasOverlap(ctx context.Context, tx pgx.Tx, userID uuid.UUID, start time.Time, end time.Time, date time.Time) (bool, error) //checks user has overalp of bookings with other facilities
+	CountUpcomingBookings(ctx context.Context, tx pgx.Tx, userID uuid.UUID) (int, error)
+	GetUserStanding(ctx context.Context, tx pgx.Tx, userID uuid.UUID) (role string, creditScore int, err error)
 	CreateBooking(ctx context.Context, tx pgx.Tx, data Booking) error
 	ListBookigsForFacility(ctx context.Context, tx pgx.Tx, facilID uuid.UUID, date time.Time) ([]Booking, error)
 	ListBookingsForUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID, offset int) ([]Booking, error)
 
+	GetBooking(ctx context.Context, tx pgx.Tx, bookingID uuid.UUID) (Booking, error)
 	CancelBooking(ctx context.Context, tx pgx.Tx, bookingID uuid.UUID, adminNote string) error
 	ListBookings(ctx context.Context, tx pgx.Tx, start_date time.Time, end_date time.Time, offset int) ([]Booking, error)
 	BeginTx(context.Context) (pgx.Tx, error)
+
+	CreateSeries(ctx context.Context, tx pgx.Tx, data Series) error
+	GetSeries(ctx context.Context, tx pgx.Tx, seriesID uuid.UUID) (Series, error)
+	ListSeriesBookings(ctx context.Context, tx pgx.Tx, seriesID uuid.UUID) ([]Booking, error)
+	// CancelSeriesBookings cancels the active occurrences on or after from that start more than cutoffSecs from now, returns them
+	CancelSeriesBookings(ctx context.Context, tx pgx.Tx, seriesID uuid.UUID, from time.Time, cutoffSecs float64) ([]Booking, error)
+	// EndSeries moves the end of the series back to until, a series ending before its start is canceled
+	EndSeries(ctx context.Context, tx pgx.Tx, seriesID uuid.UUID, until time.Time) error
 }
 
 type BookingRepositoryPostgres struct {
@@ -69,16 +78,16 @@ func (r *BookingRepositoryPostgres) exec(ctx context.Context, tx pgx.Tx, q strin
 	return err
 }
 
-func (r *BookingRepositoryPostgres) UserHasBooking(ctx context.Context, tx pgx.Tx, userID uuid.UUID, facilID uuid.UUID, date time.Time) (bool, error) {
+func (r *BookingRepositoryPostgres) CountUserBookingsOnDay(ctx context.Context, tx pgx.Tx, userID uuid.UUID, facilID uuid.UUID, date time.Time) (int, error) {
 	query := `SELECT COUNT(*) FROM bookings WHERE user_id=$1 and facility_id = $2 and date = $3 and is_canceled = FALSE`
 	var count int
 
 	err := r.execRow(ctx, tx, query, userID, facilID, date).Scan(&count)
 	if err != nil {
-		return false, fmt.Errorf("UserHasBooking query error: %w", err)
+		return 0, fmt.Errorf("CountUserBookingsOnDay query error: %w", err)
 	}
 
-	return count > 0, nil
+	return count, nil
 }
 
 func (r *BookingRepositoryPostgres) UserHasOverlap(ctx context.Context, tx pgx.Tx, userID uuid.UUID, start time.Time, end time.Time, date time.Time) (bool, error) {
@@ -100,26 +109,7 @@ func (r *BookingRepositoryPostgres) UserHasOverlap(ctx context.Context, tx pgx.T
 	return count > 0, nil
 }
 
-func (r *BookingRepositoryPostgres) BookingHasOverlap(ctx context.Context, tx pgx.Tx, facilID uuid.UUID, start time.Time, end time.Time, date time.Time) (bool, error) {
-	query := `
-        SELECT COUNT(*)
-        FROM bookings
-        WHERE facility_id = $1
-          AND date = $2
-          AND is_canceled = FALSE
-          AND NOT (end_time <= $3 OR start_time >= $4)
-    `
-
-	var count int
-	err := r.execRow(ctx, tx, query, facilID, date, start, end).Scan(&count)
-	if err != nil {
-		return false, fmt.Errorf("BookingHasOverlap query error: %w", err)
-	}
-
-	return count > 0, nil
-}
-
-func (r *BookingRepositoryPostgres) HasTooManyBookings(ctx context.Context, tx pgx.Tx, userID uuid.UUID) (bool, error) {
+func (r *BookingRepositoryPostgres) CountUpcomingBookings(ctx context.Context, tx pgx.Tx, userID uuid.UUID) (int, error) {
 	// Count bookings that are in the future OR today but haven't ended yet
 	query := `
 		SELECT COUNT(*) 
@@ -135,25 +125,28 @@ func (r *BookingRepositoryPostgres) HasTooManyBookings(ctx context.Context, tx p
 	var count int
 	err := r.execRow(ctx, tx, query, userID).Scan(&count)
 	if err != nil {
-		return false, fmt.Errorf("HasTooManyBookings query error: %w", err)
+		return 0, fmt.Errorf("CountUpcomingBookings query error: %w", err)
 	}
-	return count >= 3, nil
+	return count, nil
 }
 
-func (r *BookingRepositoryPostgres) UserHasEnoughPoints(ctx context.Context, tx pgx.Tx, userID uuid.UUID) (bool, error) {
-	query := `SELECT credit_score FROM users WHERE user_id = $1`
+// GetUserStanding returns what the booking policies are evaluated against
+func (r *BookingRepositoryPostgres) GetUserStanding(ctx context.Context, tx pgx.Tx, userID uuid.UUID) (string, int, error) {
+	query := `SELECT role, credit_score FROM users WHERE user_id = $1`
 
-	var count int
-	err := r.execRow(ctx, tx, query, userID).Scan(&count)
+	var role string
+	var score int
+	err := r.execRow(ctx, tx, query, userID).Scan(&role, &score)
 	if err != nil {
-		return false, fmt.Errorf("UserHasEnoughPoints query error: %w", err)
+		return "", 0, fmt.Errorf("GetUserStanding query error: %w", err)
 	}
-	return count >= 50, nil
+	return role, score, nil
 }
 
 func (r *BookingRepositoryPostgres) CreateBooking(ctx context.Context, tx pgx.Tx, data Booking) error {
 	query := `
         INSERT INTO bookings (
+            booking_id,
             user_id,
             facility_id,
             date,
@@ -162,12 +155,14 @@ func (r *BookingRepositoryPostgres) CreateBooking(ctx context.Context, tx pgx.Tx
             note,
             is_canceled,
             admin_note,
+            series_id,
             created_at,
             updated_at
-        ) VALUES ($1,$2,$3,$4,$5,$6,$7,$8, NOW(), NOW());
+        ) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10, NOW(), NOW());
     `
 
 	err := r.exec(ctx, tx, query,
+		data.ID,
 		data.UserID,
 		data.FacilityID,
 		data.Date,
@@ -176,6 +171,7 @@ func (r *BookingRepositoryPostgres) CreateBooking(ctx context.Context, tx pgx.Tx
 		data.Note,
 		data.IsCanceled,
 		data.AdminNote,
+		data.SeriesID,
 	)
 
 	if err != nil {
@@ -294,3 +290,100 @@ func (r *BookingRepositoryPostgres) CancelBooking(ctx context.Context, tx pgx.Tx
 	query := `UPDATE bookings SET is_canceled=TRUE, admin_note=$1 WHERE booking_id=$2 `
 	return r.exec(ctx, tx, query, adminNote, bookingID)
 }
+
+func (r *BookingRepositoryPostgres) GetBooking(ctx context.Context, tx pgx.Tx, bookingID uuid.UUID) (Booking, error) {
+	query := `SELECT booking_id, facility_id, user_id, date, start_time, end_time, COALESCE(note, ''), is_canceled, COALESCE(admin_note, ''), series_id, created_at, updated_at
+			  FROM bookings WHERE booking_id = $1`
+
+	var b Booking
+	err := r.execRow(ctx, tx, query, bookingID).Scan(
+		&b.ID,
+		&b.FacilityID,
+		&b.UserID,
+		&b.Date,
+		&b.StartTime,
+		&b.EndTime,
+		&b.Note,
+		&b.IsCanceled,
+		&b.AdminNote,
+		&b.SeriesID,
+		&b.CreatedAt,
+		&b.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Booking{}, ErrBookingNotFound
+		}
+		return Booking{}, fmt.Errorf("repository.GetBooking: %w", err)
+	}
+	return b, nil
+}
+
+func (r *BookingRepositoryPostgres) CreateSeries(ctx context.Context, tx pgx.Tx, data Series) error {
+	query := `INSERT INTO booking_series (series_id, user_id, facility_id, rrule, start_date, until, start_time, end_time, note)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+
+	err := r.exec(ctx, tx, query, data.ID, data.UserID, data.FacilityID, data.Rule, data.StartDate, data.Until, data.StartTime, data.EndTime, data.Note)
+	if err != nil {
+		return fmt.Errorf("CreateSeries: insert failed: %w", err)
+	}
+	return nil
+}
+
+func (r *BookingRepositoryPostgres) GetSeries(ctx context.Context, tx pgx.Tx, seriesID uuid.UUID) (Series, error) {
+	query := `SELECT series_id, user_id, facility_id, rrule, start_date, until, start_time, end_time, COALESCE(note, ''), is_canceled, created_at, updated_at
+			  FROM booking_series WHERE series_id = $1`
+
+	var s Series
+	err := r.execRow(ctx, tx, query, seriesID).Scan(&s.ID, &s.UserID, &s.FacilityID, &s.Rule, &s.StartDate, &s.Until,
+		&s.StartTime, &s.EndTime, &s.Note, &s.IsCanceled, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Series{}, ErrSeriesNotFound
+		}
+		return Series{}, fmt.Errorf("repository.GetSeries: %w", err)
+	}
+	return s, nil
+}
+
+func (r *BookingRepositoryPostgres) ListSeriesBookings(ctx context.Context, tx pgx.Tx, seriesID uuid.UUID) ([]Booking, error) {
+	query := `SELECT booking_id, facility_id, user_id, date, start_time, end_time, COALESCE(note, ''), is_canceled, COALESCE(admin_note, ''), series_id, created_at, updated_at
+			  FROM bookings WHERE series_id = $1 ORDER BY date`
+
+	return r.scanSeriesBookings(ctx, tx, "ListSeriesBookings", query, seriesID)
+}
+
+func (r *BookingRepositoryPostgres) CancelSeriesBookings(ctx context.Context, tx pgx.Tx, seriesID uuid.UUID, from time.Time, cutoffSecs float64) ([]Booking, error) {
+	query := `UPDATE bookings SET is_canceled = TRUE, updated_at = NOW()
+			  WHERE series_id = $1 AND is_canceled = FALSE AND date >= $2
+			    AND date + start_time > LOCALTIMESTAMP + make_interval(secs => $3)
+			  RETURNING booking_id, facility_id, user_id, date, start_time, end_time, COALESCE(note, ''), is_canceled, COALESCE(admin_note, ''), series_id, created_at, updated_at`
+
+	return r.scanSeriesBookings(ctx, tx, "CancelSeriesBookings", query, seriesID, from, cutoffSecs)
+}
+
+func (r *BookingRepositoryPostgres) scanSeriesBookings(ctx context.Context, tx pgx.Tx, method, query string, args ...any) ([]Booking, error) {
+	rows, err := r.execRows(ctx, tx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", method, err)
+	}
+	defer rows.Close()
+
+	bookings := make([]Booking, 0)
+	for rows.Next() {
+		var b Booking
+		err := rows.Scan(&b.ID, &b.FacilityID, &b.UserID, &b.Date, &b.StartTime, &b.EndTime, &b.Note, &b.IsCanceled, &b.AdminNote, &b.SeriesID, &b.CreatedAt, &b.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", method, err)
+		}
+		bookings = append(bookings, b)
+	}
+	return bookings, rows.Err()
+}
+
+func (r *BookingRepositoryPostgres) EndSeries(ctx context.Context, tx pgx.Tx, seriesID uuid.UUID, until time.Time) error {
+	query := `UPDATE booking_series
+			  SET until = LEAST(until, GREATEST($2::date, start_date)), is_canceled = $2::date < start_date, updated_at = NOW()
+			  WHERE series_id = $1`
+	return r.exec(ctx, tx, query, seriesID, until)
+}
@@ -0,0 +1,221 @@
+package booking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"t/internal/notification"
+	"t/pkg/rrule"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxSeriesOccurrences caps one series, two slots a week for a whole year fit
+const MaxSeriesOccurrences = 110
+
+// occurrence errors that only reject one date, anything else aborts the whole series
+var occurrenceConflicts = []error{
+	ErrBookingInPast,
+	ErrOutsideOpeningHours,
+	ErrSlotTooShort,
+	ErrSlotTooLong,
+	ErrNotEnoughPoints,
+	ErrAlreadyBookedThatDay,
+	ErrUserOverlap,
+	ErrFacilityOverlap,
+	ErrFacilityClosed,
+	ErrHoliday,
+}
+
+func isOccurrenceConflict(err error) bool {
+	for _, c := range occurrenceConflicts {
+		if errors.Is(err, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateSeries expands the rule between data.StartDate and data.Until and checks every occurrence with the rules of a
+// single booking (except the upcoming bookings cap, series are for staff and clubs). without skipConflicts any conflict books nothing, with it the free
+// occurrences are booked and the others reported. the report lists every occurrence either way
+func (s *BookingService) CreateSeries(ctx context.Context, data Series, skipConflicts bool) (SeriesReport, error) {
+	report := SeriesReport{Series: data, Occurrences: make([]Occurrence, 0)}
+
+	rule, err := rrule.Parse(data.Rule)
+	if err != nil {
+		return report, fmt.Errorf("%w: %w", ErrInvalidSeries, err)
+	}
+	if data.Until.Before(data.StartDate) {
+		return report, fmt.Errorf("%w: until is before start_date", ErrInvalidSeries)
+	}
+	dates, err := rule.Dates(data.StartDate, data.Until, MaxSeriesOccurrences)
+	if err != nil {
+		return report, fmt.Errorf("%w: %w", ErrInvalidSeries, err)
+	}
+	if len(dates) == 0 {
+		return report, fmt.Errorf("%w: the rule has no occurrence between start_date and until", ErrInvalidSeries)
+	}
+	if !data.EndTime.After(data.StartTime) {
+		return report, ErrInvalidInterval
+	}
+	f, err := s.facilityRepo.GetFacility(ctx, data.FacilityID)
+	if err != nil {
+		if errors.Is(err, ErrFacilityNotFound) {
+			return report, ErrFacilityNotFound
+		}
+		return report, fmt.Errorf("failed to load facility: %w", err)
+	}
+	if !f.IsActive {
+		return report, ErrFacilityInactive
+	}
+
+	if data.ID == uuid.Nil {
+		data.ID = uuid.New()
+	}
+	report.Series = data
+
+	tx, err := s.bookingRepo.BeginTx(ctx)
+	if err != nil {
+		return report, fmt.Errorf("CreateSeries: Failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := s.bookingRepo.CreateSeries(ctx, tx, data); err != nil {
+		return report, err
+	}
+
+	bookings := make([]Booking, 0, len(dates))
+	for _, date := range dates {
+		b := Booking{
+			ID:         uuid.New(),
+			UserID:     data.UserID,
+			FacilityID: data.FacilityID,
+			Date:       date,
+			StartTime:  data.StartTime,
+			EndTime:    data.EndTime,
+			Note:       data.Note,
+			SeriesID:   &data.ID,
+		}
+
+		err := s.validateAgainstFacility(ctx, b)
+		if err == nil {
+			err = s.checkSlot(ctx, tx, b)
+		}
+		if err == nil {
+			//booked right away, so the daily limit and the overlaps of the next occurrences see it
+			err = s.bookingRepo.CreateBooking(ctx, tx, b)
+			if err != nil {
+				return report, fmt.Errorf("failed to create booking: %w", err)
+			}
+		}
+
+		switch {
+		case err == nil:
+			report.Occurrences = append(report.Occurrences, Occurrence{Date: date, BookingID: &b.ID, Status: OccurrenceBooked})
+			report.Booked++
+			bookings = append(bookings, b)
+		case isOccurrenceConflict(err):
+			report.Occurrences = append(report.Occurrences, Occurrence{Date: date, Status: OccurrenceConflict, Err: err})
+			report.Conflicts++
+		default:
+			return report, err
+		}
+	}
+
+	if report.Booked == 0 || (report.Conflicts > 0 && !skipConflicts) {
+		//nothing is kept, the report tells the caller why
+		for i := range report.Occurrences {
+			report.Occurrences[i].BookingID = nil
+		}
+		report.Booked = 0
+		return report, nil
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return report, fmt.Errorf("CreateSeries: Failed to commit: %w", err)
+	}
+	report.Created = true
+
+	s.audit.Record(ctx, "booking_series.create", "booking_series", data.ID, nil, data)
+	for _, b := range bookings {
+		s.audit.Record(ctx, "booking.create", "booking", b.ID, nil, b)
+	}
+	return report, nil
+}
+
+// GetSeries returns the series with all of its occurrences, canceled ones included
+func (s *BookingService) GetSeries(ctx context.Context, seriesID uuid.UUID) (Series, []Booking, error) {
+	series, err := s.bookingRepo.GetSeries(ctx, nil, seriesID)
+	if err != nil {
+		return Series{}, nil, err
+	}
+	bookings, err := s.bookingRepo.ListSeriesBookings(ctx, nil, seriesID)
+	if err != nil {
+		return Series{}, nil, err
+	}
+	return series, bookings, nil
+}
+
+// CancelSeries cancels the occurrences of the series on or after from and ends the series there.
+// the owner cannot cancel occurrences inside the cancel cutoff this way: they are kept and reported,
+// so the cancel policy is applied to them one by one through CancelBooking
+func (s *BookingService) CancelSeries(ctx context.Context, seriesID, actorID uuid.UUID, from time.Time) (SeriesCancellation, error) {
+	result := SeriesCancellation{SeriesID: seriesID, From: from, Canceled: make([]Booking, 0), Kept: make([]Booking, 0)}
+
+	tx, err := s.bookingRepo.BeginTx(ctx)
+	if err != nil {
+		return result, fmt.Errorf("CancelSeries: Failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	series, err := s.bookingRepo.GetSeries(ctx, tx, seriesID)
+	if err != nil {
+		return result, err
+	}
+	if series.IsCanceled {
+		return result, ErrSeriesAlreadyCanceled
+	}
+
+	var cutoff time.Duration
+	if actorID == series.UserID {
+		cutoff = s.cancelPolicy.Cutoff
+	}
+	result.Canceled, err = s.bookingRepo.CancelSeriesBookings(ctx, tx, seriesID, from, cutoff.Seconds())
+	if err != nil {
+		return result, err
+	}
+
+	if err := s.bookingRepo.EndSeries(ctx, tx, seriesID, from.AddDate(0, 0, -1)); err != nil {
+		return result, fmt.Errorf("CancelSeries: %w", err)
+	}
+
+	remaining, err := s.bookingRepo.ListSeriesBookings(ctx, tx, seriesID)
+	if err != nil {
+		return result, err
+	}
+	now := time.Now()
+	for _, b := range remaining {
+		if !b.IsCanceled && !b.Date.Before(from) && combine(b.Date, b.StartTime).After(now) {
+			result.Kept = append(result.Kept, b)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return result, fmt.Errorf("CancelSeries: Failed to commit: %w", err)
+	}
+
+	s.audit.Record(ctx, "booking_series.cancel", "booking_series", seriesID, series, result)
+	if actorID != series.UserID && len(result.Canceled) > 0 {
+		s.notifier.Notify(ctx, notification.Notification{
+			UserID:     series.UserID,
+			Type:       notification.TypeBookingCanceled,
+			Title:      "Your recurring booking was canceled",
+			Body:       fmt.Sprintf("%d occurrences of your recurring booking from %s on were canceled.", len(result.Canceled), from.Format("2006-01-02")),
+			EntityType: "booking_series",
+			EntityID:   &seriesID,
+		})
+	}
+	return result, nil
+}
@@ -2,68 +2,147 @@ package booking
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"t/internal/audit"
+	"t/internal/availability"
+	"t/internal/facility"
+	"t/internal/notification"
+	"t/internal/penalty"
+	"t/internal/policy"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 type BookingService struct {
-	bookingRepo BookingRepository
+	bookingRepo  BookingRepository
+	facilityRepo facility.FacilityRepository
+	policyRepo   policy.PolicyRepository
+	penaltyRepo  penalty.PenaltyRepository
+	occupancy    availability.Occupancy
+	cancelPolicy penalty.CancelPolicy
+	audit        audit.Recorder
+	notifier     notification.Notifier
 }
 
-func NewBookingService(bookingRep BookingRepository) *BookingService {
+func NewBookingService(bookingRep BookingRepository, facilityRep facility.FacilityRepository, policyRep policy.PolicyRepository, penaltyRep penalty.PenaltyRepository, occupancy availability.Occupancy, cancelPolicy penalty.CancelPolicy, auditRec audit.Recorder, notifier notification.Notifier) *BookingService {
 	return &BookingService{
-		bookingRepo: bookingRep,
+		bookingRepo:  bookingRep,
+		facilityRepo: facilityRep,
+		policyRepo:   policyRep,
+		penaltyRepo:  penaltyRep,
+		occupancy:    occupancy,
+		cancelPolicy: cancelPolicy,
+		audit:        auditRec,
+		notifier:     notifier,
 	}
 }
 
-func (s *BookingService) CreateNewBooking(ctx context.Context, data Booking) error {
-	// 1. Begin transaction
-	tx, err := s.bookingRepo.BeginTx(ctx)
+// validateAgainstFacility checks the booking against the facility record: active flag, opening hours and date
+func (s *BookingService) validateAgainstFacility(ctx context.Context, data Booking) error {
+	if !data.EndTime.After(data.StartTime) {
+		return ErrInvalidInterval
+	}
+
+	f, err := s.facilityRepo.GetFacility(ctx, data.FacilityID)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		if errors.Is(err, ErrFacilityNotFound) {
+			return ErrFacilityNotFound
+		}
+		return fmt.Errorf("failed to load facility: %w", err)
+	}
+
+	if !f.IsActive {
+		return ErrFacilityInactive
+	}
+
+	if combine(data.Date, data.StartTime).Before(time.Now()) {
+		return ErrBookingInPast
 	}
-	defer tx.Rollback(ctx)
 
-	hasEnoughPoints, err := s.bookingRepo.UserHasEnoughPoints(ctx, tx, data.UserID)
+	//same interval logic as the availability endpoint, so a free slot there is bookable here.
+	//the slot has to fit in one opening interval of the day, the seasonal hours win over the regular week
+	hours := f.Hours.On(data.Date)
+	slot := availability.ClockInterval(data.StartTime, data.EndTime)
+	for _, h := range hours {
+		if availability.ClockInterval(h.Open, h.Close).Contains(slot) {
+			return nil
+		}
+	}
+	if len(hours) == 0 {
+		return fmt.Errorf("%w: closed on %s", ErrOutsideOpeningHours, data.Date.Format("Monday 2006-01-02"))
+	}
+	return fmt.Errorf("%w: open %s on %s", ErrOutsideOpeningHours, facility.FormatHours(hours), data.Date.Format("Monday 2006-01-02"))
+}
+
+// checkPolicies evaluates the booking policies that apply to the facility and the user role.
+// every rejection wraps the sentinel error and the *policy.Violation explaining which rule fired
+// the upcoming bookings cap is skipped for the occurrences of a series, which only staff and admins can create
+func (s *BookingService) checkPolicies(ctx context.Context, tx pgx.Tx, data Booking) error {
+	role, score, err := s.bookingRepo.GetUserStanding(ctx, tx, data.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to load user: %w", err)
+	}
+
+	policies, err := s.policyRepo.ListApplicable(ctx, tx, data.FacilityID, role)
 	if err != nil {
-		return fmt.Errorf("failed to check user points: %w", err)
+		return fmt.Errorf("failed to load booking policies: %w", err)
 	}
-	if !hasEnoughPoints {
-		return fmt.Errorf("user does not have enough points")
+	eff := policy.Resolve(policies)
+
+	length := int(data.EndTime.Sub(data.StartTime).Minutes())
+	if err := eff.CheckMin(policy.RuleMinSlotMinutes, length); err != nil {
+		return fmt.Errorf("%w: %w", ErrSlotTooShort, err)
+	}
+	if err := eff.CheckMax(policy.RuleMaxSlotMinutes, length); err != nil {
+		return fmt.Errorf("%w: %w", ErrSlotTooLong, err)
 	}
 
-	hasBooking, err := s.bookingRepo.UserHasBooking(ctx, tx, data.UserID, data.FacilityID, data.Date)
+	if err := eff.CheckMin(policy.RuleMinCreditScore, score); err != nil {
+		return fmt.Errorf("%w: %w", ErrNotEnoughPoints, err)
+	}
+
+	onDay, err := s.bookingRepo.CountUserBookingsOnDay(ctx, tx, data.UserID, data.FacilityID, data.Date)
 	if err != nil {
 		return fmt.Errorf("failed to check user daily booking: %w", err)
 	}
-	if hasBooking {
-		return fmt.Errorf("user already booked this facility on this day")
+	if err := eff.CheckMax(policy.RuleMaxBookingsPerDay, onDay+1); err != nil {
+		return fmt.Errorf("%w: %w", ErrAlreadyBookedThatDay, err)
 	}
 
-	hasUserOverlap, err := s.bookingRepo.UserHasOverlap(ctx, tx, data.UserID, data.StartTime, data.EndTime, data.Date)
+	if data.SeriesID != nil {
+		return nil
+	}
+	upcoming, err := s.bookingRepo.CountUpcomingBookings(ctx, tx, data.UserID)
 	if err != nil {
-		return fmt.Errorf("failed to check user overlap: %w", err)
+		return fmt.Errorf("failed to check too many bookings: %w", err)
 	}
-	if hasUserOverlap {
-		return fmt.Errorf("user has another booking during this time")
+	if err := eff.CheckMax(policy.RuleMaxUpcomingBookings, upcoming+1); err != nil {
+		return fmt.Errorf("%w: %w", ErrTooManyBookings, err)
 	}
 
-	hasFacilityOverlap, err := s.bookingRepo.BookingHasOverlap(ctx, tx, data.FacilityID, data.StartTime, data.EndTime, data.Date)
-	if err != nil {
-		return fmt.Errorf("failed to check facility overlap: %w", err)
+	return nil
+}
+
+func (s *BookingService) CreateNewBooking(ctx context.Context, data Booking) error {
+	if err := s.validateAgainstFacility(ctx, data); err != nil {
+		return err
 	}
-	if hasFacilityOverlap {
-		return fmt.Errorf("facility is already booked for this interval")
+	if data.ID == uuid.Nil {
+		data.ID = uuid.New()
 	}
 
-	hasTooManyBookings, err := s.bookingRepo.HasTooManyBookings(ctx, tx, data.UserID)
+	// 1. Begin transaction
+	tx, err := s.bookingRepo.BeginTx(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to check too many bookings: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	if hasTooManyBookings {
-		return fmt.Errorf("user has 3 upcoming bookings. Cannot book another one")
+	defer tx.Rollback(ctx)
+
+	if err := s.checkSlot(ctx, tx, data); err != nil {
+		return err
 	}
 
 	if err := s.bookingRepo.CreateBooking(ctx, tx, data); err != nil {
@@ -74,6 +153,49 @@ func (s *BookingService) CreateNewBooking(ctx context.Context, data Booking) err
 		return fmt.Errorf("failed to commit booking transaction: %w", err)
 	}
 
+	s.audit.Record(ctx, "booking.create", "booking", data.ID, nil, data)
+	return nil
+}
+
+// checkSlot runs the booking policies and the overlap rules for one booking inside tx.
+// the facility stays locked until tx ends, so nothing else claims the slot before the booking is written
+func (s *BookingService) checkSlot(ctx context.Context, tx pgx.Tx, data Booking) error {
+	if err := s.occupancy.LockFacility(ctx, tx, data.FacilityID); err != nil {
+		return err
+	}
+
+	if err := s.checkPolicies(ctx, tx, data); err != nil {
+		return err
+	}
+
+	hasUserOverlap, err := s.bookingRepo.UserHasOverlap(ctx, tx, data.UserID, data.StartTime, data.EndTime, data.Date)
+	if err != nil {
+		return fmt.Errorf("failed to check user overlap: %w", err)
+	}
+	if hasUserOverlap {
+		return ErrUserOverlap
+	}
+
+	//other bookings, trainer sessions, closures and holidays alike
+	conflicts, err := s.occupancy.Conflicts(ctx, tx, availability.Claim{
+		FacilityID: data.FacilityID,
+		Date:       data.Date,
+		Interval:   availability.ClockInterval(data.StartTime, data.EndTime),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to check facility overlap: %w", err)
+	}
+	for _, c := range conflicts {
+		switch c.Kind {
+		case availability.KindHoliday:
+			return fmt.Errorf("%w: %s", ErrHoliday, c.Reason)
+		case availability.KindClosure:
+			return fmt.Errorf("%w: %s", ErrFacilityClosed, c.Reason)
+		}
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%w: %s", ErrFacilityOverlap, availability.Describe(conflicts))
+	}
 	return nil
 }
 
@@ -89,6 +211,87 @@ func (s *BookingService) ListBookings(ctx context.Context, start_date time.Time,
 	return s.bookingRepo.ListBookings(ctx, nil, start_date, end_date, offset)
 }
 
-func (s *BookingService) CancelBooking(ctx context.Context, bookingID uuid.UUID, admin_note string) error {
-	return s.bookingRepo.CancelBooking(ctx, nil, bookingID, admin_note)
+// CancelBooking cancels the booking. when actorID is the owner the cancel policy applies: after the cutoff the
+// cancellation is refused or goes through with a late penalty, the result tells which. staff and admins cancel any time
+func (s *BookingService) CancelBooking(ctx context.Context, bookingID, actorID uuid.UUID, admin_note string) (penalty.CancelResult, error) {
+	result := penalty.CancelResult{Outcome: penalty.CancelOnTime}
+
+	tx, err := s.bookingRepo.BeginTx(ctx)
+	if err != nil {
+		return result, fmt.Errorf("CancelBooking: Failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := s.bookingRepo.GetBooking(ctx, tx, bookingID)
+	if err != nil {
+		return result, err
+	}
+	if before.IsCanceled {
+		return result, ErrBookingAlreadyCanceled
+	}
+
+	start := combine(before.Date, before.StartTime)
+	if actorID == before.UserID && s.cancelPolicy.IsLate(start, time.Now()) {
+		if err := s.cancelPolicy.Refuse(); err != nil {
+			return result, err
+		}
+		result.Outcome = penalty.CancelLate
+
+		p := s.cancelPolicy.LateCancelPenalty(before.UserID, uuid.Nil, bookingID, "booking", start)
+		if p != nil {
+			exists, err := s.penaltyRepo.AutomaticPenaltyExists(ctx, tx, *p)
+			if err != nil {
+				return result, err
+			}
+			if !exists {
+				if err := s.penaltyRepo.CreatePenaltyTx(ctx, tx, *p); err != nil {
+					return result, err
+				}
+				result.Outcome = penalty.CancelLatePenalized
+				result.Penalty = p
+			}
+		}
+	}
+
+	if err := s.bookingRepo.CancelBooking(ctx, tx, bookingID, admin_note); err != nil {
+		return result, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return result, fmt.Errorf("CancelBooking: Failed to commit: %w", err)
+	}
+
+	after := before
+	after.IsCanceled = true
+	after.AdminNote = admin_note
+	s.audit.Record(ctx, "booking.cancel", "booking", bookingID, before, after)
+	if result.Penalty != nil {
+		s.audit.Record(ctx, "penalty.create", "penalty", result.Penalty.ID, nil, result.Penalty)
+		s.notifier.Notify(ctx, penalty.GivenNotification(*result.Penalty))
+	}
+
+	//owners canceling their own booking already know, everyone else has to be told (with the note, if any)
+	if actorID != before.UserID {
+		body := fmt.Sprintf("Your booking on %s at %s was canceled.", before.Date.Format("2006-01-02"), before.StartTime.Format("15:04"))
+		if admin_note != "" {
+			body += " Note from the staff: " + admin_note
+		}
+		s.notifier.Notify(ctx, notification.Notification{
+			UserID:     before.UserID,
+			Type:       notification.TypeBookingCanceled,
+			Title:      "Your booking was canceled",
+			Body:       body,
+			EntityType: "booking",
+			EntityID:   &bookingID,
+		})
+	}
+	return result, nil
+}
+
+// combine puts the date part of the booking and its time-only part together, in the server local time
+func combine(date time.Time, clock time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), 0, 0, time.Local)
+}
+
+func (s *BookingService) GetBooking(ctx context.Context, bookingID uuid.UUID) (Booking, error) {
+	return s.bookingRepo.GetBooking(ctx, nil, bookingID)
 }
@@ -0,0 +1,26 @@
+package closure
+
+import (
+	"t/internal/availability"
+
+	"github.com/google/uuid"
+)
+
+// Report is what creating a closure did to the bookings and sessions it overlaps. with cancel they are canceled,
+// without it they are only reported and their owners (the trainer and the registered users, for sessions) warned.
+// the closure is stored either way, cancellations that failed are listed in Failed and can be done by hand
+type Report struct {
+	Closure          availability.Closure
+	Affected         []availability.Busy
+	Canceled         bool
+	CanceledBookings []uuid.UUID
+	CanceledSessions []uuid.UUID
+	Failed           []Failure
+}
+
+// Failure is a booking or session the closure could not cancel
+type Failure struct {
+	Kind  availability.Kind
+	RefID uuid.UUID
+	Err   error
+}
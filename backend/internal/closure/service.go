@@ -0,0 +1,149 @@
+package closure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"t/internal/audit"
+	"t/internal/availability"
+	"t/internal/booking"
+	"t/internal/notification"
+	"t/internal/session"
+
+	"github.com/google/uuid"
+)
+
+// ClosureService creates the facility closures and deals with what is already booked in them,
+// the closures themselves are checked by the availability package
+type ClosureService struct {
+	availability *availability.AvailabilityService
+	bookings     *booking.BookingService
+	sessions     *session.SessionService
+	audit        audit.Recorder
+	notifier     notification.Notifier
+}
+
+func NewClosureService(availabilitySrv *availability.AvailabilityService, bookingSrv *booking.BookingService, sessionSrv *session.SessionService, auditRec audit.Recorder, notifier notification.Notifier) *ClosureService {
+	return &ClosureService{
+		availability: availabilitySrv,
+		bookings:     bookingSrv,
+		sessions:     sessionSrv,
+		audit:        auditRec,
+		notifier:     notifier,
+	}
+}
+
+// CreateClosure stores the closure, from then on nothing can be booked or generated in it. the bookings and sessions
+// already there are canceled with the reason when cancel is set (the owners and registered users are notified by the
+// cancellation, without any late cancel penalty), otherwise their owners and registered users get a warning and the
+// report lists them. a failed cancellation does not undo the closure, it is reported in Failed and the rest goes on
+func (s *ClosureService) CreateClosure(ctx context.Context, c availability.Closure, cancel bool) (Report, error) {
+	created, affected, err := s.availability.CreateClosure(ctx, c)
+	if err != nil {
+		return Report{}, err
+	}
+	report := Report{
+		Closure:          created,
+		Affected:         affected,
+		Canceled:         cancel,
+		CanceledBookings: make([]uuid.UUID, 0),
+		CanceledSessions: make([]uuid.UUID, 0),
+		Failed:           make([]Failure, 0),
+	}
+	s.audit.Record(ctx, "closure.create", "closure", created.ID, nil, created)
+
+	reason := "facility closed: " + created.Reason
+	for _, b := range affected {
+		if !cancel {
+			s.warn(ctx, created, b)
+			continue
+		}
+
+		switch b.Kind {
+		case availability.KindBooking:
+			//uuid.Nil as the actor: nobody owns the closure, so the cancel policy never applies
+			_, err := s.bookings.CancelBooking(ctx, b.RefID, uuid.Nil, reason)
+			switch {
+			case errors.Is(err, booking.ErrBookingAlreadyCanceled):
+			case err != nil:
+				report.Failed = append(report.Failed, Failure{Kind: b.Kind, RefID: b.RefID, Err: err})
+			default:
+				report.CanceledBookings = append(report.CanceledBookings, b.RefID)
+			}
+		case availability.KindSession:
+			_, err := s.sessions.CancelSession(ctx, b.RefID, reason)
+			switch {
+			case errors.Is(err, session.ErrSessionAlreadyCanceled):
+			case err != nil:
+				report.Failed = append(report.Failed, Failure{Kind: b.Kind, RefID: b.RefID, Err: err})
+			default:
+				report.CanceledSessions = append(report.CanceledSessions, b.RefID)
+				s.notifyTrainer(ctx, created, b, "was canceled")
+			}
+		}
+	}
+	return report, nil
+}
+
+// warn tells the owner of the booking, or the trainer and the registered users of the session, that the closure overlaps it
+func (s *ClosureService) warn(ctx context.Context, c availability.Closure, b availability.Busy) {
+	switch b.Kind {
+	case availability.KindBooking:
+		bk, err := s.bookings.GetBooking(ctx, b.RefID)
+		if err != nil {
+			return
+		}
+		s.notifier.Notify(ctx, notification.Notification{
+			UserID:     bk.UserID,
+			Type:       notification.TypeFacilityClosure,
+			Title:      "The facility is closed during your booking",
+			Body:       fmt.Sprintf("The facility is closed during your booking on %s %s. Reason: %s", b.Date.Format("2006-01-02"), b.Interval, c.Reason),
+			EntityType: "booking",
+			EntityID:   &bk.ID,
+		})
+	case availability.KindSession:
+		s.notifyTrainer(ctx, c, b, "overlaps the closure")
+		users, err := s.sessions.RegisteredUsers(ctx, b.RefID)
+		if err != nil {
+			return
+		}
+		for _, userID := range users {
+			s.notifier.Notify(ctx, notification.Notification{
+				UserID:     userID,
+				Type:       notification.TypeFacilityClosure,
+				Title:      "The facility is closed during your session",
+				Body:       fmt.Sprintf("The facility is closed during the session you registered for on %s %s. Reason: %s", b.Date.Format("2006-01-02"), b.Interval, c.Reason),
+				EntityType: "session",
+				EntityID:   &b.RefID,
+			})
+		}
+	}
+}
+
+func (s *ClosureService) notifyTrainer(ctx context.Context, c availability.Closure, b availability.Busy, what string) {
+	sess, err := s.sessions.GetSession(ctx, b.RefID)
+	if err != nil {
+		return
+	}
+	s.notifier.Notify(ctx, notification.Notification{
+		UserID:     sess.TrainerID,
+		Type:       notification.TypeFacilityClosure,
+		Title:      "The facility is closed during your session",
+		Body:       fmt.Sprintf("Your session on %s %s %s. Reason: %s", b.Date.Format("2006-01-02"), b.Interval, what, c.Reason),
+		EntityType: "session",
+		EntityID:   &sess.ID,
+	})
+}
+
+// DeleteClosure reopens the facility, canceled bookings and sessions are not restored
+func (s *ClosureService) DeleteClosure(ctx context.Context, id uuid.UUID) error {
+	before, err := s.availability.GetClosure(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.availability.DeleteClosure(ctx, id); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "closure.delete", "closure", id, before, nil)
+	return nil
+}
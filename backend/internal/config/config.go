@@ -2,6 +2,7 @@ package config
 
 import (
 	"log"
+	"time"
 
 	"github.com/caarlos0/env/v10"
 	"github.com/joho/godotenv"
@@ -14,6 +15,87 @@ type Config struct {
 	DBPass string `env:"DB_PASS"`
 	DBName string `env:"DB_NAME"`
 	JWTKey string `env:"JWT_KEY"`
+
+	// access tokens are short lived, the refresh token keeps the session alive and is rotated on every use
+	AccessTokenTTL  time.Duration `env:"ACCESS_TOKEN_TTL" envDefault:"15m"`
+	RefreshTokenTTL time.Duration `env:"REFRESH_TOKEN_TTL" envDefault:"720h"`
+
+	// frontend base url, used to build the links in the emails
+	AppURL string `env:"APP_URL" envDefault:"http://localhost"`
+	// emails are written to this directory instead of being sent
+	MailOutboxDir string `env:"MAIL_OUTBOX_DIR" envDefault:"outbox"`
+	MailFrom      string `env:"MAIL_FROM" envDefault:"no-reply@localhost"`
+	// outbox writes the emails to MAIL_OUTBOX_DIR, smtp sends them through SMTP_HOST
+	MailProvider string `env:"MAIL_PROVIDER" envDefault:"outbox"`
+	SMTPHost     string `env:"SMTP_HOST"`
+	SMTPPort     string `env:"SMTP_PORT" envDefault:"587"`
+	SMTPUser     string `env:"SMTP_USER"`
+	SMTPPass     string `env:"SMTP_PASS"`
+	// refuse logins until the user verified the email
+	RequireEmailVerification bool `env:"REQUIRE_EMAIL_VERIFICATION" envDefault:"false"`
+
+	// how many weeks ahead trainer sessions are materialized from the weekly schedules
+	SessionHorizonWeeks int `env:"SESSION_HORIZON_WEEKS" envDefault:"4"`
+	// how often the background generator wakes up, 0 turns it off
+	SessionGenerationInterval time.Duration `env:"SESSION_GENERATION_INTERVAL" envDefault:"1h"`
+
+	// penalties give their points back after this many days, 0 keeps them forever
+	PenaltyExpiryDays int `env:"PENALTY_EXPIRY_DAYS" envDefault:"90"`
+	// points earned for an attended session / a booking that was used, 0 disables the reward
+	CreditSessionReward int `env:"CREDIT_SESSION_REWARD" envDefault:"1"`
+	CreditBookingReward int `env:"CREDIT_BOOKING_REWARD" envDefault:"1"`
+	// recovery never lifts the score above this, 0 means no cap
+	CreditMaxScore int `env:"CREDIT_MAX_SCORE" envDefault:"100"`
+	// only sessions and bookings that ended in the last N days are rewarded, so the first run does not pay for the whole history
+	CreditRewardLookbackDays int `env:"CREDIT_REWARD_LOOKBACK_DAYS" envDefault:"7"`
+	// how often the recovery job wakes up, 0 turns it off
+	CreditRecoveryInterval time.Duration `env:"CREDIT_RECOVERY_INTERVAL" envDefault:"1h"`
+
+	// users can check themselves in from this long before the start until the end of the slot
+	CheckInOpensBefore time.Duration `env:"CHECKIN_OPENS_BEFORE" envDefault:"15m"`
+	// checking in later than start + this marks the user as late
+	CheckInLateAfter time.Duration `env:"CHECKIN_LATE_AFTER" envDefault:"10m"`
+	// the attendance of a slot is closed this long after its end, no check-in by then is a no-show
+	AttendanceCloseAfter time.Duration `env:"ATTENDANCE_CLOSE_AFTER" envDefault:"30m"`
+	// points of the automatic penalties, 0 disables that penalty
+	NoShowPenaltyPoints int `env:"NO_SHOW_PENALTY_POINTS" envDefault:"10"`
+	LatePenaltyPoints   int `env:"LATE_PENALTY_POINTS" envDefault:"5"`
+	// how often the attendance job wakes up, 0 turns it off
+	AttendanceCloseInterval time.Duration `env:"ATTENDANCE_CLOSE_INTERVAL" envDefault:"15m"`
+	// how long the signed check-in tokens (the QR codes) stay valid, they are signed with JWT_KEY
+	CheckInTokenTTL time.Duration `env:"CHECKIN_TOKEN_TTL" envDefault:"5m"`
+
+	// owners cancel their bookings and registrations freely until this long before the start
+	CancelCutoff time.Duration `env:"CANCEL_CUTOFF" envDefault:"2h"`
+	// after the cutoff: refuse rejects the cancellation, penalty lets it through with a late penalty
+	LateCancelMode string `env:"LATE_CANCEL_MODE" envDefault:"penalty"`
+	// points of the late cancel penalty, 0 lets late cancellations through without one
+	LateCancelPenaltyPoints int `env:"LATE_CANCEL_PENALTY_POINTS" envDefault:"5"`
+
+	// granularity of the slot grid of the availability endpoint and the longest range it answers for
+	AvailabilitySlotMinutes  int `env:"AVAILABILITY_SLOT_MINUTES" envDefault:"30"`
+	AvailabilityMaxRangeDays int `env:"AVAILABILITY_MAX_RANGE_DAYS" envDefault:"31"`
+
+	// outbox writes the text messages to SMS_OUTBOX_DIR, http posts them to SMS_WEBHOOK_URL
+	SMSProvider     string `env:"SMS_PROVIDER" envDefault:"outbox"`
+	SMSOutboxDir    string `env:"SMS_OUTBOX_DIR" envDefault:"outbox/sms"`
+	SMSWebhookURL   string `env:"SMS_WEBHOOK_URL"`
+	SMSWebhookToken string `env:"SMS_WEBHOOK_TOKEN"`
+	// push notifications are written to PUSH_OUTBOX_DIR, there is no push provider yet
+	PushOutboxDir string `env:"PUSH_OUTBOX_DIR" envDefault:"outbox/push"`
+	// channels used for users who never set their notification preferences
+	NotifyEmailDefault bool `env:"NOTIFY_EMAIL_DEFAULT" envDefault:"true"`
+	NotifySMSDefault   bool `env:"NOTIFY_SMS_DEFAULT" envDefault:"false"`
+	NotifyPushDefault  bool `env:"NOTIFY_PUSH_DEFAULT" envDefault:"false"`
+	// a delivery is retried after NOTIFY_RETRY_BACKOFF, doubled every attempt up to NOTIFY_RETRY_MAX, and given up after NOTIFY_MAX_ATTEMPTS
+	NotifyMaxAttempts  int           `env:"NOTIFY_MAX_ATTEMPTS" envDefault:"5"`
+	NotifyRetryBackoff time.Duration `env:"NOTIFY_RETRY_BACKOFF" envDefault:"1m"`
+	NotifyRetryMax     time.Duration `env:"NOTIFY_RETRY_MAX" envDefault:"1h"`
+	// deliveries sent per run and how often the delivery job wakes up, 0 turns the job off
+	NotifyDeliveryBatch    int           `env:"NOTIFY_DELIVERY_BATCH" envDefault:"50"`
+	NotifyDeliveryInterval time.Duration `env:"NOTIFY_DELIVERY_INTERVAL" envDefault:"30s"`
+	// a delivery the job claimed but never finished (the process died while sending) is attempted again after this
+	NotifyClaimTimeout time.Duration `env:"NOTIFY_CLAIM_TIMEOUT" envDefault:"5m"`
 }
 
 func Load() Config {
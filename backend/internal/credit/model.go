@@ -0,0 +1,66 @@
+package credit
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reason tells why the credit score changed
+type Reason string
+
+const (
+	ReasonPenalty          Reason = "penalty"
+	ReasonPenaltyRemoved   Reason = "penalty_removed"
+	ReasonPenaltyExpired   Reason = "penalty_expired"
+	ReasonSessionAttended  Reason = "session_attended"
+	ReasonBookingCompleted Reason = "booking_completed"
+	ReasonSessionCanceled  Reason = "session_canceled" // penalty refunded because its session was canceled
+)
+
+// Entry is one row of the credit history ledger, Balance is the score right after the change
+type Entry struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Delta     int
+	Balance   int
+	Reason    Reason
+	PenaltyID *uuid.UUID
+	SessionID *uuid.UUID
+	BookingID *uuid.UUID
+	CreatedAt time.Time
+}
+
+// RecoveryConfig controls how students win their points back
+type RecoveryConfig struct {
+	PenaltyExpiry time.Duration // penalties older than this give their points back, 0 disables expiry
+	SessionReward int           // points for every attended training session, 0 disables
+	BookingReward int           // points for every booking that was used and not canceled, 0 disables
+	MaxScore      int           // recovery never lifts the score above this, 0 means no cap
+	Lookback      time.Duration // only sessions and bookings that ended within this window are rewarded
+}
+
+// ExpiredPenalty is a penalty whose points are due to be restored
+type ExpiredPenalty struct {
+	PenaltyID uuid.UUID
+	UserID    uuid.UUID
+	Points    int
+}
+
+// Reward is a finished session or booking the user did not get points for yet
+type Reward struct {
+	UserID    uuid.UUID
+	SessionID *uuid.UUID
+	BookingID *uuid.UUID
+}
+
+// RecoveryReport describes one run of the recovery job
+type RecoveryReport struct {
+	StartedAt        time.Time
+	FinishedAt       time.Time
+	LockAcquired     bool // false when another replica was running the job at the same time
+	PenaltiesExpired int
+	SessionsRewarded int
+	BookingsRewarded int
+	PointsRestored   int
+}
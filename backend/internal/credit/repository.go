@@ -0,0 +1,191 @@
+package credit
+
+import (
+	"context"
+	"fmt"
+	"t/internal/user"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CreditRepository interface {
+	BeginTx(ctx context.Context) (pgx.Tx, error)
+	ListHistory(ctx context.Context, userID uuid.UUID, offset int) ([]Entry, error)
+
+	// the recovery job runs these inside its transaction
+	ListExpiredPenalties(ctx context.Context, tx pgx.Tx, olderThanSecs float64) ([]ExpiredPenalty, error)
+	MarkPenaltyRestored(ctx context.Context, tx pgx.Tx, penaltyID uuid.UUID) error
+	ListAttendedSessions(ctx context.Context, tx pgx.Tx, withinSecs float64) ([]Reward, error)
+	ListCompletedBookings(ctx context.Context, tx pgx.Tx, withinSecs float64) ([]Reward, error)
+}
+
+type CreditRepositoryPostgres struct {
+	pool *pgxpool.Pool
+}
+
+func NewCreditRepositoryPostgres(pool *pgxpool.Pool) *CreditRepositoryPostgres {
+	return &CreditRepositoryPostgres{pool: pool}
+}
+
+func (r *CreditRepositoryPostgres) BeginTx(ctx context.Context) (pgx.Tx, error) {
+	return r.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+}
+
+// Apply changes the credit score of e.UserID by e.Delta and writes the ledger row, all inside tx.
+// positive deltas are capped at maxScore (0 means no cap), so the returned entry holds the delta that was really applied.
+// every credit_score change has to go through here, otherwise the ledger stops adding up
+func Apply(ctx context.Context, tx pgx.Tx, e Entry, maxScore int) (Entry, error) {
+	query := `WITH old AS (SELECT COALESCE(credit_score, 0) AS score FROM users WHERE user_id = $1 FOR UPDATE)
+			  UPDATE users u
+			  SET credit_score = CASE
+			          WHEN $2 > 0 AND $3 > 0 THEN GREATEST(old.score, LEAST(old.score + $2, $3))
+			          ELSE old.score + $2
+			      END,
+			      updated_at = NOW()
+			  FROM old
+			  WHERE u.user_id = $1
+			  RETURNING u.credit_score, u.credit_score - old.score`
+
+	err := tx.QueryRow(ctx, query, e.UserID, e.Delta, maxScore).Scan(&e.Balance, &e.Delta)
+	if err != nil {
+		return Entry{}, fmt.Errorf("Apply: Failed to UPDATE score: %w", err)
+	}
+
+	query = `INSERT INTO credit_history (user_id, delta, balance, reason, penalty_id, session_id, booking_id)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7)
+			 RETURNING entry_id, created_at`
+
+	err = tx.QueryRow(ctx, query, e.UserID, e.Delta, e.Balance, e.Reason, e.PenaltyID, e.SessionID, e.BookingID).Scan(&e.ID, &e.CreatedAt)
+	if err != nil {
+		return Entry{}, fmt.Errorf("Apply: Failed to INSERT history: %w", err)
+	}
+	return e, nil
+}
+
+func (r *CreditRepositoryPostgres) ListHistory(ctx context.Context, userID uuid.UUID, offset int) ([]Entry, error) {
+	query := `SELECT entry_id, user_id, delta, balance, reason, penalty_id, session_id, booking_id, created_at
+			  FROM credit_history
+			  WHERE user_id = $1
+			  ORDER BY created_at DESC
+			  OFFSET $2 LIMIT 50`
+
+	rows, err := r.pool.Query(ctx, query, userID, offset)
+	if err != nil {
+		return nil, fmt.Errorf("ListHistory: Failed to SELECT: %w", err)
+	}
+	defer rows.Close()
+
+	resp := make([]Entry, 0)
+	for rows.Next() {
+		var e Entry
+		err := rows.Scan(&e.ID, &e.UserID, &e.Delta, &e.Balance, &e.Reason, &e.PenaltyID, &e.SessionID, &e.BookingID, &e.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("ListHistory: Failed to SCAN: %w", err)
+		}
+		resp = append(resp, e)
+	}
+	return resp, rows.Err()
+}
+
+// ListExpiredPenalties locks the penalties that are older than the expiry and still hold the points
+func (r *CreditRepositoryPostgres) ListExpiredPenalties(ctx context.Context, tx pgx.Tx, olderThanSecs float64) ([]ExpiredPenalty, error) {
+	query := `SELECT penalty_id, user_id, points
+			  FROM user_penalties
+			  WHERE restored_at IS NULL
+			    AND points > 0
+			    AND user_id <> $2
+			    AND created_at < NOW() - make_interval(secs => $1)
+			  ORDER BY created_at
+			  FOR UPDATE SKIP LOCKED`
+
+	rows, err := tx.Query(ctx, query, olderThanSecs, user.DeletedUserID)
+	if err != nil {
+		return nil, fmt.Errorf("ListExpiredPenalties: Failed to SELECT: %w", err)
+	}
+	defer rows.Close()
+
+	resp := make([]ExpiredPenalty, 0)
+	for rows.Next() {
+		var p ExpiredPenalty
+		if err := rows.Scan(&p.PenaltyID, &p.UserID, &p.Points); err != nil {
+			return nil, fmt.Errorf("ListExpiredPenalties: Failed to SCAN: %w", err)
+		}
+		resp = append(resp, p)
+	}
+	return resp, rows.Err()
+}
+
+func (r *CreditRepositoryPostgres) MarkPenaltyRestored(ctx context.Context, tx pgx.Tx, penaltyID uuid.UUID) error {
+	query := `UPDATE user_penalties SET restored_at = NOW(), updated_at = NOW() WHERE penalty_id = $1`
+	if _, err := tx.Exec(ctx, query, penaltyID); err != nil {
+		return fmt.Errorf("MarkPenaltyRestored: Failed to UPDATE: %w", err)
+	}
+	return nil
+}
+
+// ListAttendedSessions returns the registrations the user checked in to, for sessions that ended within the window,
+// were not canceled, had their attendance closed and did not end with a penalty for that user, skipping the ones that
+// were already rewarded
+func (r *CreditRepositoryPostgres) ListAttendedSessions(ctx context.Context, tx pgx.Tx, withinSecs float64) ([]Reward, error) {
+	query := `SELECT r.user_id, r.session_id
+			  FROM training_session_register r
+			  JOIN trainer_sessions ts ON ts.session_id = r.session_id
+			  WHERE r.is_canceled = FALSE
+			    AND r.is_waitlisted = FALSE
+			    AND ts.is_canceled = FALSE
+			    AND r.attendance_status IN ('attended', 'late')
+			    AND r.attendance_closed_at IS NOT NULL
+			    AND r.user_id <> $2
+			    AND ts.date + ts.end_time < NOW()
+			    AND ts.date + ts.end_time >= NOW() - make_interval(secs => $1)
+			    AND NOT EXISTS (SELECT 1 FROM user_penalties p WHERE p.session_id = r.session_id AND p.user_id = r.user_id)
+			    AND NOT EXISTS (SELECT 1 FROM credit_history h
+			                    WHERE h.reason = 'session_attended' AND h.user_id = r.user_id AND h.session_id = r.session_id)`
+
+	return r.listRewards(ctx, tx, "ListAttendedSessions", query, withinSecs, true)
+}
+
+// ListCompletedBookings returns the bookings that ended within the window, were not canceled or marked as no-show
+// and did not get a penalty, skipping the ones that were already rewarded. only bookings whose attendance was closed
+// count, before that a booking nobody used is still pending and its no-show penalty has not been given yet
+func (r *CreditRepositoryPostgres) ListCompletedBookings(ctx context.Context, tx pgx.Tx, withinSecs float64) ([]Reward, error) {
+	query := `SELECT b.user_id, b.booking_id
+			  FROM bookings b
+			  WHERE b.is_canceled = FALSE
+			    AND b.attendance_status <> 'no_show'
+			    AND b.attendance_closed_at IS NOT NULL
+			    AND b.user_id <> $2
+			    AND b.date + b.end_time < NOW()
+			    AND b.date + b.end_time >= NOW() - make_interval(secs => $1)
+			    AND NOT EXISTS (SELECT 1 FROM user_penalties p WHERE p.booking_id = b.booking_id)
+			    AND NOT EXISTS (SELECT 1 FROM credit_history h
+			                    WHERE h.reason = 'booking_completed' AND h.booking_id = b.booking_id)`
+
+	return r.listRewards(ctx, tx, "ListCompletedBookings", query, withinSecs, false)
+}
+
+func (r *CreditRepositoryPostgres) listRewards(ctx context.Context, tx pgx.Tx, method string, query string, withinSecs float64, session bool) ([]Reward, error) {
+	rows, err := tx.Query(ctx, query, withinSecs, user.DeletedUserID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: Failed to SELECT: %w", method, err)
+	}
+	defer rows.Close()
+
+	resp := make([]Reward, 0)
+	for rows.Next() {
+		var rw Reward
+		var id uuid.UUID
+		if err := rows.Scan(&rw.UserID, &id); err != nil {
+			return nil, fmt.Errorf("%s: Failed to SCAN: %w", method, err)
+		}
+		if session {
+			rw.SessionID = &id
+		} else {
+			rw.BookingID = &id
+		}
+		resp = append(resp, rw)
+	}
+	return resp, rows.Err()
+}
@@ -0,0 +1,156 @@
+package credit
+
+import (
+	"context"
+	"fmt"
+	"t/internal/audit"
+	pg "t/pkg/postgres"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// key of the advisory lock, so only one replica runs the recovery at a time
+const recoveryLockKey int64 = 0xC4ED17
+
+type CreditService struct {
+	creditRepo CreditRepository
+	cfg        RecoveryConfig
+	audit      audit.Recorder
+}
+
+func NewCreditService(r CreditRepository, cfg RecoveryConfig, auditRec audit.Recorder) *CreditService {
+	return &CreditService{
+		creditRepo: r,
+		cfg:        cfg,
+		audit:      auditRec,
+	}
+}
+
+func (s *CreditService) ListHistory(ctx context.Context, userID uuid.UUID, offset int) ([]Entry, error) {
+	return s.creditRepo.ListHistory(ctx, userID, offset)
+}
+
+// Recover gives points back for expired penalties, attended sessions and completed bookings.
+// every source is marked in the same transaction as the score change, so running it twice does nothing
+func (s *CreditService) Recover(ctx context.Context) (RecoveryReport, error) {
+	report := RecoveryReport{StartedAt: time.Now()}
+
+	tx, err := s.creditRepo.BeginTx(ctx)
+	if err != nil {
+		return report, fmt.Errorf("Recover: Failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	locked, err := pg.TryAdvisoryXactLock(ctx, tx, recoveryLockKey)
+	if err != nil {
+		return report, err
+	}
+	if !locked {
+		report.FinishedAt = time.Now()
+		return report, nil
+	}
+	report.LockAcquired = true
+
+	if err := s.expirePenalties(ctx, tx, &report); err != nil {
+		return report, err
+	}
+	if err := s.rewardSessions(ctx, tx, &report); err != nil {
+		return report, err
+	}
+	if err := s.rewardBookings(ctx, tx, &report); err != nil {
+		return report, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return report, fmt.Errorf("Recover: Failed to commit: %w", err)
+	}
+	report.FinishedAt = time.Now()
+	if report.PenaltiesExpired+report.SessionsRewarded+report.BookingsRewarded > 0 {
+		s.audit.Record(ctx, "credit.recover", "credit", uuid.Nil, nil, report)
+	}
+	return report, nil
+}
+
+func (s *CreditService) expirePenalties(ctx context.Context, tx pgx.Tx, report *RecoveryReport) error {
+	if s.cfg.PenaltyExpiry <= 0 {
+		return nil
+	}
+
+	expired, err := s.creditRepo.ListExpiredPenalties(ctx, tx, s.cfg.PenaltyExpiry.Seconds())
+	if err != nil {
+		return err
+	}
+
+	for _, p := range expired {
+		penaltyID := p.PenaltyID
+		e, err := Apply(ctx, tx, Entry{
+			UserID:    p.UserID,
+			Delta:     p.Points,
+			Reason:    ReasonPenaltyExpired,
+			PenaltyID: &penaltyID,
+		}, s.cfg.MaxScore)
+		if err != nil {
+			return err
+		}
+		if err := s.creditRepo.MarkPenaltyRestored(ctx, tx, p.PenaltyID); err != nil {
+			return err
+		}
+		report.PenaltiesExpired++
+		report.PointsRestored += e.Delta
+	}
+	return nil
+}
+
+func (s *CreditService) rewardSessions(ctx context.Context, tx pgx.Tx, report *RecoveryReport) error {
+	if s.cfg.SessionReward <= 0 {
+		return nil
+	}
+
+	rewards, err := s.creditRepo.ListAttendedSessions(ctx, tx, s.cfg.Lookback.Seconds())
+	if err != nil {
+		return err
+	}
+
+	for _, rw := range rewards {
+		e, err := Apply(ctx, tx, Entry{
+			UserID:    rw.UserID,
+			Delta:     s.cfg.SessionReward,
+			Reason:    ReasonSessionAttended,
+			SessionID: rw.SessionID,
+		}, s.cfg.MaxScore)
+		if err != nil {
+			return err
+		}
+		report.SessionsRewarded++
+		report.PointsRestored += e.Delta
+	}
+	return nil
+}
+
+func (s *CreditService) rewardBookings(ctx context.Context, tx pgx.Tx, report *RecoveryReport) error {
+	if s.cfg.BookingReward <= 0 {
+		return nil
+	}
+
+	rewards, err := s.creditRepo.ListCompletedBookings(ctx, tx, s.cfg.Lookback.Seconds())
+	if err != nil {
+		return err
+	}
+
+	for _, rw := range rewards {
+		e, err := Apply(ctx, tx, Entry{
+			UserID:    rw.UserID,
+			Delta:     s.cfg.BookingReward,
+			Reason:    ReasonBookingCompleted,
+			BookingID: rw.BookingID,
+		}, s.cfg.MaxScore)
+		if err != nil {
+			return err
+		}
+		report.BookingsRewarded++
+		report.PointsRestored += e.Delta
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package facility
+
+import "errors"
+
+var (
+	ErrFacilityNotFound    = errors.New("facility not found")
+	ErrInvalidOpeningHours = errors.New("invalid opening hours")
+	ErrInvalidSeason       = errors.New("invalid season")
+	ErrSeasonNotFound      = errors.New("season not found")
+	ErrSeasonOverlap       = errors.New("season overlaps another season of the facility")
+
+	ErrFacilityTypeNotFound = errors.New("facility type not found")
+	ErrUnknownFacilityType  = errors.New("unknown facility type")
+	ErrInvalidFacilityType  = errors.New("invalid facility type")
+	ErrFacilityTypeExists   = errors.New("facility type already exists")
+	ErrFacilityTypeInUse    = errors.New("facility type is still used by facilities")
+)
@@ -0,0 +1,100 @@
+package facility
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EveryDay is the week open from open to close on every weekday
+func EveryDay(open, close time.Time) Week {
+	var w Week
+	for d := range w {
+		w[d] = []Hours{{Open: open, Close: close}}
+	}
+	return w
+}
+
+// On returns the opening intervals on date: the ones of the season covering it, otherwise the regular week's
+func (o OpeningHours) On(date time.Time) []Hours {
+	for _, s := range o.Seasons {
+		if s.Covers(date) {
+			return s.Week[date.Weekday()]
+		}
+	}
+	return o.Regular[date.Weekday()]
+}
+
+// Span is the earliest opening and the latest closing of the week, ok is false when it is closed all week
+func (w Week) Span() (time.Time, time.Time, bool) {
+	var open, close time.Time
+	found := false
+	for _, day := range w {
+		for _, h := range day {
+			if !found || clock(h.Open) < clock(open) {
+				open = h.Open
+			}
+			if !found || clock(h.Close) > clock(close) {
+				close = h.Close
+			}
+			found = true
+		}
+	}
+	return open, close, found
+}
+
+// Validate sorts the intervals of every day and rejects the empty and the overlapping ones
+func (w Week) Validate() error {
+	for d, day := range w {
+		sort.Slice(day, func(a, b int) bool { return clock(day[a].Open) < clock(day[b].Open) })
+		for i, h := range day {
+			if clock(h.Close) <= clock(h.Open) {
+				return fmt.Errorf("%w: %s %s closes before it opens", ErrInvalidOpeningHours, time.Weekday(d), FormatHours([]Hours{h}))
+			}
+			if i > 0 && clock(h.Open) < clock(day[i-1].Close) {
+				return fmt.Errorf("%w: %s intervals overlap", ErrInvalidOpeningHours, time.Weekday(d))
+			}
+		}
+	}
+	return nil
+}
+
+func (s Season) Covers(date time.Time) bool {
+	d := dateOnly(date)
+	return !d.Before(dateOnly(s.StartDate)) && !d.After(dateOnly(s.EndDate))
+}
+
+func (s Season) Overlaps(o Season) bool {
+	return !dateOnly(s.StartDate).After(dateOnly(o.EndDate)) && !dateOnly(o.StartDate).After(dateOnly(s.EndDate))
+}
+
+func (s *Season) Validate() error {
+	if strings.TrimSpace(s.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidSeason)
+	}
+	if s.EndDate.Before(s.StartDate) {
+		return fmt.Errorf("%w: end_date is before start_date", ErrInvalidSeason)
+	}
+	return s.Week.Validate()
+}
+
+// FormatHours prints the intervals as "08:00-12:00, 14:00-22:00", or "closed"
+func FormatHours(hours []Hours) string {
+	if len(hours) == 0 {
+		return "closed"
+	}
+	parts := make([]string, 0, len(hours))
+	for _, h := range hours {
+		parts = append(parts, h.Open.Format("15:04")+"-"+h.Close.Format("15:04"))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func clock(t time.Time) int {
+	return t.Hour()*60 + t.Minute()
+}
+
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -9,13 +9,56 @@ import (
 type Facility struct {
 	ID          uuid.UUID
 	Name        string
-	Type        string
+	Type        string // name of its FacilityType
 	Description string
 	Capacity    int
-	OpenTime    time.Time
-	CloseTime   time.Time
+	Hours       OpeningHours
 	ImageURL    string
 	IsActive    bool
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 }
+
+// Hours is one opening interval of a day, Open and Close are time-of-day values like the time columns
+type Hours struct {
+	Open  time.Time
+	Close time.Time
+}
+
+// Week is the opening hours of every weekday, indexed by time.Weekday. a day may have several intervals
+// (closed over lunch) or none at all (closed that day)
+type Week [7][]Hours
+
+// Season overrides the regular week from StartDate to EndDate, both inclusive: on those dates only the
+// season's week counts, a weekday without hours in it is closed
+type Season struct {
+	ID         uuid.UUID
+	FacilityID uuid.UUID
+	Name       string
+	StartDate  time.Time
+	EndDate    time.Time
+	Week       Week
+	CreatedAt  time.Time
+}
+
+// OpeningHours is the regular week of the facility and its seasonal overrides
+type OpeningHours struct {
+	Regular Week
+	Seasons []Season
+}
+
+// FacilityType is a category of facilities (a sport, a pool, a climbing wall). the rules are the default booking rules
+// of its facilities, nil leaves the rule to the other booking policies
+type FacilityType struct {
+	ID                  uuid.UUID
+	Name                string
+	Icon                string
+	Description         string
+	MaxUpcomingBookings *int
+	MinCreditScore      *int
+	MaxBookingsPerDay   *int
+	MinSlotMinutes      *int
+	MaxSlotMinutes      *int
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
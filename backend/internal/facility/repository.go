@@ -7,6 +7,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -16,6 +17,24 @@ type FacilityRepository interface {
 	CreateFacility(context.Context, Facility) error
 	UpdateFacility(context.Context, Facility) error
 	DeleteFacility(context.Context, uuid.UUID) error
+
+	// SetRegularHours replaces the regular week of the facility
+	SetRegularHours(ctx context.Context, facilityID uuid.UUID, week Week) error
+	GetSeason(ctx context.Context, id uuid.UUID) (Season, error)
+	CreateSeason(ctx context.Context, season Season) error
+	// UpdateSeason replaces the name, the dates and the week of the season
+	UpdateSeason(ctx context.Context, season Season) error
+	DeleteSeason(ctx context.Context, id uuid.UUID) error
+
+	ListFacilityTypes(ctx context.Context) ([]FacilityType, error)
+	GetFacilityType(ctx context.Context, id uuid.UUID) (FacilityType, error)
+	// GetFacilityTypeByName returns ErrUnknownFacilityType when no type has the name
+	GetFacilityTypeByName(ctx context.Context, name string) (FacilityType, error)
+	CreateFacilityType(ctx context.Context, t FacilityType) error
+	// UpdateFacilityType also renames the type on its facilities
+	UpdateFacilityType(ctx context.Context, t FacilityType) error
+	// DeleteFacilityType returns ErrFacilityTypeInUse while facilities still have the type
+	DeleteFacilityType(ctx context.Context, id uuid.UUID) error
 }
 
 type FacilityRepositoryPostgres struct {
@@ -38,8 +57,6 @@ func (r *FacilityRepositoryPostgres) GetFacility(ctx context.Context, id uuid.UU
 			type,
 			description,
 			capacity,
-			open_time,
-			close_time,
 			image_url,
 			is_active,
 			created_at,
@@ -54,8 +71,6 @@ func (r *FacilityRepositoryPostgres) GetFacility(ctx context.Context, id uuid.UU
 		&f.Type,
 		&f.Description,
 		&f.Capacity,
-		&f.OpenTime,
-		&f.CloseTime,
 		&f.ImageURL,
 		&f.IsActive,
 		&f.CreatedAt,
@@ -64,11 +79,14 @@ func (r *FacilityRepositoryPostgres) GetFacility(ctx context.Context, id uuid.UU
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return Facility{}, fmt.Errorf("facility not found: %w", err)
+			return Facility{}, ErrFacilityNotFound
 		}
 		return Facility{}, fmt.Errorf("repository.GetFacility: %w", err)
 	}
 
+	if err := r.loadHours(ctx, []*Facility{&f}); err != nil {
+		return Facility{}, err
+	}
 	return f, nil
 }
 
@@ -80,8 +98,6 @@ func (r *FacilityRepositoryPostgres) ListFacilities(ctx context.Context) ([]Faci
 			type,
 			description,
 			capacity,
-			open_time,
-			close_time,
 			image_url,
 			is_active,
 			created_at,
@@ -105,8 +121,6 @@ func (r *FacilityRepositoryPostgres) ListFacilities(ctx context.Context) ([]Faci
 			&f.Type,
 			&f.Description,
 			&f.Capacity,
-			&f.OpenTime,
-			&f.CloseTime,
 			&f.ImageURL,
 			&f.IsActive,
 			&f.CreatedAt,
@@ -123,53 +137,395 @@ func (r *FacilityRepositoryPostgres) ListFacilities(ctx context.Context) ([]Faci
 		return nil, fmt.Errorf("repository.ListFacilities rows: %w", rows.Err())
 	}
 
+	ptrs := make([]*Facility, 0, len(facilities))
+	for i := range facilities {
+		ptrs = append(ptrs, &facilities[i])
+	}
+	if err := r.loadHours(ctx, ptrs); err != nil {
+		return nil, err
+	}
 	return facilities, nil
 }
 
 func (r *FacilityRepositoryPostgres) CreateFacility(ctx context.Context, facility Facility) error {
-	query := `INSERT INTO facilities (name, type, description, capacity, open_time, close_time, image_url, is_active)
-              VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("repository.CreateFacility: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `INSERT INTO facilities (facility_id, name, type, description, capacity, image_url, is_active)
+              VALUES ($1, $2, $3, $4, $5, $6, $7)`
 
-	_, err := r.pool.Exec(ctx, query,
+	_, err = tx.Exec(ctx, query,
+		facility.ID,
 		facility.Name,
 		facility.Type,
 		facility.Description,
 		facility.Capacity,
-		facility.OpenTime,
-		facility.CloseTime,
 		facility.ImageURL,
 		facility.IsActive,
 	)
-
-	return err
+	if err != nil {
+		return err
+	}
+	if err := insertWeek(ctx, tx, facility.ID, nil, facility.Hours.Regular); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
+// UpdateFacility writes the facility and its regular week, the seasons are managed on their own
 func (r *FacilityRepositoryPostgres) UpdateFacility(ctx context.Context, facility Facility) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("repository.UpdateFacility: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	query := `UPDATE facilities 
               SET name = $2, type = $3, description = $4, capacity = $5, 
-                  open_time = $6, close_time = $7, image_url = $8, is_active = $9,
+                  image_url = $6, is_active = $7,
                   updated_at = NOW()
               WHERE facility_id = $1`
 
-	_, err := r.pool.Exec(ctx, query,
+	tag, err := tx.Exec(ctx, query,
 		facility.ID,
 		facility.Name,
 		facility.Type,
 		facility.Description,
 		facility.Capacity,
-		facility.OpenTime,
-		facility.CloseTime,
 		facility.ImageURL,
 		facility.IsActive,
 	)
-
-	return err
+	if err != nil {
+		return fmt.Errorf("repository.UpdateFacility: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrFacilityNotFound
+	}
+	if err := replaceRegularWeek(ctx, tx, facility.ID, facility.Hours.Regular); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("repository.UpdateFacility: %w", err)
+	}
+	return nil
 }
 
 func (r *FacilityRepositoryPostgres) DeleteFacility(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM facilities WHERE facility_id = $1`
 
-	_, err := r.pool.Exec(ctx, query, id)
+	tag, err := r.pool.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("repository.DeleteFacility: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrFacilityNotFound
+	}
+	return nil
+}
+
+// loadHours fills the regular week and the seasons of the facilities with two queries for all of them
+func (r *FacilityRepositoryPostgres) loadHours(ctx context.Context, facilities []*Facility) error {
+	if len(facilities) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, 0, len(facilities))
+	byID := make(map[uuid.UUID]*Facility, len(facilities))
+	for _, f := range facilities {
+		ids = append(ids, f.ID)
+		byID[f.ID] = f
+	}
+
+	seasons := make(map[uuid.UUID]*Season)
+	rows, err := r.pool.Query(ctx, `SELECT season_id, facility_id, name, start_date, end_date, created_at
+									FROM facility_seasons WHERE facility_id = ANY($1) ORDER BY start_date`, ids)
+	if err != nil {
+		return fmt.Errorf("repository.loadHours seasons: %w", err)
+	}
+	for rows.Next() {
+		var s Season
+		if err := rows.Scan(&s.ID, &s.FacilityID, &s.Name, &s.StartDate, &s.EndDate, &s.CreatedAt); err != nil {
+			rows.Close()
+			return fmt.Errorf("repository.loadHours seasons scan: %w", err)
+		}
+		f := byID[s.FacilityID]
+		f.Hours.Seasons = append(f.Hours.Seasons, s)
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		return fmt.Errorf("repository.loadHours seasons rows: %w", rows.Err())
+	}
+	//the pointers are taken once every append is done
+	for _, f := range facilities {
+		for i := range f.Hours.Seasons {
+			seasons[f.Hours.Seasons[i].ID] = &f.Hours.Seasons[i]
+		}
+	}
+
+	rows, err = r.pool.Query(ctx, `SELECT facility_id, season_id, weekday, open_time, close_time
+								   FROM facility_opening_hours WHERE facility_id = ANY($1) ORDER BY open_time`, ids)
+	if err != nil {
+		return fmt.Errorf("repository.loadHours: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var facilityID uuid.UUID
+		var seasonID *uuid.UUID
+		var weekday int
+		var h Hours
+		if err := rows.Scan(&facilityID, &seasonID, &weekday, &h.Open, &h.Close); err != nil {
+			return fmt.Errorf("repository.loadHours scan: %w", err)
+		}
+		week := &byID[facilityID].Hours.Regular
+		if seasonID != nil {
+			s, ok := seasons[*seasonID]
+			if !ok {
+				continue
+			}
+			week = &s.Week
+		}
+		week[weekday] = append(week[weekday], h)
+	}
+	if rows.Err() != nil {
+		return fmt.Errorf("repository.loadHours rows: %w", rows.Err())
+	}
+	return nil
+}
+
+func insertWeek(ctx context.Context, tx pgx.Tx, facilityID uuid.UUID, seasonID *uuid.UUID, week Week) error {
+	query := `INSERT INTO facility_opening_hours (facility_id, season_id, weekday, open_time, close_time) VALUES ($1, $2, $3, $4, $5)`
+
+	for d, day := range week {
+		for _, h := range day {
+			if _, err := tx.Exec(ctx, query, facilityID, seasonID, d, h.Open, h.Close); err != nil {
+				return fmt.Errorf("repository.insertWeek: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+func replaceRegularWeek(ctx context.Context, tx pgx.Tx, facilityID uuid.UUID, week Week) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM facility_opening_hours WHERE facility_id = $1 AND season_id IS NULL`, facilityID); err != nil {
+		return fmt.Errorf("repository.replaceRegularWeek: %w", err)
+	}
+	return insertWeek(ctx, tx, facilityID, nil, week)
+}
+
+func (r *FacilityRepositoryPostgres) SetRegularHours(ctx context.Context, facilityID uuid.UUID, week Week) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("repository.SetRegularHours: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := replaceRegularWeek(ctx, tx, facilityID, week); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("repository.SetRegularHours: %w", err)
+	}
+	return nil
+}
+
+func (r *FacilityRepositoryPostgres) GetSeason(ctx context.Context, id uuid.UUID) (Season, error) {
+	var s Season
+	query := `SELECT season_id, facility_id, name, start_date, end_date, created_at FROM facility_seasons WHERE season_id = $1`
+
+	err := r.pool.QueryRow(ctx, query, id).Scan(&s.ID, &s.FacilityID, &s.Name, &s.StartDate, &s.EndDate, &s.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Season{}, ErrSeasonNotFound
+		}
+		return Season{}, fmt.Errorf("repository.GetSeason: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, `SELECT weekday, open_time, close_time FROM facility_opening_hours WHERE season_id = $1 ORDER BY open_time`, id)
+	if err != nil {
+		return Season{}, fmt.Errorf("repository.GetSeason hours: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var weekday int
+		var h Hours
+		if err := rows.Scan(&weekday, &h.Open, &h.Close); err != nil {
+			return Season{}, fmt.Errorf("repository.GetSeason scan: %w", err)
+		}
+		s.Week[weekday] = append(s.Week[weekday], h)
+	}
+	if rows.Err() != nil {
+		return Season{}, fmt.Errorf("repository.GetSeason rows: %w", rows.Err())
+	}
+	return s, nil
+}
+
+func (r *FacilityRepositoryPostgres) CreateSeason(ctx context.Context, season Season) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("repository.CreateSeason: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `INSERT INTO facility_seasons (season_id, facility_id, name, start_date, end_date) VALUES ($1, $2, $3, $4, $5)`
+	if _, err := tx.Exec(ctx, query, season.ID, season.FacilityID, season.Name, season.StartDate, season.EndDate); err != nil {
+		return fmt.Errorf("repository.CreateSeason: %w", err)
+	}
+	if err := insertWeek(ctx, tx, season.FacilityID, &season.ID, season.Week); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("repository.CreateSeason: %w", err)
+	}
+	return nil
+}
+
+func (r *FacilityRepositoryPostgres) UpdateSeason(ctx context.Context, season Season) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("repository.UpdateSeason: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `UPDATE facility_seasons SET name = $2, start_date = $3, end_date = $4 WHERE season_id = $1`
+	tag, err := tx.Exec(ctx, query, season.ID, season.Name, season.StartDate, season.EndDate)
+	if err != nil {
+		return fmt.Errorf("repository.UpdateSeason: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrSeasonNotFound
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM facility_opening_hours WHERE season_id = $1`, season.ID); err != nil {
+		return fmt.Errorf("repository.UpdateSeason: %w", err)
+	}
+	if err := insertWeek(ctx, tx, season.FacilityID, &season.ID, season.Week); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("repository.UpdateSeason: %w", err)
+	}
+	return nil
+}
+
+func (r *FacilityRepositoryPostgres) DeleteSeason(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM facility_seasons WHERE season_id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("repository.DeleteSeason: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrSeasonNotFound
+	}
+	return nil
+}
+
+const facilityTypeColumns = `type_id, name, icon, description, max_upcoming_bookings, min_credit_score, max_bookings_per_day,
+		min_slot_minutes, max_slot_minutes, created_at, updated_at`
+
+func scanFacilityType(row pgx.Row) (FacilityType, error) {
+	var t FacilityType
+	err := row.Scan(&t.ID, &t.Name, &t.Icon, &t.Description, &t.MaxUpcomingBookings, &t.MinCreditScore, &t.MaxBookingsPerDay,
+		&t.MinSlotMinutes, &t.MaxSlotMinutes, &t.CreatedAt, &t.UpdatedAt)
+	return t, err
+}
+
+// typeWriteError turns the constraint violations of facility_types into domain errors
+func typeWriteError(op string, err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505":
+			return ErrFacilityTypeExists
+		case "23503":
+			return ErrFacilityTypeInUse
+		}
+	}
+	return fmt.Errorf("repository.%s: %w", op, err)
+}
+
+func (r *FacilityRepositoryPostgres) ListFacilityTypes(ctx context.Context) ([]FacilityType, error) {
+	query := `SELECT ` + facilityTypeColumns + ` FROM facility_types ORDER BY name`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("repository.ListFacilityTypes: %w", err)
+	}
+	defer rows.Close()
+
+	types := make([]FacilityType, 0)
+	for rows.Next() {
+		t, err := scanFacilityType(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository.ListFacilityTypes scan: %w", err)
+		}
+		types = append(types, t)
+	}
+	return types, rows.Err()
+}
+
+func (r *FacilityRepositoryPostgres) GetFacilityType(ctx context.Context, id uuid.UUID) (FacilityType, error) {
+	query := `SELECT ` + facilityTypeColumns + ` FROM facility_types WHERE type_id = $1`
+
+	t, err := scanFacilityType(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return FacilityType{}, ErrFacilityTypeNotFound
+		}
+		return FacilityType{}, fmt.Errorf("repository.GetFacilityType: %w", err)
+	}
+	return t, nil
+}
+
+func (r *FacilityRepositoryPostgres) GetFacilityTypeByName(ctx context.Context, name string) (FacilityType, error) {
+	query := `SELECT ` + facilityTypeColumns + ` FROM facility_types WHERE name = $1`
+
+	t, err := scanFacilityType(r.pool.QueryRow(ctx, query, name))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return FacilityType{}, fmt.Errorf("%w: %q", ErrUnknownFacilityType, name)
+		}
+		return FacilityType{}, fmt.Errorf("repository.GetFacilityTypeByName: %w", err)
+	}
+	return t, nil
+}
+
+func (r *FacilityRepositoryPostgres) CreateFacilityType(ctx context.Context, t FacilityType) error {
+	query := `INSERT INTO facility_types (type_id, name, icon, description, max_upcoming_bookings, min_credit_score, max_bookings_per_day,
+				min_slot_minutes, max_slot_minutes)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+
+	_, err := r.pool.Exec(ctx, query, t.ID, t.Name, t.Icon, t.Description, t.MaxUpcomingBookings, t.MinCreditScore, t.MaxBookingsPerDay,
+		t.MinSlotMinutes, t.MaxSlotMinutes)
+	if err != nil {
+		return typeWriteError("CreateFacilityType", err)
+	}
+	return nil
+}
 
-	return err
+func (r *FacilityRepositoryPostgres) UpdateFacilityType(ctx context.Context, t FacilityType) error {
+	query := `UPDATE facility_types
+			  SET name = $2, icon = $3, description = $4, max_upcoming_bookings = $5, min_credit_score = $6, max_bookings_per_day = $7,
+			      min_slot_minutes = $8, max_slot_minutes = $9, updated_at = NOW()
+			  WHERE type_id = $1`
+
+	tag, err := r.pool.Exec(ctx, query, t.ID, t.Name, t.Icon, t.Description, t.MaxUpcomingBookings, t.MinCreditScore, t.MaxBookingsPerDay,
+		t.MinSlotMinutes, t.MaxSlotMinutes)
+	if err != nil {
+		return typeWriteError("UpdateFacilityType", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrFacilityTypeNotFound
+	}
+	return nil
+}
+
+func (r *FacilityRepositoryPostgres) DeleteFacilityType(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM facility_types WHERE type_id = $1`, id)
+	if err != nil {
+		return typeWriteError("DeleteFacilityType", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrFacilityTypeNotFound
+	}
+	return nil
 }
@@ -2,23 +2,40 @@ package facility
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"t/internal/audit"
 
 	"github.com/google/uuid"
 )
 
 type FacilityService struct {
 	facilityRepo FacilityRepository
+	audit        audit.Recorder
 }
 
-func NewFacilityService(r FacilityRepository) *FacilityService {
+func NewFacilityService(r FacilityRepository, auditRec audit.Recorder) *FacilityService {
 	return &FacilityService{
 		facilityRepo: r,
+		audit:        auditRec,
 	}
 }
 
 func (s *FacilityService) CreateFacility(ctx context.Context, f Facility) error {
-	err := s.facilityRepo.CreateFacility(ctx, f)
-	return err
+	if f.ID == uuid.Nil {
+		f.ID = uuid.New()
+	}
+	if err := f.Hours.Regular.Validate(); err != nil {
+		return err
+	}
+	if _, err := s.facilityRepo.GetFacilityTypeByName(ctx, f.Type); err != nil {
+		return err
+	}
+	if err := s.facilityRepo.CreateFacility(ctx, f); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "facility.create", "facility", f.ID, nil, f)
+	return nil
 }
 
 func (s *FacilityService) GetFacility(ctx context.Context, id uuid.UUID) (Facility, error) {
@@ -30,14 +47,187 @@ func (s *FacilityService) ListFacilities(ctx context.Context) ([]Facility, error
 }
 
 func (s *FacilityService) UpdateFacility(ctx context.Context, f Facility) error {
-	_, err := s.facilityRepo.GetFacility(ctx, f.ID)
+	before, err := s.facilityRepo.GetFacility(ctx, f.ID)
 	if err != nil {
 
 		return err // facility does not exist
 	}
-	return s.facilityRepo.UpdateFacility(ctx, f)
+	if err := f.Hours.Regular.Validate(); err != nil {
+		return err
+	}
+	if f.Type != before.Type {
+		if _, err := s.facilityRepo.GetFacilityTypeByName(ctx, f.Type); err != nil {
+			return err
+		}
+	}
+	if err := s.facilityRepo.UpdateFacility(ctx, f); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "facility.update", "facility", f.ID, before, f)
+	return nil
 }
 
 func (s *FacilityService) DeleteFacility(ctx context.Context, id uuid.UUID) error {
-	return s.facilityRepo.DeleteFacility(ctx, id)
+	before, err := s.facilityRepo.GetFacility(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.facilityRepo.DeleteFacility(ctx, id); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "facility.delete", "facility", id, before, nil)
+	return nil
+}
+
+// SetOpeningHours replaces the regular week of the facility, the seasons stay as they are
+func (s *FacilityService) SetOpeningHours(ctx context.Context, facilityID uuid.UUID, week Week) (OpeningHours, error) {
+	if err := week.Validate(); err != nil {
+		return OpeningHours{}, err
+	}
+	before, err := s.facilityRepo.GetFacility(ctx, facilityID)
+	if err != nil {
+		return OpeningHours{}, err
+	}
+	if err := s.facilityRepo.SetRegularHours(ctx, facilityID, week); err != nil {
+		return OpeningHours{}, err
+	}
+
+	after := before.Hours
+	after.Regular = week
+	s.audit.Record(ctx, "facility.hours", "facility", facilityID, before.Hours, after)
+	return after, nil
+}
+
+// CreateSeason adds a seasonal override, seasons of one facility cannot overlap
+func (s *FacilityService) CreateSeason(ctx context.Context, season Season) (Season, error) {
+	if err := season.Validate(); err != nil {
+		return Season{}, err
+	}
+	if err := s.checkSeasonOverlap(ctx, season); err != nil {
+		return Season{}, err
+	}
+	if season.ID == uuid.Nil {
+		season.ID = uuid.New()
+	}
+	if err := s.facilityRepo.CreateSeason(ctx, season); err != nil {
+		return Season{}, err
+	}
+	s.audit.Record(ctx, "facility.season.create", "facility_season", season.ID, nil, season)
+	return season, nil
+}
+
+// UpdateSeason replaces the season, it stays on its facility
+func (s *FacilityService) UpdateSeason(ctx context.Context, season Season) (Season, error) {
+	before, err := s.facilityRepo.GetSeason(ctx, season.ID)
+	if err != nil {
+		return Season{}, err
+	}
+	season.FacilityID = before.FacilityID
+	season.CreatedAt = before.CreatedAt
+	if err := season.Validate(); err != nil {
+		return Season{}, err
+	}
+	if err := s.checkSeasonOverlap(ctx, season); err != nil {
+		return Season{}, err
+	}
+	if err := s.facilityRepo.UpdateSeason(ctx, season); err != nil {
+		return Season{}, err
+	}
+	s.audit.Record(ctx, "facility.season.update", "facility_season", season.ID, before, season)
+	return season, nil
+}
+
+func (s *FacilityService) DeleteSeason(ctx context.Context, id uuid.UUID) error {
+	before, err := s.facilityRepo.GetSeason(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.facilityRepo.DeleteSeason(ctx, id); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "facility.season.delete", "facility_season", id, before, nil)
+	return nil
+}
+
+// checkSeasonOverlap makes sure a date never falls in two seasons, the facility has to exist
+func (s *FacilityService) checkSeasonOverlap(ctx context.Context, season Season) error {
+	f, err := s.facilityRepo.GetFacility(ctx, season.FacilityID)
+	if err != nil {
+		return err
+	}
+	for _, other := range f.Hours.Seasons {
+		if other.ID != season.ID && other.Overlaps(season) {
+			return fmt.Errorf("%w: %s (%s to %s)", ErrSeasonOverlap, other.Name, other.StartDate.Format("2006-01-02"), other.EndDate.Format("2006-01-02"))
+		}
+	}
+	return nil
+}
+
+func (s *FacilityService) ListFacilityTypes(ctx context.Context) ([]FacilityType, error) {
+	return s.facilityRepo.ListFacilityTypes(ctx)
+}
+
+func (s *FacilityService) GetFacilityType(ctx context.Context, id uuid.UUID) (FacilityType, error) {
+	return s.facilityRepo.GetFacilityType(ctx, id)
+}
+
+func (s *FacilityService) CreateFacilityType(ctx context.Context, t FacilityType) (FacilityType, error) {
+	if err := t.Validate(); err != nil {
+		return FacilityType{}, err
+	}
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	if err := s.facilityRepo.CreateFacilityType(ctx, t); err != nil {
+		return FacilityType{}, err
+	}
+	created, err := s.facilityRepo.GetFacilityType(ctx, t.ID)
+	if err != nil {
+		return FacilityType{}, err
+	}
+	s.audit.Record(ctx, "facility_type.create", "facility_type", created.ID, nil, created)
+	return created, nil
+}
+
+// UpdateFacilityType replaces the type, a new name is carried over to its facilities
+func (s *FacilityService) UpdateFacilityType(ctx context.Context, t FacilityType) (FacilityType, error) {
+	if err := t.Validate(); err != nil {
+		return FacilityType{}, err
+	}
+	before, err := s.facilityRepo.GetFacilityType(ctx, t.ID)
+	if err != nil {
+		return FacilityType{}, err
+	}
+	if err := s.facilityRepo.UpdateFacilityType(ctx, t); err != nil {
+		return FacilityType{}, err
+	}
+	updated, err := s.facilityRepo.GetFacilityType(ctx, t.ID)
+	if err != nil {
+		return FacilityType{}, err
+	}
+	s.audit.Record(ctx, "facility_type.update", "facility_type", t.ID, before, updated)
+	return updated, nil
+}
+
+// DeleteFacilityType removes a type no facility uses anymore
+func (s *FacilityService) DeleteFacilityType(ctx context.Context, id uuid.UUID) error {
+	before, err := s.facilityRepo.GetFacilityType(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.facilityRepo.DeleteFacilityType(ctx, id); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "facility_type.delete", "facility_type", id, before, nil)
+	return nil
+}
+
+func (t FacilityType) Validate() error {
+	if strings.TrimSpace(t.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidFacilityType)
+	}
+	if t.MinSlotMinutes != nil && t.MaxSlotMinutes != nil && *t.MinSlotMinutes > *t.MaxSlotMinutes {
+		return fmt.Errorf("%w: min_slot_minutes is greater than max_slot_minutes", ErrInvalidFacilityType)
+	}
+	return nil
 }
@@ -0,0 +1,8 @@
+package holiday
+
+import "errors"
+
+var (
+	ErrHolidayNotFound = errors.New("holiday not found")
+	ErrInvalidHoliday  = errors.New("invalid holiday")
+)
@@ -0,0 +1,40 @@
+package holiday
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Holiday is a campus holiday or break, no sessions are generated and nothing can be booked from StartDate
+// to EndDate, both inclusive
+type Holiday struct {
+	ID        uuid.UUID
+	Name      string
+	StartDate time.Time
+	EndDate   time.Time
+	UID       string // UID of the iCalendar event it was imported from, empty when created by hand
+	CreatedBy *uuid.UUID
+	CreatedAt time.Time
+}
+
+// SkippedEvent is a calendar event the import could not turn into a holiday
+type SkippedEvent struct {
+	UID     string
+	Summary string
+	Reason  string
+}
+
+// ImportReport is what importing a calendar did. events already imported (same UID) are updated in place
+type ImportReport struct {
+	Created          []Holiday
+	Updated          []Holiday
+	Skipped          []SkippedEvent
+	CanceledSessions []uuid.UUID
+}
+
+// Result is a created holiday with the sessions its creation canceled
+type Result struct {
+	Holiday          Holiday
+	CanceledSessions []uuid.UUID
+}
@@ -0,0 +1,141 @@
+package holiday
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type HolidayRepository interface {
+	ListHolidays(ctx context.Context, from, to time.Time) ([]Holiday, error)
+	GetHoliday(ctx context.Context, id uuid.UUID) (Holiday, error)
+	CreateHoliday(ctx context.Context, h Holiday) error
+	// UpsertHoliday creates the holiday or, when one with the same UID exists, updates its name and dates.
+	// it returns the stored holiday and whether it was created
+	UpsertHoliday(ctx context.Context, h Holiday) (Holiday, bool, error)
+	DeleteHoliday(ctx context.Context, id uuid.UUID) error
+
+	// ListSessionsBetween returns the ids of the non-canceled sessions between from and to (inclusive)
+	// that have not started yet
+	ListSessionsBetween(ctx context.Context, from, to time.Time) ([]uuid.UUID, error)
+}
+
+type HolidayRepositoryPostgres struct {
+	pool *pgxpool.Pool
+}
+
+func NewHolidayRepositoryPostgres(p *pgxpool.Pool) *HolidayRepositoryPostgres {
+	return &HolidayRepositoryPostgres{
+		pool: p,
+	}
+}
+
+const holidayColumns = `holiday_id, name, start_date, end_date, COALESCE(uid, ''), created_by, created_at`
+
+func scanHoliday(row pgx.Row) (Holiday, error) {
+	var h Holiday
+	err := row.Scan(&h.ID, &h.Name, &h.StartDate, &h.EndDate, &h.UID, &h.CreatedBy, &h.CreatedAt)
+	return h, err
+}
+
+func (r *HolidayRepositoryPostgres) ListHolidays(ctx context.Context, from, to time.Time) ([]Holiday, error) {
+	query := `SELECT ` + holidayColumns + ` FROM holidays WHERE start_date <= $2 AND end_date >= $1 ORDER BY start_date, name`
+
+	rows, err := r.pool.Query(ctx, query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("ListHolidays: Failed to query: %w", err)
+	}
+	defer rows.Close()
+
+	holidays := make([]Holiday, 0)
+	for rows.Next() {
+		h, err := scanHoliday(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ListHolidays: Failed to scan: %w", err)
+		}
+		holidays = append(holidays, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ListHolidays: %w", err)
+	}
+	return holidays, nil
+}
+
+func (r *HolidayRepositoryPostgres) GetHoliday(ctx context.Context, id uuid.UUID) (Holiday, error) {
+	query := `SELECT ` + holidayColumns + ` FROM holidays WHERE holiday_id = $1`
+
+	h, err := scanHoliday(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Holiday{}, ErrHolidayNotFound
+		}
+		return Holiday{}, fmt.Errorf("GetHoliday: Failed to scan: %w", err)
+	}
+	return h, nil
+}
+
+func (r *HolidayRepositoryPostgres) CreateHoliday(ctx context.Context, h Holiday) error {
+	query := `INSERT INTO holidays (holiday_id, name, start_date, end_date, uid, created_by) VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6)`
+
+	if _, err := r.pool.Exec(ctx, query, h.ID, h.Name, h.StartDate, h.EndDate, h.UID, h.CreatedBy); err != nil {
+		return fmt.Errorf("CreateHoliday: Failed to insert: %w", err)
+	}
+	return nil
+}
+
+func (r *HolidayRepositoryPostgres) UpsertHoliday(ctx context.Context, h Holiday) (Holiday, bool, error) {
+	//xmax is 0 only for a freshly inserted row
+	query := `INSERT INTO holidays (holiday_id, name, start_date, end_date, uid, created_by) VALUES ($1, $2, $3, $4, $5, $6)
+			  ON CONFLICT (uid) DO UPDATE SET name = EXCLUDED.name, start_date = EXCLUDED.start_date, end_date = EXCLUDED.end_date, updated_at = NOW()
+			  RETURNING ` + holidayColumns + `, (xmax = 0)`
+
+	var stored Holiday
+	var created bool
+	err := r.pool.QueryRow(ctx, query, h.ID, h.Name, h.StartDate, h.EndDate, h.UID, h.CreatedBy).
+		Scan(&stored.ID, &stored.Name, &stored.StartDate, &stored.EndDate, &stored.UID, &stored.CreatedBy, &stored.CreatedAt, &created)
+	if err != nil {
+		return Holiday{}, false, fmt.Errorf("UpsertHoliday: Failed to upsert: %w", err)
+	}
+	return stored, created, nil
+}
+
+func (r *HolidayRepositoryPostgres) DeleteHoliday(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM holidays WHERE holiday_id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("DeleteHoliday: Failed to delete: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrHolidayNotFound
+	}
+	return nil
+}
+
+func (r *HolidayRepositoryPostgres) ListSessionsBetween(ctx context.Context, from, to time.Time) ([]uuid.UUID, error) {
+	query := `SELECT session_id FROM trainer_sessions
+			  WHERE date BETWEEN $1 AND $2 AND is_canceled = FALSE AND date + start_time > NOW()
+			  ORDER BY date, start_time`
+
+	rows, err := r.pool.Query(ctx, query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("ListSessionsBetween: Failed to query: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("ListSessionsBetween: Failed to scan: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ListSessionsBetween: %w", err)
+	}
+	return ids, nil
+}
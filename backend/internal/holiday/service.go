@@ -0,0 +1,167 @@
+package holiday
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"t/internal/audit"
+	"t/internal/session"
+	"t/pkg/ical"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HolidayService manages the campus calendar. the holidays themselves are enforced by the availability
+// package: bookings on them are rejected and the session generator skips them
+type HolidayService struct {
+	holidayRepo HolidayRepository
+	sessions    *session.SessionService
+	audit       audit.Recorder
+}
+
+func NewHolidayService(r HolidayRepository, sessionSrv *session.SessionService, auditRec audit.Recorder) *HolidayService {
+	return &HolidayService{
+		holidayRepo: r,
+		sessions:    sessionSrv,
+		audit:       auditRec,
+	}
+}
+
+func (h Holiday) Validate() error {
+	if strings.TrimSpace(h.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidHoliday)
+	}
+	if h.EndDate.Before(h.StartDate) {
+		return fmt.Errorf("%w: end_date is before start_date", ErrInvalidHoliday)
+	}
+	return nil
+}
+
+func (s *HolidayService) ListHolidays(ctx context.Context, from, to time.Time) ([]Holiday, error) {
+	return s.holidayRepo.ListHolidays(ctx, from, to)
+}
+
+// CreateHoliday stores the holiday and cancels the sessions already generated on it, their registered users are
+// notified by the cancellation. bookings on the holiday are left alone
+func (s *HolidayService) CreateHoliday(ctx context.Context, h Holiday) (Result, error) {
+	if err := h.Validate(); err != nil {
+		return Result{}, err
+	}
+	if h.ID == uuid.Nil {
+		h.ID = uuid.New()
+	}
+
+	if err := s.holidayRepo.CreateHoliday(ctx, h); err != nil {
+		return Result{}, err
+	}
+	created, err := s.holidayRepo.GetHoliday(ctx, h.ID)
+	if err != nil {
+		return Result{}, err
+	}
+	s.audit.Record(ctx, "holiday.create", "holiday", created.ID, nil, created)
+
+	canceled, err := s.cancelSessions(ctx, created)
+	return Result{Holiday: created, CanceledSessions: canceled}, err
+}
+
+// Import reads an iCalendar file and creates a holiday from every all-day event, events with a UID imported
+// before are updated instead. timed and recurring events and events without a name are skipped and reported
+func (s *HolidayService) Import(ctx context.Context, r io.Reader, createdBy uuid.UUID) (ImportReport, error) {
+	report := ImportReport{
+		Created:          make([]Holiday, 0),
+		Updated:          make([]Holiday, 0),
+		Skipped:          make([]SkippedEvent, 0),
+		CanceledSessions: make([]uuid.UUID, 0),
+	}
+
+	events, err := ical.Parse(r)
+	if err != nil {
+		return report, err
+	}
+
+	for _, e := range events {
+		if e.Recurring {
+			report.Skipped = append(report.Skipped, SkippedEvent{UID: e.UID, Summary: e.Summary, Reason: "recurring events are not supported"})
+			continue
+		}
+		if !e.AllDay {
+			//an exam from 09:00 to 11:00 must not close the whole day
+			report.Skipped = append(report.Skipped, SkippedEvent{UID: e.UID, Summary: e.Summary, Reason: "only all-day events become holidays"})
+			continue
+		}
+
+		h := Holiday{ID: uuid.New(), Name: e.Summary, StartDate: e.Start, EndDate: e.End, UID: e.UID, CreatedBy: &createdBy}
+		if err := h.Validate(); err != nil {
+			report.Skipped = append(report.Skipped, SkippedEvent{UID: e.UID, Summary: e.Summary, Reason: err.Error()})
+			continue
+		}
+
+		//without a UID the event cannot be recognized on the next import, it is simply created
+		var stored Holiday
+		created := true
+		if h.UID == "" {
+			if err := s.holidayRepo.CreateHoliday(ctx, h); err != nil {
+				return report, err
+			}
+			stored, err = s.holidayRepo.GetHoliday(ctx, h.ID)
+		} else {
+			stored, created, err = s.holidayRepo.UpsertHoliday(ctx, h)
+		}
+		if err != nil {
+			return report, err
+		}
+
+		if created {
+			report.Created = append(report.Created, stored)
+			s.audit.Record(ctx, "holiday.create", "holiday", stored.ID, nil, stored)
+		} else {
+			report.Updated = append(report.Updated, stored)
+			s.audit.Record(ctx, "holiday.update", "holiday", stored.ID, nil, stored)
+		}
+
+		canceled, err := s.cancelSessions(ctx, stored)
+		report.CanceledSessions = append(report.CanceledSessions, canceled...)
+		if err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// cancelSessions cancels the sessions on the holiday that have not started yet
+func (s *HolidayService) cancelSessions(ctx context.Context, h Holiday) ([]uuid.UUID, error) {
+	canceled := make([]uuid.UUID, 0)
+
+	ids, err := s.holidayRepo.ListSessionsBetween(ctx, h.StartDate, h.EndDate)
+	if err != nil {
+		return canceled, err
+	}
+	for _, id := range ids {
+		_, err := s.sessions.CancelSession(ctx, id, "campus holiday: "+h.Name)
+		if errors.Is(err, session.ErrSessionAlreadyCanceled) {
+			continue
+		}
+		if err != nil {
+			return canceled, fmt.Errorf("cancel session %s on holiday %s: %w", id, h.ID, err)
+		}
+		canceled = append(canceled, id)
+	}
+	return canceled, nil
+}
+
+// DeleteHoliday removes the holiday. sessions canceled because of it stay canceled,
+// the generator only creates the sessions that were never generated on the freed dates
+func (s *HolidayService) DeleteHoliday(ctx context.Context, id uuid.UUID) error {
+	before, err := s.holidayRepo.GetHoliday(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.holidayRepo.DeleteHoliday(ctx, id); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "holiday.delete", "holiday", id, before, nil)
+	return nil
+}
@@ -0,0 +1,66 @@
+package mailer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails, swap the implementation to plug in SMTP or a provider API
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// OutboxMailer does not send anything, it writes every message as an .eml file into a local directory and logs it.
+// It is the default so the flows can be tested without an SMTP server.
+type OutboxMailer struct {
+	dir    string
+	from   string
+	logger *zap.Logger
+}
+
+func NewOutboxMailer(dir, from string, logger *zap.Logger) (*OutboxMailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("mailer.NewOutboxMailer: %w", err)
+	}
+	return &OutboxMailer{dir: dir, from: from, logger: logger}, nil
+}
+
+func (m *OutboxMailer) Send(ctx context.Context, msg Message) error {
+	now := time.Now()
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Errorf("OutboxMailer.Send: %w", err)
+	}
+	name := fmt.Sprintf("%s-%s.eml", now.UTC().Format("20060102T150405.000000000"), hex.EncodeToString(suffix))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", m.from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(msg.Body)
+
+	path := filepath.Join(m.dir, name)
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		return fmt.Errorf("OutboxMailer.Send: %w", err)
+	}
+
+	m.logger.Info("mail written to outbox", zap.String("to", msg.To), zap.String("subject", msg.Subject), zap.String("file", path))
+	return nil
+}
@@ -0,0 +1,97 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"t/internal/mailer"
+	"t/internal/push"
+	"t/internal/sms"
+
+	"github.com/google/uuid"
+)
+
+// ChannelName identifies a delivery channel, the inbox itself is not one: every notification lands there
+type ChannelName string
+
+const (
+	ChannelEmail ChannelName = "email"
+	ChannelSMS   ChannelName = "sms"
+	ChannelPush  ChannelName = "push"
+)
+
+// Recipient is the contact data of the user at the time of the delivery
+type Recipient struct {
+	UserID    uuid.UUID
+	FirstName string
+	Email     string
+	Phone     string
+}
+
+// Channel delivers a notification outside the app. returning ErrNoAddress marks the delivery as failed right away,
+// any other error is retried with backoff
+type Channel interface {
+	Name() ChannelName
+	Send(ctx context.Context, to Recipient, n Notification) error
+}
+
+// EmailChannel sends through the mailer, the outbox or SMTP depending on MAIL_PROVIDER
+type EmailChannel struct {
+	mail mailer.Mailer
+}
+
+func NewEmailChannel(mail mailer.Mailer) *EmailChannel {
+	return &EmailChannel{mail: mail}
+}
+
+func (c *EmailChannel) Name() ChannelName { return ChannelEmail }
+
+func (c *EmailChannel) Send(ctx context.Context, to Recipient, n Notification) error {
+	if to.Email == "" {
+		return ErrNoAddress
+	}
+	body := fmt.Sprintf("Hi %s,\n\n%s\n", to.FirstName, n.Body)
+	return c.mail.Send(ctx, mailer.Message{To: to.Email, Subject: n.Title, Body: body})
+}
+
+// SMSChannel sends to users.phone through the sms sender, the outbox or an HTTP gateway depending on SMS_PROVIDER
+type SMSChannel struct {
+	sender sms.Sender
+}
+
+func NewSMSChannel(sender sms.Sender) *SMSChannel {
+	return &SMSChannel{sender: sender}
+}
+
+func (c *SMSChannel) Name() ChannelName { return ChannelSMS }
+
+func (c *SMSChannel) Send(ctx context.Context, to Recipient, n Notification) error {
+	if to.Phone == "" {
+		return ErrNoAddress
+	}
+	text := n.Title
+	if n.Body != "" {
+		text += ": " + n.Body
+	}
+	return c.sender.Send(ctx, sms.Message{To: to.Phone, Text: text})
+}
+
+// PushChannel sends to the devices of the user through the push sender, for now always the outbox
+type PushChannel struct {
+	sender push.Sender
+}
+
+func NewPushChannel(sender push.Sender) *PushChannel {
+	return &PushChannel{sender: sender}
+}
+
+func (c *PushChannel) Name() ChannelName { return ChannelPush }
+
+func (c *PushChannel) Send(ctx context.Context, to Recipient, n Notification) error {
+	return c.sender.Send(ctx, push.Message{
+		UserID:     to.UserID,
+		Title:      n.Title,
+		Body:       n.Body,
+		EntityType: n.EntityType,
+		EntityID:   n.EntityID,
+	})
+}
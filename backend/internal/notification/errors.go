@@ -0,0 +1,10 @@
+package notification
+
+import "errors"
+
+var (
+	ErrNotificationNotFound = errors.New("notification not found")
+	ErrUnknownChannel       = errors.New("unknown notification channel")
+	// ErrNoAddress means the user has no email/phone for the channel, retrying would not help
+	ErrNoAddress = errors.New("user has no address for this channel")
+)
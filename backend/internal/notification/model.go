@@ -0,0 +1,87 @@
+package notification
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Type string
+
+const (
+	TypeBookingCanceled  Type = "booking_canceled"
+	TypeSessionCanceled  Type = "session_canceled"
+	TypeWaitlistPromoted Type = "waitlist_promoted"
+	TypePenaltyGiven     Type = "penalty_given"
+	TypePenaltyRemoved   Type = "penalty_removed"
+	TypeFacilityClosure  Type = "facility_closure"
+)
+
+// Notification is one message in the inbox of a user
+type Notification struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	Type       Type
+	Title      string
+	Body       string
+	EntityType string // booking, session, registration, penalty
+	EntityID   *uuid.UUID
+	ReadAt     *time.Time
+	CreatedAt  time.Time
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending DeliveryStatus = "pending" // waiting for the first or the next attempt
+	DeliverySent    DeliveryStatus = "sent"
+	DeliveryFailed  DeliveryStatus = "failed" // gave up, see LastError
+)
+
+// Delivery is the state of one notification on one channel
+type Delivery struct {
+	ID             uuid.UUID
+	NotificationID uuid.UUID
+	UserID         uuid.UUID
+	Channel        ChannelName
+	Status         DeliveryStatus
+	Attempts       int
+	LastError      string
+	NextAttemptAt  time.Time
+	SentAt         *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// DueDelivery is a pending delivery together with what is needed to send it
+type DueDelivery struct {
+	Delivery
+	Notification Notification
+	Recipient    Recipient
+}
+
+// Preference is whether the user wants the notifications on a channel, Default is true when the user never chose
+type Preference struct {
+	Channel ChannelName
+	Enabled bool
+	Default bool
+}
+
+// DeliveryConfig controls the retries and which channels are on for users who never set a preference
+type DeliveryConfig struct {
+	MaxAttempts  int           // a delivery is failed after this many attempts
+	RetryBackoff time.Duration // wait after the first failed attempt, doubled after every further one
+	MaxBackoff   time.Duration
+	BatchSize    int           // deliveries sent per run of the job
+	ClaimTimeout time.Duration // a claimed delivery whose result was never saved is attempted again after this
+	Defaults     map[ChannelName]bool
+}
+
+// DeliveryReport describes one run of the delivery job
+type DeliveryReport struct {
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Sent       int
+	Retrying   int
+	Failed     int
+}
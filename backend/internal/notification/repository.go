@@ -0,0 +1,247 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type NotificationRepository interface {
+	Create(ctx context.Context, n Notification) (Notification, error)
+	List(ctx context.Context, userID uuid.UUID, unreadOnly bool, offset int) ([]Notification, error)
+	CountUnread(ctx context.Context, userID uuid.UUID) (int, error)
+	MarkRead(ctx context.Context, userID, id uuid.UUID) error
+	MarkAllRead(ctx context.Context, userID uuid.UUID) (int, error)
+
+	// GetPreferences returns only the channels the user chose explicitly
+	GetPreferences(ctx context.Context, userID uuid.UUID) (map[ChannelName]bool, error)
+	SetPreferences(ctx context.Context, userID uuid.UUID, prefs map[ChannelName]bool) error
+
+	BeginTx(ctx context.Context) (pgx.Tx, error)
+	CreateDelivery(ctx context.Context, notificationID, userID uuid.UUID, channel ChannelName) error
+	// ClaimDueDeliveries takes the pending deliveries whose next attempt is due, oldest first, counts the attempt and
+	// moves their next attempt to until, so no other run picks them up while they are being sent
+	ClaimDueDeliveries(ctx context.Context, limit int, until time.Time) ([]DueDelivery, error)
+	SaveDelivery(ctx context.Context, d Delivery) error
+	ListDeliveries(ctx context.Context, userID, notificationID uuid.UUID) ([]Delivery, error)
+}
+
+type NotificationRepositoryPostgres struct {
+	pool *pgxpool.Pool
+}
+
+func NewNotificationRepositoryPostgres(pool *pgxpool.Pool) *NotificationRepositoryPostgres {
+	return &NotificationRepositoryPostgres{pool: pool}
+}
+
+func (r *NotificationRepositoryPostgres) Create(ctx context.Context, n Notification) (Notification, error) {
+	query := `INSERT INTO notifications (user_id, type, title, body, entity_type, entity_id)
+			  VALUES ($1, $2, $3, $4, $5, $6)
+			  RETURNING notification_id, created_at`
+
+	err := r.pool.QueryRow(ctx, query, n.UserID, n.Type, n.Title, n.Body, n.EntityType, n.EntityID).Scan(&n.ID, &n.CreatedAt)
+	if err != nil {
+		return Notification{}, fmt.Errorf("Create: Failed to INSERT: %w", err)
+	}
+	return n, nil
+}
+
+func (r *NotificationRepositoryPostgres) List(ctx context.Context, userID uuid.UUID, unreadOnly bool, offset int) ([]Notification, error) {
+	query := `SELECT notification_id, user_id, type, title, body, entity_type, entity_id, read_at, created_at
+			  FROM notifications
+			  WHERE user_id = $1 AND ($2 = FALSE OR read_at IS NULL)
+			  ORDER BY created_at DESC
+			  OFFSET $3 LIMIT 50`
+
+	rows, err := r.pool.Query(ctx, query, userID, unreadOnly, offset)
+	if err != nil {
+		return nil, fmt.Errorf("List: Failed to SELECT: %w", err)
+	}
+	defer rows.Close()
+
+	resp := make([]Notification, 0)
+	for rows.Next() {
+		var n Notification
+		err := rows.Scan(&n.ID, &n.UserID, &n.Type, &n.Title, &n.Body, &n.EntityType, &n.EntityID, &n.ReadAt, &n.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("List: Failed to SCAN: %w", err)
+		}
+		resp = append(resp, n)
+	}
+	return resp, rows.Err()
+}
+
+func (r *NotificationRepositoryPostgres) CountUnread(ctx context.Context, userID uuid.UUID) (int, error) {
+	query := `SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`
+
+	var count int
+	if err := r.pool.QueryRow(ctx, query, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("CountUnread: Failed to SELECT: %w", err)
+	}
+	return count, nil
+}
+
+// MarkRead marks one notification of the user as read, reading it twice is fine
+func (r *NotificationRepositoryPostgres) MarkRead(ctx context.Context, userID, id uuid.UUID) error {
+	query := `UPDATE notifications SET read_at = COALESCE(read_at, NOW()) WHERE notification_id = $1 AND user_id = $2`
+
+	tag, err := r.pool.Exec(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("MarkRead: Failed to UPDATE: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+func (r *NotificationRepositoryPostgres) MarkAllRead(ctx context.Context, userID uuid.UUID) (int, error) {
+	query := `UPDATE notifications SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL`
+
+	tag, err := r.pool.Exec(ctx, query, userID)
+	if err != nil {
+		return 0, fmt.Errorf("MarkAllRead: Failed to UPDATE: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+func (r *NotificationRepositoryPostgres) GetPreferences(ctx context.Context, userID uuid.UUID) (map[ChannelName]bool, error) {
+	query := `SELECT channel, enabled FROM notification_preferences WHERE user_id = $1`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("GetPreferences: Failed to SELECT: %w", err)
+	}
+	defer rows.Close()
+
+	prefs := make(map[ChannelName]bool)
+	for rows.Next() {
+		var channel ChannelName
+		var enabled bool
+		if err := rows.Scan(&channel, &enabled); err != nil {
+			return nil, fmt.Errorf("GetPreferences: Failed to SCAN: %w", err)
+		}
+		prefs[channel] = enabled
+	}
+	return prefs, rows.Err()
+}
+
+func (r *NotificationRepositoryPostgres) SetPreferences(ctx context.Context, userID uuid.UUID, prefs map[ChannelName]bool) error {
+	tx, err := r.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("SetPreferences: Failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `INSERT INTO notification_preferences (user_id, channel, enabled)
+			  VALUES ($1, $2, $3)
+			  ON CONFLICT (user_id, channel) DO UPDATE SET enabled = EXCLUDED.enabled, updated_at = NOW()`
+
+	for channel, enabled := range prefs {
+		if _, err := tx.Exec(ctx, query, userID, channel, enabled); err != nil {
+			return fmt.Errorf("SetPreferences: Failed to UPSERT: %w", err)
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *NotificationRepositoryPostgres) BeginTx(ctx context.Context) (pgx.Tx, error) {
+	return r.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+}
+
+func (r *NotificationRepositoryPostgres) CreateDelivery(ctx context.Context, notificationID, userID uuid.UUID, channel ChannelName) error {
+	query := `INSERT INTO notification_deliveries (notification_id, user_id, channel) VALUES ($1, $2, $3)`
+
+	if _, err := r.pool.Exec(ctx, query, notificationID, userID, channel); err != nil {
+		return fmt.Errorf("CreateDelivery: Failed to INSERT: %w", err)
+	}
+	return nil
+}
+
+func (r *NotificationRepositoryPostgres) ClaimDueDeliveries(ctx context.Context, limit int, until time.Time) ([]DueDelivery, error) {
+	//one statement, so the row locks are only held while claiming and not while sending
+	query := `WITH claimed AS (
+			      UPDATE notification_deliveries
+			      SET attempts = attempts + 1, next_attempt_at = $2, updated_at = NOW()
+			      WHERE delivery_id IN (
+			          SELECT delivery_id FROM notification_deliveries
+			          WHERE status = 'pending' AND next_attempt_at <= NOW()
+			          ORDER BY next_attempt_at
+			          LIMIT $1
+			          FOR UPDATE SKIP LOCKED)
+			      RETURNING *)
+			  SELECT d.delivery_id, d.notification_id, d.user_id, d.channel, d.status, d.attempts, d.last_error,
+			         d.next_attempt_at, d.sent_at, d.created_at, d.updated_at,
+			         n.type, n.title, n.body, n.entity_type, n.entity_id, n.created_at,
+			         u.first_name, u.email, COALESCE(u.phone, '')
+			  FROM claimed d
+			  JOIN notifications n ON n.notification_id = d.notification_id
+			  JOIN users u ON u.user_id = d.user_id
+			  ORDER BY d.created_at`
+
+	rows, err := r.pool.Query(ctx, query, limit, until)
+	if err != nil {
+		return nil, fmt.Errorf("ClaimDueDeliveries: Failed to UPDATE: %w", err)
+	}
+	defer rows.Close()
+
+	resp := make([]DueDelivery, 0)
+	for rows.Next() {
+		var d DueDelivery
+		n := &d.Notification
+		err := rows.Scan(&d.ID, &d.NotificationID, &d.UserID, &d.Channel, &d.Status, &d.Attempts, &d.LastError,
+			&d.NextAttemptAt, &d.SentAt, &d.CreatedAt, &d.UpdatedAt,
+			&n.Type, &n.Title, &n.Body, &n.EntityType, &n.EntityID, &n.CreatedAt,
+			&d.Recipient.FirstName, &d.Recipient.Email, &d.Recipient.Phone)
+		if err != nil {
+			return nil, fmt.Errorf("ClaimDueDeliveries: Failed to SCAN: %w", err)
+		}
+		n.ID, n.UserID = d.NotificationID, d.UserID
+		d.Recipient.UserID = d.UserID
+		resp = append(resp, d)
+	}
+	return resp, rows.Err()
+}
+
+// SaveDelivery stores the result of an attempt, only while the delivery is still pending
+func (r *NotificationRepositoryPostgres) SaveDelivery(ctx context.Context, d Delivery) error {
+	query := `UPDATE notification_deliveries
+			  SET status = $2, last_error = $3, next_attempt_at = $4, sent_at = $5, updated_at = NOW()
+			  WHERE delivery_id = $1 AND status = 'pending'`
+
+	if _, err := r.pool.Exec(ctx, query, d.ID, d.Status, d.LastError, d.NextAttemptAt, d.SentAt); err != nil {
+		return fmt.Errorf("SaveDelivery: Failed to UPDATE: %w", err)
+	}
+	return nil
+}
+
+// ListDeliveries returns the deliveries of one notification of the user, nothing if the notification is someone else's
+func (r *NotificationRepositoryPostgres) ListDeliveries(ctx context.Context, userID, notificationID uuid.UUID) ([]Delivery, error) {
+	query := `SELECT delivery_id, notification_id, user_id, channel, status, attempts, last_error,
+			         next_attempt_at, sent_at, created_at, updated_at
+			  FROM notification_deliveries
+			  WHERE notification_id = $1 AND user_id = $2
+			  ORDER BY channel`
+
+	rows, err := r.pool.Query(ctx, query, notificationID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ListDeliveries: Failed to SELECT: %w", err)
+	}
+	defer rows.Close()
+
+	resp := make([]Delivery, 0)
+	for rows.Next() {
+		var d Delivery
+		err := rows.Scan(&d.ID, &d.NotificationID, &d.UserID, &d.Channel, &d.Status, &d.Attempts, &d.LastError,
+			&d.NextAttemptAt, &d.SentAt, &d.CreatedAt, &d.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("ListDeliveries: Failed to SCAN: %w", err)
+		}
+		resp = append(resp, d)
+	}
+	return resp, rows.Err()
+}
@@ -0,0 +1,184 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Notifier is what the other services depend on to tell a user about something that happened to them
+type Notifier interface {
+	Notify(ctx context.Context, n Notification)
+}
+
+type NotificationService struct {
+	notificationRepo NotificationRepository
+	channels         []Channel
+	cfg              DeliveryConfig
+	logger           *zap.Logger
+}
+
+func NewNotificationService(r NotificationRepository, channels []Channel, cfg DeliveryConfig, logger *zap.Logger) *NotificationService {
+	return &NotificationService{
+		notificationRepo: r,
+		channels:         channels,
+		cfg:              cfg,
+		logger:           logger,
+	}
+}
+
+// Notify puts the message into the inbox of n.UserID and queues a delivery on every channel the user has enabled,
+// the delivery job sends them. like the audit log it is called after the change is committed,
+// so a failure is logged and not returned
+func (s *NotificationService) Notify(ctx context.Context, n Notification) {
+	ctx = context.WithoutCancel(ctx)
+	log := s.logger.With(zap.Stringer("user_id", n.UserID), zap.String("type", string(n.Type)))
+
+	n, err := s.notificationRepo.Create(ctx, n)
+	if err != nil {
+		log.Error("failed to store the notification", zap.Error(err))
+		return
+	}
+
+	prefs, err := s.GetPreferences(ctx, n.UserID)
+	if err != nil {
+		log.Error("failed to load the notification preferences", zap.Error(err))
+		return
+	}
+	for _, p := range prefs {
+		if !p.Enabled {
+			continue
+		}
+		if err := s.notificationRepo.CreateDelivery(ctx, n.ID, n.UserID, p.Channel); err != nil {
+			log.Error("failed to queue the delivery", zap.String("channel", string(p.Channel)), zap.Error(err))
+		}
+	}
+}
+
+// DeliverPending sends up to BatchSize due deliveries. a failed attempt is retried after RetryBackoff, doubled for every
+// further attempt up to MaxBackoff, and the delivery is failed after MaxAttempts or when the user has no address.
+// the deliveries are claimed first (SKIP LOCKED, so several replicas can run the job at the same time) and the claim is
+// committed before anything is sent; if the job dies while sending, the claim runs out after ClaimTimeout and the
+// delivery is attempted again
+func (s *NotificationService) DeliverPending(ctx context.Context) (DeliveryReport, error) {
+	report := DeliveryReport{StartedAt: time.Now()}
+
+	due, err := s.notificationRepo.ClaimDueDeliveries(ctx, s.cfg.BatchSize, report.StartedAt.Add(s.cfg.ClaimTimeout))
+	if err != nil {
+		return report, err
+	}
+
+	for _, d := range due {
+		err := s.send(ctx, d)
+		now := time.Now()
+
+		switch {
+		case err == nil:
+			d.Status = DeliverySent
+			d.SentAt = &now
+			d.LastError = ""
+			report.Sent++
+		case errors.Is(err, ErrNoAddress) || d.Attempts >= s.cfg.MaxAttempts:
+			d.Status = DeliveryFailed
+			d.LastError = err.Error()
+			report.Failed++
+		default:
+			d.NextAttemptAt = now.Add(s.backoff(d.Attempts))
+			d.LastError = err.Error()
+			report.Retrying++
+		}
+
+		//each result is saved on its own, a failed save only means the delivery is retried when the claim runs out
+		if err := s.notificationRepo.SaveDelivery(ctx, d.Delivery); err != nil {
+			s.logger.Error("failed to save the delivery", zap.Stringer("delivery_id", d.ID), zap.Error(err))
+		}
+	}
+
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+func (s *NotificationService) send(ctx context.Context, d DueDelivery) error {
+	for _, c := range s.channels {
+		if c.Name() == d.Channel {
+			return c.Send(ctx, d.Recipient, d.Notification)
+		}
+	}
+	return ErrUnknownChannel
+}
+
+// backoff is the wait before the next attempt after the given number of failed ones
+func (s *NotificationService) backoff(attempts int) time.Duration {
+	wait := s.cfg.RetryBackoff
+	for i := 1; i < attempts && wait < s.cfg.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if s.cfg.MaxBackoff > 0 && wait > s.cfg.MaxBackoff {
+		wait = s.cfg.MaxBackoff
+	}
+	return wait
+}
+
+// GetPreferences returns every registered channel, with the configured default where the user did not choose
+func (s *NotificationService) GetPreferences(ctx context.Context, userID uuid.UUID) ([]Preference, error) {
+	chosen, err := s.notificationRepo.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]Preference, 0, len(s.channels))
+	for _, c := range s.channels {
+		enabled, ok := chosen[c.Name()]
+		if !ok {
+			enabled = s.cfg.Defaults[c.Name()]
+		}
+		resp = append(resp, Preference{Channel: c.Name(), Enabled: enabled, Default: !ok})
+	}
+	return resp, nil
+}
+
+func (s *NotificationService) SetPreferences(ctx context.Context, userID uuid.UUID, prefs map[ChannelName]bool) ([]Preference, error) {
+	for name := range prefs {
+		if !s.hasChannel(name) {
+			return nil, ErrUnknownChannel
+		}
+	}
+
+	if err := s.notificationRepo.SetPreferences(ctx, userID, prefs); err != nil {
+		return nil, err
+	}
+	return s.GetPreferences(ctx, userID)
+}
+
+func (s *NotificationService) hasChannel(name ChannelName) bool {
+	for _, c := range s.channels {
+		if c.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ListDeliveries returns how the notification of the user was delivered on each channel
+func (s *NotificationService) ListDeliveries(ctx context.Context, userID, notificationID uuid.UUID) ([]Delivery, error) {
+	return s.notificationRepo.ListDeliveries(ctx, userID, notificationID)
+}
+
+func (s *NotificationService) List(ctx context.Context, userID uuid.UUID, unreadOnly bool, offset int) ([]Notification, error) {
+	return s.notificationRepo.List(ctx, userID, unreadOnly, offset)
+}
+
+func (s *NotificationService) CountUnread(ctx context.Context, userID uuid.UUID) (int, error) {
+	return s.notificationRepo.CountUnread(ctx, userID)
+}
+
+func (s *NotificationService) MarkRead(ctx context.Context, userID, id uuid.UUID) error {
+	return s.notificationRepo.MarkRead(ctx, userID, id)
+}
+
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID uuid.UUID) (int, error) {
+	return s.notificationRepo.MarkAllRead(ctx, userID)
+}
@@ -0,0 +1,81 @@
+package penalty
+
+import (
+	"fmt"
+	"t/internal/user"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CancelMode is what happens when the owner cancels a booking or registration after the cutoff
+type CancelMode string
+
+const (
+	CancelModeRefuse  CancelMode = "refuse"  // the cancellation is rejected with ErrCancelCutoffPassed
+	CancelModePenalty CancelMode = "penalty" // the cancellation goes through with an automatic late penalty
+)
+
+// ParseCancelMode checks the configured mode, an unknown one is an error instead of silently behaving like penalty
+func ParseCancelMode(s string) (CancelMode, error) {
+	switch m := CancelMode(s); m {
+	case CancelModeRefuse, CancelModePenalty:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unknown late cancel mode %q, expected %q or %q", s, CancelModeRefuse, CancelModePenalty)
+	}
+}
+
+// CancelOutcome tells the caller how the cancellation was handled
+type CancelOutcome string
+
+const (
+	CancelOnTime        CancelOutcome = "canceled"              // before the cutoff, or canceled by someone else than the owner
+	CancelLatePenalized CancelOutcome = "canceled_late_penalty" // after the cutoff, Penalty holds the late penalty
+	CancelLate          CancelOutcome = "canceled_late"         // after the cutoff but no penalty: 0 points or the slot was already penalized
+)
+
+// CancelPolicy is the self-cancellation rule shared by bookings and session registrations
+type CancelPolicy struct {
+	Cutoff time.Duration // owners cancel freely until this long before the start
+	Mode   CancelMode
+	Points int // points of the late penalty in CancelModePenalty, 0 lets late cancellations through without one
+}
+
+// CancelResult is what a cancellation ended with
+type CancelResult struct {
+	Outcome CancelOutcome
+	Penalty *Penalty
+}
+
+// IsLate reports whether canceling a slot that starts at start is already past the cutoff
+func (p CancelPolicy) IsLate(start, now time.Time) bool {
+	return !now.Before(start.Add(-p.Cutoff))
+}
+
+// Refuse returns ErrCancelCutoffPassed when the late cancellation has to be rejected, nil when it may go through
+func (p CancelPolicy) Refuse() error {
+	if p.Mode == CancelModeRefuse {
+		return fmt.Errorf("%w: cancel at least %s before the start", ErrCancelCutoffPassed, p.Cutoff)
+	}
+	return nil
+}
+
+// LateCancelPenalty is the automatic late penalty for canceling the slot after the cutoff,
+// nil when the policy gives no points. pass uuid.Nil for the session or the booking, whichever it is not
+func (p CancelPolicy) LateCancelPenalty(userID, sessionID, bookingID uuid.UUID, what string, start time.Time) *Penalty {
+	if p.Points <= 0 {
+		return nil
+	}
+	return &Penalty{
+		ID:          uuid.New(),
+		UserID:      userID,
+		GivenByID:   user.SystemUserID,
+		SessionID:   sessionID,
+		BookingID:   bookingID,
+		Reason:      fmt.Sprintf("automatic: canceled the %s on %s less than %s before the start", what, start.Format("2006-01-02 15:04"), p.Cutoff),
+		Points:      p.Points,
+		PenaltyType: "late",
+		IsAutomatic: true,
+	}
+}
@@ -0,0 +1,9 @@
+package penalty
+
+import "errors"
+
+var (
+	ErrPenaltyNotFound = errors.New("penalty not found")
+	// ErrCancelCutoffPassed is returned when the owner cancels after the cutoff and late cancellations are refused
+	ErrCancelCutoffPassed = errors.New("too late to cancel")
+)
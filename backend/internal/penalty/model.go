@@ -17,6 +17,8 @@ type Penalty struct {
 	PenaltyType  string
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
+	RestoredAt   *time.Time // set when the penalty expired and gave the points back
+	IsAutomatic  bool       // issued by the attendance job, not by a person
 	FacilityName string
 	SessionDate  *time.Time
 	BookingDate  *time.Time
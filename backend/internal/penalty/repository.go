@@ -2,7 +2,9 @@ package penalty
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"t/internal/credit"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,7 +14,12 @@ import (
 
 type PenaltyRepository interface {
 	CreatePenalty(ctx context.Context, data Penalty) error
+	// CreatePenaltyTx is CreatePenalty inside the transaction of the caller
+	CreatePenaltyTx(ctx context.Context, tx pgx.Tx, data Penalty) error
+	// AutomaticPenaltyExists tells whether the slot of data already has an automatic penalty of the same type
+	AutomaticPenaltyExists(ctx context.Context, tx pgx.Tx, data Penalty) (bool, error)
 	DeletePenalty(ctx context.Context, id uuid.UUID) error
+	GetPenalty(ctx context.Context, id uuid.UUID) (Penalty, error)
 	ListPenaltyForUser(ctx context.Context, userID uuid.UUID) ([]Penalty, error)
 	ListGivenPenaltyByUser(ctx context.Context, userID uuid.UUID) ([]Penalty, error)
 	ListPenaltiesInterval(ctx context.Context, start_date time.Time, end_date time.Time) ([]Penalty, error)
@@ -30,18 +37,21 @@ func (r *PenaltyRepositoryPostgres) CreatePenalty(ctx context.Context, data Pena
 
 	//should deduct points by the user
 	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable})
+	if err != nil {
+		return fmt.Errorf("CreatePenalty: Failed to Begin: %w", err)
+	}
 	defer tx.Rollback(ctx)
 
-	//first deduct points
-	query := `UPDATE users SET credit_score = credit_score - $1 WHERE user_id=$2`
-
-	_, err = tx.Exec(ctx, query, data.Points, data.UserID)
-	if err != nil {
-		return fmt.Errorf("CreatePenalty: Failed to Deduct Points: %w", err)
+	if err := r.CreatePenaltyTx(ctx, tx, data); err != nil {
+		return err
 	}
 
-	//next, create row in the penalties table
-	query = `INSERT INTO user_penalties (penalty_id, user_id, given_by_id, session_id, booking_id, reason, points, penalty_type) VALUES( $1, $2, $3, $4, $5, $6, $7, $8)`
+	return tx.Commit(ctx)
+}
+
+func (r *PenaltyRepositoryPostgres) CreatePenaltyTx(ctx context.Context, tx pgx.Tx, data Penalty) error {
+	//first create row in the penalties table
+	query := `INSERT INTO user_penalties (penalty_id, user_id, given_by_id, session_id, booking_id, reason, points, penalty_type, is_automatic) VALUES( $1, $2, $3, $4, $5, $6, $7, $8, $9)`
 
 	// Handle nullable UUIDs - convert zero UUID to nil
 	var sessionID interface{} = data.SessionID
@@ -53,30 +63,70 @@ func (r *PenaltyRepositoryPostgres) CreatePenalty(ctx context.Context, data Pena
 		bookingID = nil
 	}
 
-	_, err = tx.Exec(ctx, query, data.ID, data.UserID, data.GivenByID, sessionID, bookingID, data.Reason, data.Points, data.PenaltyType)
+	_, err := tx.Exec(ctx, query, data.ID, data.UserID, data.GivenByID, sessionID, bookingID, data.Reason, data.Points, data.PenaltyType, data.IsAutomatic)
 
 	if err != nil {
 		return fmt.Errorf("CreatePenalty: Failed to INSERT: %w", err)
 	}
 
-	return tx.Commit(ctx)
+	//next, deduct the points, the ledger row points back to the penalty
+	_, err = credit.Apply(ctx, tx, credit.Entry{
+		UserID:    data.UserID,
+		Delta:     -data.Points,
+		Reason:    credit.ReasonPenalty,
+		PenaltyID: &data.ID,
+		SessionID: nilIfZero(data.SessionID),
+		BookingID: nilIfZero(data.BookingID),
+	}, 0)
+	if err != nil {
+		return fmt.Errorf("CreatePenalty: Failed to Deduct Points: %w", err)
+	}
+	return nil
+}
+
+func (r *PenaltyRepositoryPostgres) AutomaticPenaltyExists(ctx context.Context, tx pgx.Tx, data Penalty) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM user_penalties
+			  WHERE is_automatic AND user_id = $1 AND penalty_type = $2
+			    AND (session_id = $3 OR booking_id = $4))`
+
+	var exists bool
+	err := tx.QueryRow(ctx, query, data.UserID, data.PenaltyType, nilIfZero(data.SessionID), nilIfZero(data.BookingID)).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("AutomaticPenaltyExists: Failed to SELECT: %w", err)
+	}
+	return exists, nil
 }
 
 func (r *PenaltyRepositoryPostgres) DeletePenalty(ctx context.Context, id uuid.UUID) error {
 	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable})
+	if err != nil {
+		return fmt.Errorf("DeletePenalty: Failed to Begin :%w", err)
+	}
 	defer tx.Rollback(ctx)
 	var userID uuid.UUID
 	var points int
-	query := `DELETE FROM user_penalties WHERE penalty_id=$1 RETURNING user_id, points`
+	var restoredAt *time.Time
+	query := `DELETE FROM user_penalties WHERE penalty_id=$1 RETURNING user_id, points, restored_at`
 
-	err = tx.QueryRow(ctx, query, id).Scan(&userID, &points)
+	err = tx.QueryRow(ctx, query, id).Scan(&userID, &points, &restoredAt)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrPenaltyNotFound
+		}
 		return fmt.Errorf("DeletePenalty: Failed to DELETE :%w", err)
 	}
 
-	query = `UPDATE users SET credit_score = credit_score+$1 WHERE user_id=$2`
-	_, err = tx.Exec(ctx, query, points, userID)
+	//an expired penalty already gave its points back
+	if restoredAt != nil {
+		return tx.Commit(ctx)
+	}
 
+	_, err = credit.Apply(ctx, tx, credit.Entry{
+		UserID:    userID,
+		Delta:     points,
+		Reason:    credit.ReasonPenaltyRemoved,
+		PenaltyID: &id,
+	}, 0)
 	if err != nil {
 		return fmt.Errorf("DeletePenalty: Failed to UPDATE :%w", err)
 	}
@@ -90,7 +140,7 @@ func (r *PenaltyRepositoryPostgres) ListPenaltyForUser(ctx context.Context, user
 			p.penalty_id, p.user_id, p.given_by_id,
 			COALESCE(p.session_id, '00000000-0000-0000-0000-000000000000'::uuid) AS session_id,
 			COALESCE(p.booking_id, '00000000-0000-0000-0000-000000000000'::uuid) AS booking_id,
-			p.reason, p.points, p.penalty_type, p.created_at, p.updated_at,
+			p.reason, p.points, p.penalty_type, p.created_at, p.updated_at, p.restored_at, p.is_automatic,
 			COALESCE(f_session.name, f_booking.name, '') as facility_name,
 			ts.date as session_date,
 			b.date as booking_date,
@@ -119,7 +169,7 @@ func (r *PenaltyRepositoryPostgres) ListPenaltyForUser(ctx context.Context, user
 		var p Penalty
 		err := rows.Scan(
 			&p.ID, &p.UserID, &p.GivenByID, &p.SessionID, &p.BookingID,
-			&p.Reason, &p.Points, &p.PenaltyType, &p.CreatedAt, &p.UpdatedAt,
+			&p.Reason, &p.Points, &p.PenaltyType, &p.CreatedAt, &p.UpdatedAt, &p.RestoredAt, &p.IsAutomatic,
 			&p.FacilityName, &p.SessionDate, &p.BookingDate, &p.ContextInfo,
 		)
 		if err != nil {
@@ -136,7 +186,7 @@ func (r *PenaltyRepositoryPostgres) ListGivenPenaltyByUser(ctx context.Context,
 			p.penalty_id, p.user_id, p.given_by_id,
 			COALESCE(p.session_id, '00000000-0000-0000-0000-000000000000'::uuid) AS session_id,
 			COALESCE(p.booking_id, '00000000-0000-0000-0000-000000000000'::uuid) AS booking_id,
-			p.reason, p.points, p.penalty_type, p.created_at, p.updated_at,
+			p.reason, p.points, p.penalty_type, p.created_at, p.updated_at, p.restored_at, p.is_automatic,
 			COALESCE(f_session.name, f_booking.name, '') as facility_name,
 			ts.date as session_date,
 			b.date as booking_date,
@@ -167,7 +217,7 @@ func (r *PenaltyRepositoryPostgres) ListGivenPenaltyByUser(ctx context.Context,
 		var p Penalty
 		err := rows.Scan(
 			&p.ID, &p.UserID, &p.GivenByID, &p.SessionID, &p.BookingID,
-			&p.Reason, &p.Points, &p.PenaltyType, &p.CreatedAt, &p.UpdatedAt,
+			&p.Reason, &p.Points, &p.PenaltyType, &p.CreatedAt, &p.UpdatedAt, &p.RestoredAt, &p.IsAutomatic,
 			&p.FacilityName, &p.SessionDate, &p.BookingDate, &p.ContextInfo,
 			&p.UserName,
 		)
@@ -185,7 +235,7 @@ func (r *PenaltyRepositoryPostgres) ListPenaltiesInterval(ctx context.Context, s
 			p.penalty_id, p.user_id, p.given_by_id,
 			COALESCE(p.session_id, '00000000-0000-0000-0000-000000000000'::uuid) AS session_id,
 			COALESCE(p.booking_id, '00000000-0000-0000-0000-000000000000'::uuid) AS booking_id,
-			p.reason, p.points, p.penalty_type, p.created_at, p.updated_at,
+			p.reason, p.points, p.penalty_type, p.created_at, p.updated_at, p.restored_at, p.is_automatic,
 			COALESCE(f_session.name, f_booking.name, '') as facility_name,
 			ts.date as session_date,
 			b.date as booking_date,
@@ -214,7 +264,7 @@ func (r *PenaltyRepositoryPostgres) ListPenaltiesInterval(ctx context.Context, s
 		var p Penalty
 		err := rows.Scan(
 			&p.ID, &p.UserID, &p.GivenByID, &p.SessionID, &p.BookingID,
-			&p.Reason, &p.Points, &p.PenaltyType, &p.CreatedAt, &p.UpdatedAt,
+			&p.Reason, &p.Points, &p.PenaltyType, &p.CreatedAt, &p.UpdatedAt, &p.RestoredAt, &p.IsAutomatic,
 			&p.FacilityName, &p.SessionDate, &p.BookingDate, &p.ContextInfo,
 		)
 		if err != nil {
@@ -224,3 +274,34 @@ func (r *PenaltyRepositoryPostgres) ListPenaltiesInterval(ctx context.Context, s
 	}
 	return resp, nil
 }
+
+func (r *PenaltyRepositoryPostgres) GetPenalty(ctx context.Context, id uuid.UUID) (Penalty, error) {
+	query := `
+		SELECT 
+			penalty_id, user_id, given_by_id,
+			COALESCE(session_id, '00000000-0000-0000-0000-000000000000'::uuid) AS session_id,
+			COALESCE(booking_id, '00000000-0000-0000-0000-000000000000'::uuid) AS booking_id,
+			reason, points, penalty_type, created_at, updated_at, restored_at, is_automatic
+		FROM user_penalties
+		WHERE penalty_id=$1`
+
+	var p Penalty
+	err := r.pool.QueryRow(ctx, query, id).Scan(
+		&p.ID, &p.UserID, &p.GivenByID, &p.SessionID, &p.BookingID,
+		&p.Reason, &p.Points, &p.PenaltyType, &p.CreatedAt, &p.UpdatedAt, &p.RestoredAt, &p.IsAutomatic,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Penalty{}, ErrPenaltyNotFound
+		}
+		return Penalty{}, fmt.Errorf("GetPenalty: Failed to SELECT :%w", err)
+	}
+	return p, nil
+}
+
+func nilIfZero(id uuid.UUID) *uuid.UUID {
+	if id == uuid.Nil {
+		return nil
+	}
+	return &id
+}
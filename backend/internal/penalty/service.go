@@ -2,6 +2,9 @@ package penalty
 
 import (
 	"context"
+	"fmt"
+	"t/internal/audit"
+	"t/internal/notification"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,20 +12,58 @@ import (
 
 type PenaltyService struct {
 	penaltyRepo PenaltyRepository
+	audit       audit.Recorder
+	notifier    notification.Notifier
 }
 
-func NewPenaltyService(r PenaltyRepository) *PenaltyService {
+func NewPenaltyService(r PenaltyRepository, auditRec audit.Recorder, notifier notification.Notifier) *PenaltyService {
 	return &PenaltyService{
 		penaltyRepo: r,
+		audit:       auditRec,
+		notifier:    notifier,
 	}
 }
 
 func (s *PenaltyService) CreatePenalty(ctx context.Context, data Penalty) error {
-	return s.penaltyRepo.CreatePenalty(ctx, data)
+	if err := s.penaltyRepo.CreatePenalty(ctx, data); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "penalty.create", "penalty", data.ID, nil, data)
+	s.notifier.Notify(ctx, GivenNotification(data))
+	return nil
+}
+
+// GivenNotification is the message the penalized user gets, also used for the automatic penalties
+func GivenNotification(p Penalty) notification.Notification {
+	return notification.Notification{
+		UserID:     p.UserID,
+		Type:       notification.TypePenaltyGiven,
+		Title:      fmt.Sprintf("You received a penalty of %d points", p.Points),
+		Body:       p.Reason,
+		EntityType: "penalty",
+		EntityID:   &p.ID,
+	}
 }
 
+// DeletePenalty removes the penalty and gives the points back, unless they were already restored
 func (s *PenaltyService) DeletePenalty(ctx context.Context, id uuid.UUID) error {
-	return s.penaltyRepo.DeletePenalty(ctx, id)
+	before, err := s.penaltyRepo.GetPenalty(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.penaltyRepo.DeletePenalty(ctx, id); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "penalty.delete", "penalty", id, before, nil)
+	s.notifier.Notify(ctx, notification.Notification{
+		UserID:     before.UserID,
+		Type:       notification.TypePenaltyRemoved,
+		Title:      "A penalty was removed",
+		Body:       fmt.Sprintf("The penalty \"%s\" was removed.", before.Reason),
+		EntityType: "penalty",
+		EntityID:   &id,
+	})
+	return nil
 }
 
 func (s *PenaltyService) ListPenaltyForUser(ctx context.Context, userID uuid.UUID) ([]Penalty, error) {
@@ -36,3 +77,7 @@ func (s *PenaltyService) ListGivenPenaltyByUser(ctx context.Context, userID uuid
 func (s *PenaltyService) ListPenaltiesInterval(ctx context.Context, start, end time.Time) ([]Penalty, error) {
 	return s.penaltyRepo.ListPenaltiesInterval(ctx, start, end)
 }
+
+func (s *PenaltyService) GetPenalty(ctx context.Context, id uuid.UUID) (Penalty, error) {
+	return s.penaltyRepo.GetPenalty(ctx, id)
+}
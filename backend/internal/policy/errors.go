@@ -0,0 +1,24 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrPolicyNotFound = errors.New("policy not found")
+	ErrInvalidPolicy  = errors.New("invalid policy")
+)
+
+// Violation explains which rule of which policy rejected a booking
+type Violation struct {
+	Rule   Rule
+	Limit  int
+	Actual int
+	Source EffectiveRule
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("rejected by rule %s of policy %q (%s scope): limit %d, got %d",
+		v.Rule, v.Source.PolicyName, v.Source.Scope, v.Limit, v.Actual)
+}
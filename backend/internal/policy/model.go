@@ -0,0 +1,55 @@
+package policy
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Policy is one row of booking rules. nil FacilityID/Role means the policy applies to every facility/role,
+// nil rule means the rule is inherited from a less specific policy
+type Policy struct {
+	ID                  uuid.UUID
+	Name                string
+	FacilityID          *uuid.UUID
+	Role                *string
+	FacilityType        *string // set on the default rules of a facility type, never stored in booking_policies
+	MaxUpcomingBookings *int
+	MinCreditScore      *int
+	MaxBookingsPerDay   *int
+	MinSlotMinutes      *int
+	MaxSlotMinutes      *int
+	IsActive            bool
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
+
+type Rule string
+
+const (
+	RuleMaxUpcomingBookings Rule = "max_upcoming_bookings"
+	RuleMinCreditScore      Rule = "min_credit_score"
+	RuleMaxBookingsPerDay   Rule = "max_bookings_per_day"
+	RuleMinSlotMinutes      Rule = "min_slot_minutes"
+	RuleMaxSlotMinutes      Rule = "max_slot_minutes"
+)
+
+var Rules = []Rule{RuleMaxUpcomingBookings, RuleMinCreditScore, RuleMaxBookingsPerDay, RuleMinSlotMinutes, RuleMaxSlotMinutes}
+
+// EffectiveRule is the value of a rule after resolution, together with the policy it came from
+type EffectiveRule struct {
+	Value      int
+	PolicyID   uuid.UUID
+	PolicyName string
+	Scope      string
+}
+
+// Effective is the set of rules that apply to one (facility, role) pair
+type Effective struct {
+	Rules map[Rule]EffectiveRule
+}
+
+func (e Effective) Get(rule Rule) (EffectiveRule, bool) {
+	r, ok := e.Rules[rule]
+	return r, ok
+}
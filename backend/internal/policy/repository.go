@@ -0,0 +1,189 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PolicyRepository interface {
+	CreatePolicy(ctx context.Context, p Policy) (uuid.UUID, error)
+	UpdatePolicy(ctx context.Context, p Policy) error
+	DeletePolicy(ctx context.Context, id uuid.UUID) error
+	GetPolicy(ctx context.Context, id uuid.UUID) (Policy, error)
+	ListPolicies(ctx context.Context) ([]Policy, error)
+	// ListApplicable returns the active policies matching the facility and the role, including the global ones
+	ListApplicable(ctx context.Context, tx pgx.Tx, facilityID uuid.UUID, role string) ([]Policy, error)
+}
+
+type PolicyRepositoryPostgres struct {
+	pool *pgxpool.Pool
+}
+
+func NewPolicyRepositoryPostgres(pool *pgxpool.Pool) *PolicyRepositoryPostgres {
+	return &PolicyRepositoryPostgres{pool: pool}
+}
+
+const policyColumns = `policy_id, name, facility_id, role, max_upcoming_bookings, min_credit_score, max_bookings_per_day,
+		min_slot_minutes, max_slot_minutes, is_active, created_at, updated_at`
+
+func scanPolicy(row pgx.Row) (Policy, error) {
+	var p Policy
+	err := row.Scan(&p.ID, &p.Name, &p.FacilityID, &p.Role, &p.MaxUpcomingBookings, &p.MinCreditScore, &p.MaxBookingsPerDay,
+		&p.MinSlotMinutes, &p.MaxSlotMinutes, &p.IsActive, &p.CreatedAt, &p.UpdatedAt)
+	return p, err
+}
+
+func (r *PolicyRepositoryPostgres) CreatePolicy(ctx context.Context, p Policy) (uuid.UUID, error) {
+	query := `INSERT INTO booking_policies (name, facility_id, role, max_upcoming_bookings, min_credit_score, max_bookings_per_day,
+				min_slot_minutes, max_slot_minutes, is_active)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			  RETURNING policy_id`
+
+	var id uuid.UUID
+	err := r.pool.QueryRow(ctx, query, p.Name, p.FacilityID, p.Role, p.MaxUpcomingBookings, p.MinCreditScore, p.MaxBookingsPerDay,
+		p.MinSlotMinutes, p.MaxSlotMinutes, p.IsActive).Scan(&id)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("repository.CreatePolicy: %w", err)
+	}
+	return id, nil
+}
+
+func (r *PolicyRepositoryPostgres) UpdatePolicy(ctx context.Context, p Policy) error {
+	query := `UPDATE booking_policies
+			  SET name=$2, max_upcoming_bookings=$3, min_credit_score=$4, max_bookings_per_day=$5,
+			      min_slot_minutes=$6, max_slot_minutes=$7, is_active=$8, updated_at=NOW()
+			  WHERE policy_id=$1`
+
+	tag, err := r.pool.Exec(ctx, query, p.ID, p.Name, p.MaxUpcomingBookings, p.MinCreditScore, p.MaxBookingsPerDay,
+		p.MinSlotMinutes, p.MaxSlotMinutes, p.IsActive)
+	if err != nil {
+		return fmt.Errorf("repository.UpdatePolicy: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrPolicyNotFound
+	}
+	return nil
+}
+
+func (r *PolicyRepositoryPostgres) DeletePolicy(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM booking_policies WHERE policy_id=$1`, id)
+	if err != nil {
+		return fmt.Errorf("repository.DeletePolicy: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrPolicyNotFound
+	}
+	return nil
+}
+
+func (r *PolicyRepositoryPostgres) GetPolicy(ctx context.Context, id uuid.UUID) (Policy, error) {
+	query := `SELECT ` + policyColumns + ` FROM booking_policies WHERE policy_id=$1`
+
+	p, err := scanPolicy(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Policy{}, ErrPolicyNotFound
+		}
+		return Policy{}, fmt.Errorf("repository.GetPolicy: %w", err)
+	}
+	return p, nil
+}
+
+func (r *PolicyRepositoryPostgres) ListPolicies(ctx context.Context) ([]Policy, error) {
+	query := `SELECT ` + policyColumns + ` FROM booking_policies ORDER BY created_at`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("repository.ListPolicies: %w", err)
+	}
+	defer rows.Close()
+
+	resp := make([]Policy, 0)
+	for rows.Next() {
+		p, err := scanPolicy(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository.ListPolicies scan: %w", err)
+		}
+		resp = append(resp, p)
+	}
+	return resp, rows.Err()
+}
+
+func (r *PolicyRepositoryPostgres) ListApplicable(ctx context.Context, tx pgx.Tx, facilityID uuid.UUID, role string) ([]Policy, error) {
+	query := `SELECT ` + policyColumns + ` FROM booking_policies
+			  WHERE is_active = TRUE
+			    AND (facility_id IS NULL OR facility_id = $1)
+			    AND (role IS NULL OR role::text = $2)`
+
+	var rows pgx.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.Query(ctx, query, facilityID, role)
+	} else {
+		rows, err = r.pool.Query(ctx, query, facilityID, role)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("repository.ListApplicable: %w", err)
+	}
+	defer rows.Close()
+
+	resp := make([]Policy, 0)
+	for rows.Next() {
+		p, err := scanPolicy(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository.ListApplicable scan: %w", err)
+		}
+		resp = append(resp, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository.ListApplicable rows: %w", err)
+	}
+	rows.Close()
+
+	defaults, err := r.typeDefaults(ctx, tx, facilityID)
+	if err != nil {
+		return nil, err
+	}
+	if defaults != nil {
+		resp = append(resp, *defaults)
+	}
+	return resp, nil
+}
+
+// typeDefaults is the default booking rules of the facility's type as a policy, nil when the type sets none
+func (r *PolicyRepositoryPostgres) typeDefaults(ctx context.Context, tx pgx.Tx, facilityID uuid.UUID) (*Policy, error) {
+	query := `SELECT ft.type_id, ft.name, ft.max_upcoming_bookings, ft.min_credit_score, ft.max_bookings_per_day,
+				ft.min_slot_minutes, ft.max_slot_minutes, ft.created_at, ft.updated_at
+			  FROM facility_types ft JOIN facilities f ON f.type = ft.name
+			  WHERE f.facility_id = $1`
+
+	var row pgx.Row
+	if tx != nil {
+		row = tx.QueryRow(ctx, query, facilityID)
+	} else {
+		row = r.pool.QueryRow(ctx, query, facilityID)
+	}
+
+	var p Policy
+	var typeName string
+	err := row.Scan(&p.ID, &typeName, &p.MaxUpcomingBookings, &p.MinCreditScore, &p.MaxBookingsPerDay,
+		&p.MinSlotMinutes, &p.MaxSlotMinutes, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("repository.typeDefaults: %w", err)
+	}
+	if p.MaxUpcomingBookings == nil && p.MinCreditScore == nil && p.MaxBookingsPerDay == nil && p.MinSlotMinutes == nil && p.MaxSlotMinutes == nil {
+		return nil, nil
+	}
+	p.Name = typeName + " defaults"
+	p.FacilityType = &typeName
+	p.IsActive = true
+	return &p, nil
+}
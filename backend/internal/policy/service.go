@@ -0,0 +1,168 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"t/internal/audit"
+
+	"github.com/google/uuid"
+)
+
+type PolicyService struct {
+	policyRepo PolicyRepository
+	audit      audit.Recorder
+}
+
+func NewPolicyService(r PolicyRepository, auditRec audit.Recorder) *PolicyService {
+	return &PolicyService{policyRepo: r, audit: auditRec}
+}
+
+func (s *PolicyService) CreatePolicy(ctx context.Context, p Policy) (uuid.UUID, error) {
+	if err := validate(p); err != nil {
+		return uuid.Nil, err
+	}
+	id, err := s.policyRepo.CreatePolicy(ctx, p)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	p.ID = id
+	s.audit.Record(ctx, "policy.create", "policy", id, nil, p)
+	return id, nil
+}
+
+func (s *PolicyService) UpdatePolicy(ctx context.Context, p Policy) error {
+	if err := validate(p); err != nil {
+		return err
+	}
+	before, err := s.policyRepo.GetPolicy(ctx, p.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.policyRepo.UpdatePolicy(ctx, p); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "policy.update", "policy", p.ID, before, p)
+	return nil
+}
+
+func (s *PolicyService) DeletePolicy(ctx context.Context, id uuid.UUID) error {
+	before, err := s.policyRepo.GetPolicy(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.policyRepo.DeletePolicy(ctx, id); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "policy.delete", "policy", id, before, nil)
+	return nil
+}
+
+func (s *PolicyService) GetPolicy(ctx context.Context, id uuid.UUID) (Policy, error) {
+	return s.policyRepo.GetPolicy(ctx, id)
+}
+
+func (s *PolicyService) ListPolicies(ctx context.Context) ([]Policy, error) {
+	return s.policyRepo.ListPolicies(ctx)
+}
+
+// EffectivePolicy resolves the rules for the facility and role, used to explain to admins what applies
+func (s *PolicyService) EffectivePolicy(ctx context.Context, facilityID uuid.UUID, role string) (Effective, error) {
+	policies, err := s.policyRepo.ListApplicable(ctx, nil, facilityID, role)
+	if err != nil {
+		return Effective{}, err
+	}
+	return Resolve(policies), nil
+}
+
+func validate(p Policy) error {
+	if p.MinSlotMinutes != nil && p.MaxSlotMinutes != nil && *p.MinSlotMinutes > *p.MaxSlotMinutes {
+		return fmt.Errorf("%w: min_slot_minutes is greater than max_slot_minutes", ErrInvalidPolicy)
+	}
+	return nil
+}
+
+// Resolve merges the policies rule by rule, the most specific policy that sets a rule wins:
+// facility+role > facility > role > facility type defaults > global. the type defaults only refine the global policy,
+// a policy written for a role still applies to that role on every facility
+func Resolve(policies []Policy) Effective {
+	sorted := make([]Policy, len(policies))
+	copy(sorted, policies)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].specificity() > sorted[j].specificity()
+	})
+
+	eff := Effective{Rules: make(map[Rule]EffectiveRule)}
+	for _, rule := range Rules {
+		for _, p := range sorted {
+			if v := p.value(rule); v != nil {
+				eff.Rules[rule] = EffectiveRule{Value: *v, PolicyID: p.ID, PolicyName: p.Name, Scope: p.Scope()}
+				break
+			}
+		}
+	}
+	return eff
+}
+
+// CheckMax returns a *Violation when actual is above the rule limit, unset rules are not enforced
+func (e Effective) CheckMax(rule Rule, actual int) error {
+	r, ok := e.Get(rule)
+	if !ok || actual <= r.Value {
+		return nil
+	}
+	return &Violation{Rule: rule, Limit: r.Value, Actual: actual, Source: r}
+}
+
+// CheckMin returns a *Violation when actual is below the rule limit, unset rules are not enforced
+func (e Effective) CheckMin(rule Rule, actual int) error {
+	r, ok := e.Get(rule)
+	if !ok || actual >= r.Value {
+		return nil
+	}
+	return &Violation{Rule: rule, Limit: r.Value, Actual: actual, Source: r}
+}
+
+func (p Policy) Scope() string {
+	switch {
+	case p.FacilityID != nil && p.Role != nil:
+		return "facility+role"
+	case p.FacilityID != nil:
+		return "facility"
+	case p.Role != nil:
+		return "role"
+	case p.FacilityType != nil:
+		return "facility_type"
+	default:
+		return "global"
+	}
+}
+
+func (p Policy) specificity() int {
+	n := 0
+	if p.FacilityID != nil {
+		n += 3
+	}
+	if p.Role != nil {
+		n += 2
+	}
+	if p.FacilityType != nil {
+		n++
+	}
+	return n
+}
+
+func (p Policy) value(rule Rule) *int {
+	switch rule {
+	case RuleMaxUpcomingBookings:
+		return p.MaxUpcomingBookings
+	case RuleMinCreditScore:
+		return p.MinCreditScore
+	case RuleMaxBookingsPerDay:
+		return p.MaxBookingsPerDay
+	case RuleMinSlotMinutes:
+		return p.MinSlotMinutes
+	case RuleMaxSlotMinutes:
+		return p.MaxSlotMinutes
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package policy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func intPtr(v int) *int { return &v }
+
+func strPtr(s string) *string { return &s }
+
+func TestResolve(t *testing.T) {
+	facility := uuid.New()
+	global := Policy{ID: uuid.New(), Name: "global", MaxUpcomingBookings: intPtr(5), MinCreditScore: intPtr(0), MaxBookingsPerDay: intPtr(2)}
+	role := Policy{ID: uuid.New(), Name: "role", Role: strPtr("USER"), MaxUpcomingBookings: intPtr(4), MinCreditScore: intPtr(10)}
+	fac := Policy{ID: uuid.New(), Name: "facility", FacilityID: &facility, MaxUpcomingBookings: intPtr(3), MaxSlotMinutes: intPtr(120)}
+	typeDefaults := Policy{Name: "football", FacilityType: strPtr("football"), MaxBookingsPerDay: intPtr(1), MinSlotMinutes: intPtr(60)}
+	facRole := Policy{ID: uuid.New(), Name: "facility+role", FacilityID: &facility, Role: strPtr("USER"), MaxUpcomingBookings: intPtr(1)}
+
+	type want struct {
+		value int
+		from  string
+	}
+	tests := []struct {
+		name     string
+		policies []Policy
+		want     map[Rule]want
+	}{
+		{
+			name:     "nothing",
+			policies: nil,
+			want:     map[Rule]want{},
+		},
+		{
+			name:     "global only",
+			policies: []Policy{global},
+			want: map[Rule]want{
+				RuleMaxUpcomingBookings: {5, "global"},
+				RuleMinCreditScore:      {0, "global"},
+				RuleMaxBookingsPerDay:   {2, "global"},
+			},
+		},
+		{
+			name:     "role over global",
+			policies: []Policy{global, role},
+			want: map[Rule]want{
+				RuleMaxUpcomingBookings: {4, "role"},
+				RuleMinCreditScore:      {10, "role"},
+				RuleMaxBookingsPerDay:   {2, "global"},
+			},
+		},
+		{
+			name:     "facility over role",
+			policies: []Policy{role, fac, global},
+			want: map[Rule]want{
+				RuleMaxUpcomingBookings: {3, "facility"},
+				RuleMinCreditScore:      {10, "role"},
+				RuleMaxBookingsPerDay:   {2, "global"},
+				RuleMaxSlotMinutes:      {120, "facility"},
+			},
+		},
+		{
+			name:     "facility+role over everything, unset rules inherited",
+			policies: []Policy{facRole, global, fac, role},
+			want: map[Rule]want{
+				RuleMaxUpcomingBookings: {1, "facility+role"},
+				RuleMinCreditScore:      {10, "role"},
+				RuleMaxBookingsPerDay:   {2, "global"},
+				RuleMaxSlotMinutes:      {120, "facility"},
+			},
+		},
+		{
+			name:     "type defaults over global",
+			policies: []Policy{global, typeDefaults},
+			want: map[Rule]want{
+				RuleMaxUpcomingBookings: {5, "global"},
+				RuleMinCreditScore:      {0, "global"},
+				RuleMaxBookingsPerDay:   {1, "football"},
+				RuleMinSlotMinutes:      {60, "football"},
+			},
+		},
+		{
+			name:     "role over type defaults",
+			policies: []Policy{typeDefaults, global, {ID: uuid.New(), Name: "role per day", Role: strPtr("USER"), MaxBookingsPerDay: intPtr(3)}},
+			want: map[Rule]want{
+				RuleMaxUpcomingBookings: {5, "global"},
+				RuleMinCreditScore:      {0, "global"},
+				RuleMaxBookingsPerDay:   {3, "role per day"},
+				RuleMinSlotMinutes:      {60, "football"},
+			},
+		},
+		{
+			name:     "facility over type defaults",
+			policies: []Policy{typeDefaults, {ID: uuid.New(), Name: "facility per day", FacilityID: &facility, MaxBookingsPerDay: intPtr(4)}},
+			want: map[Rule]want{
+				RuleMaxBookingsPerDay: {4, "facility per day"},
+				RuleMinSlotMinutes:    {60, "football"},
+			},
+		},
+		{
+			name:     "zero is a value, not unset",
+			policies: []Policy{global, {ID: uuid.New(), Name: "zero", Role: strPtr("USER"), MaxBookingsPerDay: intPtr(0)}},
+			want: map[Rule]want{
+				RuleMaxUpcomingBookings: {5, "global"},
+				RuleMinCreditScore:      {0, "global"},
+				RuleMaxBookingsPerDay:   {0, "zero"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eff := Resolve(tt.policies)
+			if len(eff.Rules) != len(tt.want) {
+				t.Errorf("got %d rules, want %d: %+v", len(eff.Rules), len(tt.want), eff.Rules)
+			}
+			for rule, w := range tt.want {
+				got, ok := eff.Get(rule)
+				if !ok {
+					t.Errorf("%s is not set", rule)
+					continue
+				}
+				if got.Value != w.value || got.PolicyName != w.from {
+					t.Errorf("%s = %d from %s, want %d from %s", rule, got.Value, got.PolicyName, w.value, w.from)
+				}
+			}
+		})
+	}
+}
+
+func TestResolveScope(t *testing.T) {
+	facility := uuid.New()
+	tests := []struct {
+		policy Policy
+		want   string
+	}{
+		{Policy{FacilityID: &facility, Role: strPtr("USER")}, "facility+role"},
+		{Policy{FacilityID: &facility}, "facility"},
+		{Policy{Role: strPtr("USER")}, "role"},
+		{Policy{FacilityType: strPtr("football")}, "facility_type"},
+		{Policy{}, "global"},
+	}
+	for _, tt := range tests {
+		tt.policy.MaxUpcomingBookings = intPtr(1)
+		eff := Resolve([]Policy{tt.policy})
+		if got := eff.Rules[RuleMaxUpcomingBookings].Scope; got != tt.want {
+			t.Errorf("scope = %s, want %s", got, tt.want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	eff := Resolve([]Policy{{Name: "global", MaxUpcomingBookings: intPtr(3), MinCreditScore: intPtr(50)}})
+
+	if err := eff.CheckMax(RuleMaxUpcomingBookings, 3); err != nil {
+		t.Errorf("at the limit: %v", err)
+	}
+	var v *Violation
+	if err := eff.CheckMax(RuleMaxUpcomingBookings, 4); !errors.As(err, &v) || v.Limit != 3 || v.Actual != 4 {
+		t.Errorf("above the limit: %v", err)
+	}
+	if err := eff.CheckMin(RuleMinCreditScore, 50); err != nil {
+		t.Errorf("at the minimum: %v", err)
+	}
+	if err := eff.CheckMin(RuleMinCreditScore, 49); !errors.As(err, &v) || v.Limit != 50 {
+		t.Errorf("below the minimum: %v", err)
+	}
+	if err := eff.CheckMax(RuleMaxBookingsPerDay, 1000); err != nil {
+		t.Errorf("unset rule: %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package push
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Message is a push notification for every device of the user. the app has no device registration yet,
+// so a real provider has to map the user to its device tokens itself
+type Message struct {
+	UserID     uuid.UUID  `json:"user_id"`
+	Title      string     `json:"title"`
+	Body       string     `json:"body"`
+	EntityType string     `json:"entity_type,omitempty"`
+	EntityID   *uuid.UUID `json:"entity_id,omitempty"`
+}
+
+// Sender sends push notifications, swap the implementation to plug in a provider
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// OutboxSender does not send anything, it writes every message as a .json file into a local directory and logs it.
+// It is the only implementation for now, so the push channel can be tested without a provider.
+type OutboxSender struct {
+	dir    string
+	logger *zap.Logger
+}
+
+func NewOutboxSender(dir string, logger *zap.Logger) (*OutboxSender, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("push.NewOutboxSender: %w", err)
+	}
+	return &OutboxSender{dir: dir, logger: logger}, nil
+}
+
+func (s *OutboxSender) Send(ctx context.Context, msg Message) error {
+	now := time.Now()
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Errorf("OutboxSender.Send: %w", err)
+	}
+	name := fmt.Sprintf("%s-%s.json", now.UTC().Format("20060102T150405.000000000"), hex.EncodeToString(suffix))
+
+	content, err := json.MarshalIndent(msg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("OutboxSender.Send: %w", err)
+	}
+	path := filepath.Join(s.dir, name)
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return fmt.Errorf("OutboxSender.Send: %w", err)
+	}
+
+	s.logger.Info("push written to outbox", zap.Stringer("user_id", msg.UserID), zap.String("file", path))
+	return nil
+}
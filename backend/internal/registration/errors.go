@@ -0,0 +1,10 @@
+package registration
+
+import "errors"
+
+var (
+	ErrAlreadyRegistered    = errors.New("user is already registered for this session")
+	ErrSessionNotAvailable  = errors.New("session does not exist or is canceled")
+	ErrRegistrationNotFound = errors.New("registration not found or already canceled")
+	ErrNotOnWaitlist        = errors.New("user is not on the waitlist of this session")
+)
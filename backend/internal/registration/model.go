@@ -13,8 +13,12 @@ type Registration struct {
 	SessionID  uuid.UUID
 	UserID     uuid.UUID
 	IsCanceled bool
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
-	User       *user.User
-	Session    *session.Session
+	// set when the session was full at the time of registration, the user waits in the queue
+	IsWaitlisted     bool
+	WaitlistedAt     *time.Time
+	WaitlistPosition int // 1-based, 0 when not waitlisted
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	User             *user.User
+	Session          *session.Session
 }
@@ -2,24 +2,36 @@ package registration
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
 	"t/internal/session"
 	"t/internal/user"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type RegistrationRepository interface {
-	CreateRegistration(ctx context.Context, tx pgx.Tx, data Registration) error
-	CheckForFreeSpot(ctx context.Context, tx pgx.Tx, sessionID uuid.UUID) bool
+	CreateRegistration(ctx context.Context, tx pgx.Tx, data Registration) (uuid.UUID, error)
+	// CheckForFreeSpot reports whether the session is open and has fewer registrations than its capacity
+	CheckForFreeSpot(ctx context.Context, tx pgx.Tx, sessionID uuid.UUID) (bool, error)
+	SessionIsOpen(ctx context.Context, tx pgx.Tx, sessionID uuid.UUID) (bool, error)
+	// GetSessionStart returns when the session starts, in the server local time
+	GetSessionStart(ctx context.Context, tx pgx.Tx, sessionID uuid.UUID) (time.Time, error)
 
-	CancelRegistration(ctx context.Context, tx pgx.Tx, registerID uuid.UUID) error
+	CancelRegistration(ctx context.Context, tx pgx.Tx, registerID uuid.UUID) (Registration, error)
+	PromoteFromWaitlist(ctx context.Context, tx pgx.Tx, sessionID uuid.UUID) (*Registration, error)
+	LeaveWaitlist(ctx context.Context, tx pgx.Tx, sessionID, userID uuid.UUID) (bool, error)
+	GetWaitlistPosition(ctx context.Context, tx pgx.Tx, registerID uuid.UUID) (int, error)
 	ListRegistrationsForSession(ctx context.Context, tx pgx.Tx, sessionID uuid.UUID) ([]Registration, error)
 	ListRegistrationsForUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID, offset int) ([]Registration, error)
 	CheckIfUserRegistered(ctx context.Context, tx pgx.Tx, sessionID, userID uuid.UUID) (bool, error)
+	GetRegistration(ctx context.Context, tx pgx.Tx, registerID uuid.UUID) (Registration, error)
+	// ListUpcomingRegistrationIDs returns the active registrations and waitlist entries of the user for sessions that did not happen yet
+	ListUpcomingRegistrationIDs(ctx context.Context, tx pgx.Tx, userID uuid.UUID) ([]uuid.UUID, error)
 	BeginTx(ctx context.Context) (pgx.Tx, error)
 }
 
@@ -64,22 +76,34 @@ func (r *RegistrationRepositoryPostgres) exec(ctx context.Context, tx pgx.Tx, q
 	return err
 }
 
-func (r *RegistrationRepositoryPostgres) CreateRegistration(ctx context.Context, tx pgx.Tx, data Registration) error {
+// CreateRegistration inserts the registration (or the waitlist entry). if the user canceled before, the old row is reused
+// because of UNIQUE(session_id, user_id). returns the id of the row that is now active
+func (r *RegistrationRepositoryPostgres) CreateRegistration(ctx context.Context, tx pgx.Tx, data Registration) (uuid.UUID, error) {
+	query := `INSERT INTO training_session_register (register_id, session_id, user_id, is_waitlisted, waitlisted_at)
+			  VALUES ($1, $2, $3, $4, $5)
+			  ON CONFLICT (session_id, user_id) DO UPDATE
+			  SET is_canceled = FALSE, cancel_reason = NULL, canceled_at = NULL, is_waitlisted = EXCLUDED.is_waitlisted, waitlisted_at = EXCLUDED.waitlisted_at, updated_at = NOW()
+			  WHERE training_session_register.is_canceled = TRUE
+			  RETURNING register_id`
 
-	query := `INSERT INTO training_session_register (register_id, session_id, user_id) VALUES ($1, $2, $3)`
-	err := r.exec(ctx, tx, query, data.ID, data.SessionID, data.UserID)
+	var id uuid.UUID
+	err := r.execRow(ctx, tx, query, data.ID, data.SessionID, data.UserID, data.IsWaitlisted, data.WaitlistedAt).Scan(&id)
 	if err != nil {
-		return fmt.Errorf("CreateRegistration: Failed to INSERT: %w", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, ErrAlreadyRegistered
+		}
+		return uuid.Nil, fmt.Errorf("CreateRegistration: Failed to INSERT: %w", err)
 	}
-	return nil
+	return id, nil
 }
 
-func (r *RegistrationRepositoryPostgres) CheckForFreeSpot(ctx context.Context, tx pgx.Tx, sessionID uuid.UUID) bool {
+func (r *RegistrationRepositoryPostgres) CheckForFreeSpot(ctx context.Context, tx pgx.Tx, sessionID uuid.UUID) (bool, error) {
 	query := `SELECT 1
 		FROM trainer_sessions ts
 		LEFT JOIN training_session_register r
 		    ON ts.session_id = r.session_id
 		    AND r.is_canceled = FALSE
+		    AND r.is_waitlisted = FALSE
 		WHERE ts.session_id = $1
 		  AND ts.is_canceled = FALSE
 		GROUP BY ts.capacity
@@ -87,28 +111,117 @@ func (r *RegistrationRepositoryPostgres) CheckForFreeSpot(ctx context.Context, t
 		LIMIT 1`
 	var ok int
 	err := r.execRow(ctx, tx, query, sessionID).Scan(&ok)
-	if err != nil || ok == 0 {
-		log.Println(err)
-		return false
+	if err != nil {
+		//no row: the session is full (or canceled)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("CheckForFreeSpot: %w", err)
 	}
-	return true
+	return true, nil
 }
 
-func (r *RegistrationRepositoryPostgres) CancelRegistration(ctx context.Context, tx pgx.Tx, registerID uuid.UUID) error {
-	query := `UPDATE training_session_register SET is_canceled=TRUE WHERE register_id=$1`
-	err := r.exec(ctx, tx, query, registerID)
+func (r *RegistrationRepositoryPostgres) SessionIsOpen(ctx context.Context, tx pgx.Tx, sessionID uuid.UUID) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM trainer_sessions WHERE session_id=$1 AND is_canceled=FALSE)`
+	var ok bool
+	err := r.execRow(ctx, tx, query, sessionID).Scan(&ok)
 	if err != nil {
-		return fmt.Errorf("CancelRegistration: Failed to UPDATE: %w", err)
+		return false, fmt.Errorf("SessionIsOpen: %w", err)
 	}
-	return nil
+	return ok, nil
+}
+
+func (r *RegistrationRepositoryPostgres) GetSessionStart(ctx context.Context, tx pgx.Tx, sessionID uuid.UUID) (time.Time, error) {
+	query := `SELECT date, start_time FROM trainer_sessions WHERE session_id=$1`
+	var date, start time.Time
+	err := r.execRow(ctx, tx, query, sessionID).Scan(&date, &start)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, ErrSessionNotAvailable
+		}
+		return time.Time{}, fmt.Errorf("GetSessionStart: %w", err)
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), start.Hour(), start.Minute(), 0, 0, time.Local), nil
+}
+
+// CancelRegistration cancels the active registration (or waitlist entry) and returns it as it was before the cancel
+func (r *RegistrationRepositoryPostgres) CancelRegistration(ctx context.Context, tx pgx.Tx, registerID uuid.UUID) (Registration, error) {
+	query := `UPDATE training_session_register SET is_canceled=TRUE, canceled_at=NOW(), updated_at=NOW()
+			  WHERE register_id=$1 AND is_canceled=FALSE
+			  RETURNING register_id, session_id, user_id, is_waitlisted`
+
+	var reg Registration
+	err := r.execRow(ctx, tx, query, registerID).Scan(&reg.ID, &reg.SessionID, &reg.UserID, &reg.IsWaitlisted)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Registration{}, ErrRegistrationNotFound
+		}
+		return Registration{}, fmt.Errorf("CancelRegistration: Failed to UPDATE: %w", err)
+	}
+	return reg, nil
+}
+
+// PromoteFromWaitlist moves the head of the session waitlist into the registered list, returns nil if the waitlist is empty
+func (r *RegistrationRepositoryPostgres) PromoteFromWaitlist(ctx context.Context, tx pgx.Tx, sessionID uuid.UUID) (*Registration, error) {
+	query := `UPDATE training_session_register SET is_waitlisted=FALSE, waitlisted_at=NULL, updated_at=NOW()
+			  WHERE register_id = (
+			      SELECT register_id FROM training_session_register
+			      WHERE session_id=$1 AND is_waitlisted=TRUE AND is_canceled=FALSE
+			      ORDER BY waitlisted_at
+			      LIMIT 1
+			  )
+			  RETURNING register_id, session_id, user_id`
+
+	var reg Registration
+	err := r.execRow(ctx, tx, query, sessionID).Scan(&reg.ID, &reg.SessionID, &reg.UserID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("PromoteFromWaitlist: Failed to UPDATE: %w", err)
+	}
+	return &reg, nil
+}
+
+func (r *RegistrationRepositoryPostgres) LeaveWaitlist(ctx context.Context, tx pgx.Tx, sessionID, userID uuid.UUID) (bool, error) {
+	query := `UPDATE training_session_register SET is_canceled=TRUE, canceled_at=NOW(), updated_at=NOW()
+			  WHERE session_id=$1 AND user_id=$2 AND is_waitlisted=TRUE AND is_canceled=FALSE`
+
+	var tag pgconn.CommandTag
+	var err error
+	if tx != nil {
+		tag, err = tx.Exec(ctx, query, sessionID, userID)
+	} else {
+		tag, err = r.pool.Exec(ctx, query, sessionID, userID)
+	}
+	if err != nil {
+		return false, fmt.Errorf("LeaveWaitlist: Failed to UPDATE: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *RegistrationRepositoryPostgres) GetWaitlistPosition(ctx context.Context, tx pgx.Tx, registerID uuid.UUID) (int, error) {
+	query := `SELECT COUNT(*) FROM training_session_register w
+			  JOIN training_session_register me ON me.session_id = w.session_id
+			  WHERE me.register_id=$1 AND me.is_waitlisted=TRUE
+			    AND w.is_waitlisted=TRUE AND w.is_canceled=FALSE AND w.waitlisted_at <= me.waitlisted_at`
+	var pos int
+	err := r.execRow(ctx, tx, query, registerID).Scan(&pos)
+	if err != nil {
+		return 0, fmt.Errorf("GetWaitlistPosition: %w", err)
+	}
+	return pos, nil
 }
 
 func (r *RegistrationRepositoryPostgres) ListRegistrationsForSession(ctx context.Context, tx pgx.Tx, sessionID uuid.UUID) ([]Registration, error) {
-	query := `SELECT r.register_id, r.session_id, r.user_id, r.created_at, r.updated_at,
+	// registered users first, then the waitlist in queue order
+	query := `SELECT r.register_id, r.session_id, r.user_id, r.created_at, r.updated_at, r.is_waitlisted, r.waitlisted_at,
+	                 CASE WHEN r.is_waitlisted THEN ROW_NUMBER() OVER (PARTITION BY r.is_waitlisted ORDER BY r.waitlisted_at) ELSE 0 END AS position,
 	                 u.email, u.first_name, u.last_name, u.role, u.phone, u.credit_score, u.is_active, u.created_at, u.updated_at
 	          FROM training_session_register r
 	          JOIN users u ON r.user_id = u.user_id
-	          WHERE r.session_id=$1 AND r.is_canceled=FALSE`
+	          WHERE r.session_id=$1 AND r.is_canceled=FALSE
+	          ORDER BY r.is_waitlisted, r.waitlisted_at, r.created_at`
 	rows, err := r.execRows(ctx, tx, query, sessionID)
 	if err != nil {
 		return []Registration{}, fmt.Errorf("ListRegistrationsForSession: Failed to SELECT: %w", err)
@@ -121,7 +234,7 @@ func (r *RegistrationRepositoryPostgres) ListRegistrationsForSession(ctx context
 		var u user.User
 		// We need to scan user ID into u.ID as well, but it's already in r.UserID
 		// Let's scan user fields
-		err := rows.Scan(&r.ID, &r.SessionID, &r.UserID, &r.CreatedAt, &r.UpdatedAt,
+		err := rows.Scan(&r.ID, &r.SessionID, &r.UserID, &r.CreatedAt, &r.UpdatedAt, &r.IsWaitlisted, &r.WaitlistedAt, &r.WaitlistPosition,
 			&u.Email, &u.FirstName, &u.LastName, &u.Role, &u.Phone, &u.CreditScore, &u.IsActive, &u.CreatedAt, &u.UpdatedAt)
 		if err != nil {
 			return []Registration{}, fmt.Errorf("ListRegistrationsForSession: Failed to SCAN: %w", err)
@@ -134,7 +247,12 @@ func (r *RegistrationRepositoryPostgres) ListRegistrationsForSession(ctx context
 }
 
 func (r *RegistrationRepositoryPostgres) ListRegistrationsForUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID, offset int) ([]Registration, error) {
-	query := `SELECT r.register_id, r.session_id, r.user_id, r.created_at, r.updated_at,
+	query := `SELECT r.register_id, r.session_id, r.user_id, r.created_at, r.updated_at, r.is_waitlisted, r.waitlisted_at,
+	                 CASE WHEN r.is_waitlisted THEN (
+	                     SELECT COUNT(*) FROM training_session_register w
+	                     WHERE w.session_id = r.session_id AND w.is_waitlisted = TRUE AND w.is_canceled = FALSE
+	                       AND w.waitlisted_at <= r.waitlisted_at
+	                 ) ELSE 0 END AS position,
 	                 ts.session_id, ts.schedule_id, ts.trainer_id, ts.facility_id, ts.date, ts.start_time, ts.end_time, ts.capacity, ts.is_canceled
 	          FROM training_session_register r
 	          JOIN trainer_sessions ts ON r.session_id = ts.session_id
@@ -149,7 +267,7 @@ func (r *RegistrationRepositoryPostgres) ListRegistrationsForUser(ctx context.Co
 	for rows.Next() {
 		var r Registration
 		var s session.Session
-		err := rows.Scan(&r.ID, &r.SessionID, &r.UserID, &r.CreatedAt, &r.UpdatedAt,
+		err := rows.Scan(&r.ID, &r.SessionID, &r.UserID, &r.CreatedAt, &r.UpdatedAt, &r.IsWaitlisted, &r.WaitlistedAt, &r.WaitlistPosition,
 			&s.ID, &s.ScheduleID, &s.TrainerID, &s.FacilityID, &s.Date, &s.StartTime, &s.EndTime, &s.Capacity, &s.IsCanceled)
 		if err != nil {
 			return []Registration{}, fmt.Errorf("ListRegistrationsForUser: Failed to SCAN: %w", err)
@@ -169,3 +287,37 @@ func (r *RegistrationRepositoryPostgres) CheckIfUserRegistered(ctx context.Conte
 	}
 	return exists, nil
 }
+
+func (r *RegistrationRepositoryPostgres) GetRegistration(ctx context.Context, tx pgx.Tx, registerID uuid.UUID) (Registration, error) {
+	query := `SELECT register_id, session_id, user_id, is_canceled, is_waitlisted, waitlisted_at, created_at, updated_at
+			  FROM training_session_register WHERE register_id=$1`
+
+	var reg Registration
+	err := r.execRow(ctx, tx, query, registerID).Scan(&reg.ID, &reg.SessionID, &reg.UserID, &reg.IsCanceled, &reg.IsWaitlisted, &reg.WaitlistedAt, &reg.CreatedAt, &reg.UpdatedAt)
+	if err != nil {
+		return Registration{}, fmt.Errorf("GetRegistration: %w", err)
+	}
+	return reg, nil
+}
+
+func (r *RegistrationRepositoryPostgres) ListUpcomingRegistrationIDs(ctx context.Context, tx pgx.Tx, userID uuid.UUID) ([]uuid.UUID, error) {
+	query := `SELECT r.register_id FROM training_session_register r
+			  JOIN trainer_sessions s ON s.session_id = r.session_id
+			  WHERE r.user_id = $1 AND r.is_canceled = FALSE AND s.date >= CURRENT_DATE`
+
+	rows, err := r.execRows(ctx, tx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ListUpcomingRegistrationIDs: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("ListUpcomingRegistrationIDs: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
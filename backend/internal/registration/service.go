@@ -2,54 +2,208 @@ package registration
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"t/internal/audit"
+	"t/internal/notification"
+	"t/internal/penalty"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 type RegistrationService struct {
 	registerRepo RegistrationRepository
+	penaltyRepo  penalty.PenaltyRepository
+	cancelPolicy penalty.CancelPolicy
+	audit        audit.Recorder
+	notifier     notification.Notifier
 }
 
-func NewRegistrationService(registerRepo RegistrationRepository) *RegistrationService {
-	return &RegistrationService{registerRepo: registerRepo}
+func NewRegistrationService(registerRepo RegistrationRepository, penaltyRepo penalty.PenaltyRepository, cancelPolicy penalty.CancelPolicy, auditRec audit.Recorder, notifier notification.Notifier) *RegistrationService {
+	return &RegistrationService{registerRepo: registerRepo, penaltyRepo: penaltyRepo, cancelPolicy: cancelPolicy, audit: auditRec, notifier: notifier}
 }
 
-func (s *RegistrationService) CreateRegistration(ctx context.Context, data Registration) error {
+// CreateRegistration registers the user for the session, if the session is full the user is put on the waitlist instead.
+// the returned registration tells which one happened
+func (s *RegistrationService) CreateRegistration(ctx context.Context, data Registration) (Registration, error) {
 	//create transaction
 	tx, err := s.registerRepo.BeginTx(ctx)
 	if err != nil {
-		return fmt.Errorf("CreateRegistration: Failed to Create Transaction: %w", err)
+		return Registration{}, fmt.Errorf("CreateRegistration: Failed to Create Transaction: %w", err)
 	}
 	defer tx.Rollback(ctx)
 
-	//check if there are free slots
-	ok := s.registerRepo.CheckForFreeSpot(ctx, tx, data.SessionID)
-	if !ok {
-		return fmt.Errorf("There is not free spots")
+	open, err := s.registerRepo.SessionIsOpen(ctx, tx, data.SessionID)
+	if err != nil {
+		return Registration{}, err
+	}
+	if !open {
+		return Registration{}, ErrSessionNotAvailable
+	}
+
+	registered, err := s.registerRepo.CheckIfUserRegistered(ctx, tx, data.SessionID, data.UserID)
+	if err != nil {
+		return Registration{}, err
+	}
+	if registered {
+		return Registration{}, ErrAlreadyRegistered
 	}
+
+	//check if there are free slots, if not the user goes to the waitlist
+	free, err := s.registerRepo.CheckForFreeSpot(ctx, tx, data.SessionID)
+	if err != nil {
+		return Registration{}, err
+	}
+	if !free {
+		now := time.Now()
+		data.IsWaitlisted = true
+		data.WaitlistedAt = &now
+	}
+
 	//create the registration
-	err = s.registerRepo.CreateRegistration(ctx, tx, data)
+	id, err := s.registerRepo.CreateRegistration(ctx, tx, data)
 	if err != nil {
-		return err
+		return Registration{}, err
+	}
+	data.ID = id
+
+	if data.IsWaitlisted {
+		data.WaitlistPosition, err = s.registerRepo.GetWaitlistPosition(ctx, tx, id)
+		if err != nil {
+			return Registration{}, err
+		}
 	}
 
-	return tx.Commit(ctx)
+	if err := tx.Commit(ctx); err != nil {
+		return Registration{}, fmt.Errorf("CreateRegistration: Failed to Commit: %w", err)
+	}
+	s.audit.Record(ctx, "registration.create", "registration", data.ID, nil, data)
+	return data, nil
 }
 
-func (s *RegistrationService) CancelRegistration(ctx context.Context, id uuid.UUID) error {
+// CancelRegistration cancels the registration (or waitlist entry) and promotes the head of the waitlist into the freed spot
+// in the same transaction, the promoted registration is returned (or nil).
+// when actorID is the owner the cancel policy applies to registrations that hold a spot: after the cutoff the cancellation
+// is refused or goes through with a late penalty, the result tells which. leaving the waitlist is always free
+func (s *RegistrationService) CancelRegistration(ctx context.Context, id, actorID uuid.UUID) (penalty.CancelResult, *Registration, error) {
 	tx, err := s.registerRepo.BeginTx(ctx)
 	if err != nil {
-		return fmt.Errorf("CancelRegistration: Failed to Create Transaction: %w", err)
+		return penalty.CancelResult{Outcome: penalty.CancelOnTime}, nil, fmt.Errorf("CancelRegistration: Failed to Create Transaction: %w", err)
 	}
 	defer tx.Rollback(ctx)
 
-	err = s.registerRepo.CancelRegistration(ctx, tx, id)
+	c, err := s.cancelTx(ctx, tx, id, actorID)
 	if err != nil {
-		return err
+		return c.result, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return c.result, nil, fmt.Errorf("CancelRegistration: Failed to Commit: %w", err)
+	}
+	s.announce(ctx, c)
+	return c.result, c.promoted, nil
+}
+
+// Cancellations are registrations canceled inside a transaction the registration service does not own,
+// Announce them once that transaction committed
+type Cancellations struct {
+	items []cancellation
+}
+
+func (c Cancellations) Len() int { return len(c.items) }
+
+// cancellation is what one cancel changed, announce records and notifies it after the commit
+type cancellation struct {
+	id       uuid.UUID
+	canceled Registration
+	result   penalty.CancelResult
+	promoted *Registration
+}
+
+func (s *RegistrationService) cancelTx(ctx context.Context, tx pgx.Tx, id, actorID uuid.UUID) (cancellation, error) {
+	c := cancellation{id: id, result: penalty.CancelResult{Outcome: penalty.CancelOnTime}}
+
+	canceled, err := s.registerRepo.CancelRegistration(ctx, tx, id)
+	if err != nil {
+		return c, err
+	}
+	c.canceled = canceled
+
+	if actorID == canceled.UserID && !canceled.IsWaitlisted {
+		start, err := s.registerRepo.GetSessionStart(ctx, tx, canceled.SessionID)
+		if err != nil {
+			return c, err
+		}
+		if s.cancelPolicy.IsLate(start, time.Now()) {
+			if err := s.cancelPolicy.Refuse(); err != nil {
+				return c, err
+			}
+			c.result.Outcome = penalty.CancelLate
+
+			p := s.cancelPolicy.LateCancelPenalty(canceled.UserID, canceled.SessionID, uuid.Nil, "session", start)
+			if p != nil {
+				exists, err := s.penaltyRepo.AutomaticPenaltyExists(ctx, tx, *p)
+				if err != nil {
+					return c, err
+				}
+				if !exists {
+					if err := s.penaltyRepo.CreatePenaltyTx(ctx, tx, *p); err != nil {
+						return c, err
+					}
+					c.result.Outcome = penalty.CancelLatePenalized
+					c.result.Penalty = p
+				}
+			}
+		}
+	}
+
+	if !canceled.IsWaitlisted {
+		free, err := s.registerRepo.CheckForFreeSpot(ctx, tx, canceled.SessionID)
+		if err != nil {
+			return c, err
+		}
+		if free {
+			c.promoted, err = s.registerRepo.PromoteFromWaitlist(ctx, tx, canceled.SessionID)
+			if err != nil {
+				return c, err
+			}
+		}
+	}
+	return c, nil
+}
+
+func (s *RegistrationService) announce(ctx context.Context, c cancellation) {
+	s.audit.Record(ctx, "registration.cancel", "registration", c.id, nil, c.canceled)
+	if c.result.Penalty != nil {
+		s.audit.Record(ctx, "penalty.create", "penalty", c.result.Penalty.ID, nil, c.result.Penalty)
+		s.notifier.Notify(ctx, penalty.GivenNotification(*c.result.Penalty))
 	}
+	if c.promoted != nil {
+		s.audit.Record(ctx, "registration.promote", "registration", c.promoted.ID, nil, c.promoted)
+		s.notifier.Notify(ctx, notification.Notification{
+			UserID:     c.promoted.UserID,
+			Type:       notification.TypeWaitlistPromoted,
+			Title:      "You got a spot",
+			Body:       "A spot opened up and you were moved from the waitlist to the registered list.",
+			EntityType: "registration",
+			EntityID:   &c.promoted.ID,
+		})
+	}
+}
 
-	return tx.Commit(ctx)
+// LeaveWaitlist removes the user from the waitlist of the session, nobody is promoted because no spot was freed
+func (s *RegistrationService) LeaveWaitlist(ctx context.Context, sessionID, userID uuid.UUID) error {
+	ok, err := s.registerRepo.LeaveWaitlist(ctx, nil, sessionID, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotOnWaitlist
+	}
+	s.audit.Record(ctx, "registration.leave_waitlist", "session", sessionID, nil, map[string]uuid.UUID{"UserID": userID})
+	return nil
 }
 
 func (s *RegistrationService) ListRegistrationsForSession(ctx context.Context, sessionID uuid.UUID) ([]Registration, error) {
@@ -61,3 +215,38 @@ func (s *RegistrationService) ListRegistrationsForSession(ctx context.Context, s
 func (s *RegistrationService) ListRegistrationsForUser(ctx context.Context, userID uuid.UUID, offset int) ([]Registration, error) {
 	return s.registerRepo.ListRegistrationsForUser(ctx, nil, userID, offset)
 }
+
+func (s *RegistrationService) GetRegistration(ctx context.Context, id uuid.UUID) (Registration, error) {
+	return s.registerRepo.GetRegistration(ctx, nil, id)
+}
+
+// CancelUpcomingForUser cancels every upcoming registration of the user inside tx, one by one so the waitlists move up.
+// it is part of the erasure: the account is going away, so the cancel policy does not apply, and nothing is announced
+// before the caller committed and passed the result to Announce
+func (s *RegistrationService) CancelUpcomingForUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID) (Cancellations, error) {
+	var result Cancellations
+
+	ids, err := s.registerRepo.ListUpcomingRegistrationIDs(ctx, tx, userID)
+	if err != nil {
+		return result, err
+	}
+
+	for _, id := range ids {
+		c, err := s.cancelTx(ctx, tx, id, uuid.Nil)
+		if errors.Is(err, ErrRegistrationNotFound) {
+			continue
+		}
+		if err != nil {
+			return result, fmt.Errorf("CancelUpcomingForUser: %w", err)
+		}
+		result.items = append(result.items, c)
+	}
+	return result, nil
+}
+
+// Announce records and notifies the cancellations, call it only after their transaction committed
+func (s *RegistrationService) Announce(ctx context.Context, c Cancellations) {
+	for _, item := range c.items {
+		s.announce(ctx, item)
+	}
+}
@@ -0,0 +1,5 @@
+package review
+
+import "errors"
+
+var ErrReviewNotFound = errors.New("review not found")
@@ -2,9 +2,11 @@ package review
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -13,6 +15,7 @@ type ReviewRepository interface {
 	DeleteFacilityReview(ctx context.Context, id uuid.UUID) error
 	GetFacilityRating(ctx context.Context, id uuid.UUID) (float64, error)
 	GetFacilityReviews(ctx context.Context, id uuid.UUID, offset int) ([]FacilityReview, error)
+	GetFacilityReview(ctx context.Context, id uuid.UUID) (FacilityReview, error)
 }
 
 type ReviewRepositoryPostgres struct {
@@ -26,9 +29,9 @@ func NewReviewRepositoryPostgres(pool *pgxpool.Pool) *ReviewRepositoryPostgres {
 }
 
 func (r *ReviewRepositoryPostgres) CreateFacilityReview(ctx context.Context, facilRew FacilityReview) error {
-	query := `INSERT INTO facility_review (facility_id, user_id, comment, rating, updated_at, created_at) VALUES( $1, $2, $3, $4, NOW(), NOW())`
+	query := `INSERT INTO facility_review (review_id, facility_id, user_id, comment, rating, updated_at, created_at) VALUES($1, $2, $3, $4, $5, NOW(), NOW())`
 
-	_, err := r.pool.Exec(ctx, query, facilRew.FacilityID, facilRew.UserID, facilRew.Comment, facilRew.Rating)
+	_, err := r.pool.Exec(ctx, query, facilRew.ID, facilRew.FacilityID, facilRew.UserID, facilRew.Comment, facilRew.Rating)
 	if err != nil {
 		return fmt.Errorf("repository.CreateFacilityReview : %w", err)
 	}
@@ -38,10 +41,13 @@ func (r *ReviewRepositoryPostgres) CreateFacilityReview(ctx context.Context, fac
 func (r *ReviewRepositoryPostgres) DeleteFacilityReview(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM facility_review WHERE review_id=$1`
 
-	_, err := r.pool.Exec(ctx, query, id)
+	tag, err := r.pool.Exec(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("repository.DeleteFacilityReview : %w", err)
 	}
+	if tag.RowsAffected() == 0 {
+		return ErrReviewNotFound
+	}
 	return nil
 }
 
@@ -75,3 +81,17 @@ func (r *ReviewRepositoryPostgres) GetFacilityReviews(ctx context.Context, id uu
 	}
 	return resp, nil
 }
+
+func (r *ReviewRepositoryPostgres) GetFacilityReview(ctx context.Context, id uuid.UUID) (FacilityReview, error) {
+	query := `SELECT review_id, facility_id, user_id, comment, rating, created_at, updated_at FROM facility_review WHERE review_id=$1`
+
+	var i FacilityReview
+	err := r.pool.QueryRow(ctx, query, id).Scan(&i.ID, &i.FacilityID, &i.UserID, &i.Comment, &i.Rating, &i.CreatedAt, &i.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return FacilityReview{}, ErrReviewNotFound
+		}
+		return FacilityReview{}, fmt.Errorf("repository.GetFacilityReview : %w", err)
+	}
+	return i, nil
+}
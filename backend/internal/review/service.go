@@ -2,26 +2,44 @@ package review
 
 import (
 	"context"
+	"t/internal/audit"
 
 	"github.com/google/uuid"
 )
 
 type ReviewService struct {
 	reviewRepo ReviewRepository
+	audit      audit.Recorder
 }
 
-func NewReviewService(rep ReviewRepository) *ReviewService {
+func NewReviewService(rep ReviewRepository, auditRec audit.Recorder) *ReviewService {
 	return &ReviewService{
 		reviewRepo: rep,
+		audit:      auditRec,
 	}
 }
 
 func (s *ReviewService) CreateFacilityReview(ctx context.Context, f FacilityReview) error {
-	return s.reviewRepo.CreateFacilityReview(ctx, f)
+	if f.ID == uuid.Nil {
+		f.ID = uuid.New()
+	}
+	if err := s.reviewRepo.CreateFacilityReview(ctx, f); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "review.create", "review", f.ID, nil, f)
+	return nil
 }
 
 func (s *ReviewService) DeleteFacilityReview(ctx context.Context, id uuid.UUID) error {
-	return s.reviewRepo.DeleteFacilityReview(ctx, id)
+	before, err := s.reviewRepo.GetFacilityReview(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.reviewRepo.DeleteFacilityReview(ctx, id); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "review.delete", "review", id, before, nil)
+	return nil
 }
 
 func (s *ReviewService) GetFacilityRating(ctx context.Context, id uuid.UUID) (float64, error) {
@@ -31,3 +49,7 @@ func (s *ReviewService) GetFacilityRating(ctx context.Context, id uuid.UUID) (fl
 func (s *ReviewService) GetFacilityReviews(ctx context.Context, id uuid.UUID, offset int) ([]FacilityReview, error) {
 	return s.reviewRepo.GetFacilityReviews(ctx, id, offset)
 }
+
+func (s *ReviewService) GetFacilityReview(ctx context.Context, id uuid.UUID) (FacilityReview, error) {
+	return s.reviewRepo.GetFacilityReview(ctx, id)
+}
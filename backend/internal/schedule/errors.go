@@ -0,0 +1,8 @@
+package schedule
+
+import "errors"
+
+var (
+	ErrScheduleNotFound = errors.New("schedule not found")
+	ErrScheduleOverlap  = errors.New("trainer cannot have overlapping trainings")
+)
@@ -1,6 +1,7 @@
 package schedule
 
 import (
+	"t/internal/availability"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,3 +19,13 @@ type Schedule struct {
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
 }
+
+// ConflictReport is what creating a schedule collides with. a schedule of another trainer at the same weekly time
+// always stops it, the dated bookings and sessions only unless the trainer asked to skip them: the session generator
+// leaves those dates out
+type ConflictReport struct {
+	Schedule  Schedule
+	Created   bool
+	Schedules []Schedule
+	Occupied  []availability.Busy
+}
@@ -2,6 +2,7 @@ package schedule
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
@@ -10,10 +11,15 @@ import (
 )
 
 type ScheduleRepository interface {
-	CreateTrainingScehdule(ctx context.Context, data Schedule) error
+	BeginTx(ctx context.Context) (pgx.Tx, error)
+	TrainerHasOverlap(ctx context.Context, tx pgx.Tx, data Schedule) (bool, error)
+	// ListFacilityOverlaps returns the active schedules of other trainers holding the facility at the same weekly time
+	ListFacilityOverlaps(ctx context.Context, tx pgx.Tx, data Schedule) ([]Schedule, error)
+	CreateTrainingScehdule(ctx context.Context, tx pgx.Tx, data Schedule) error
 	DeleteTrainingSchedule(ctx context.Context, id uuid.UUID) error
 	ListSchedulesForTrainer(ctx context.Context, trainerID uuid.UUID) ([]Schedule, error)
 	ListSchedulesForFacility(ctx context.Context, facilityID uuid.UUID) ([]Schedule, error)
+	GetSchedule(ctx context.Context, id uuid.UUID) (Schedule, error)
 }
 
 type ScheduleRepositoryPostgres struct {
@@ -26,35 +32,56 @@ func NewScheduleRepositoryPostgres(p *pgxpool.Pool) *ScheduleRepositoryPostgres
 	}
 }
 
-func (r *ScheduleRepositoryPostgres) CreateTrainingScehdule(ctx context.Context, data Schedule) error {
-	//craete transaction
-	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable})
-	if err != nil {
-		return fmt.Errorf("CreateTrainingScehdule: Failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback(ctx)
+func (r *ScheduleRepositoryPostgres) BeginTx(ctx context.Context) (pgx.Tx, error) {
+	return r.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable})
+}
 
+func (r *ScheduleRepositoryPostgres) TrainerHasOverlap(ctx context.Context, tx pgx.Tx, data Schedule) (bool, error) {
 	// Check for overlaps: (StartA < EndB) AND (EndA > StartB)
 	query := `SELECT COUNT(*) FROM trainer_weekly_schedule 
 			  WHERE trainer_id=$1 AND weekday=$2 
 			  AND start_time < $3 AND end_time > $4`
 	var count int
-	err = tx.QueryRow(ctx, query, data.TrainerID, data.WeekDay, data.EndTime, data.StartTime).Scan(&count)
+	err := tx.QueryRow(ctx, query, data.TrainerID, data.WeekDay, data.EndTime, data.StartTime).Scan(&count)
 	if err != nil {
-		return fmt.Errorf("CreateTrainingScehdule: Failed to query: %w", err)
+		return false, fmt.Errorf("TrainerHasOverlap: Failed to query: %w", err)
 	}
-	if count > 0 {
-		return fmt.Errorf("Trainer cannot have overlapping trainings")
+	return count > 0, nil
+}
+
+func (r *ScheduleRepositoryPostgres) ListFacilityOverlaps(ctx context.Context, tx pgx.Tx, data Schedule) ([]Schedule, error) {
+	query := `SELECT schedule_id, trainer_id, facility_id, weekday, start_time, end_time, capacity, is_active, created_at, updated_at
+			  FROM trainer_weekly_schedule
+			  WHERE facility_id=$1 AND weekday=$2 AND is_active = TRUE
+			  AND start_time < $3 AND end_time > $4
+			  ORDER BY start_time`
+
+	rows, err := tx.Query(ctx, query, data.FacilityID, data.WeekDay, data.EndTime, data.StartTime)
+	if err != nil {
+		return nil, fmt.Errorf("ListFacilityOverlaps: Failed to query: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := make([]Schedule, 0)
+	for rows.Next() {
+		var s Schedule
+		err := rows.Scan(&s.ID, &s.TrainerID, &s.FacilityID, &s.WeekDay, &s.StartTime, &s.EndTime, &s.Capacity, &s.IsActive, &s.CreatedAt, &s.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("ListFacilityOverlaps: Failed to scan: %w", err)
+		}
+		schedules = append(schedules, s)
 	}
+	return schedules, rows.Err()
+}
 
-	query = `INSERT INTO trainer_weekly_schedule ( schedule_id,trainer_id, facility_id, weekday, start_time, end_time, capacity) VALUES ($1, $2, $3, $4, $5, $6, $7)`
+func (r *ScheduleRepositoryPostgres) CreateTrainingScehdule(ctx context.Context, tx pgx.Tx, data Schedule) error {
+	query := `INSERT INTO trainer_weekly_schedule ( schedule_id,trainer_id, facility_id, weekday, start_time, end_time, capacity) VALUES ($1, $2, $3, $4, $5, $6, $7)`
 
-	_, err = tx.Exec(ctx, query, data.ID, data.TrainerID, data.FacilityID, data.WeekDay, data.StartTime, data.EndTime, data.Capacity)
+	_, err := tx.Exec(ctx, query, data.ID, data.TrainerID, data.FacilityID, data.WeekDay, data.StartTime, data.EndTime, data.Capacity)
 	if err != nil {
 		return fmt.Errorf("CreateTrainingScehdule: Failed to Insert: %w", err)
 	}
-
-	return tx.Commit(ctx)
+	return nil
 }
 
 func (r *ScheduleRepositoryPostgres) DeleteTrainingSchedule(ctx context.Context, id uuid.UUID) error {
@@ -123,3 +150,18 @@ func (r *ScheduleRepositoryPostgres) ListSchedulesForFacility(ctx context.Contex
 	}
 	return schedules, nil
 }
+
+func (r *ScheduleRepositoryPostgres) GetSchedule(ctx context.Context, id uuid.UUID) (Schedule, error) {
+	query := `SELECT schedule_id, trainer_id, facility_id, weekday, start_time, end_time, capacity, is_active, created_at, updated_at 
+			  FROM trainer_weekly_schedule WHERE schedule_id=$1`
+
+	var s Schedule
+	err := r.pool.QueryRow(ctx, query, id).Scan(&s.ID, &s.TrainerID, &s.FacilityID, &s.WeekDay, &s.StartTime, &s.EndTime, &s.Capacity, &s.IsActive, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Schedule{}, ErrScheduleNotFound
+		}
+		return Schedule{}, fmt.Errorf("GetSchedule: Failed to scan: %w", err)
+	}
+	return s, nil
+}
@@ -2,26 +2,91 @@ package schedule
 
 import (
 	"context"
+	"fmt"
+	"t/internal/audit"
+	"t/internal/availability"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type ScheduleService struct {
 	scheduleRepo ScheduleRepository
+	occupancy    availability.Occupancy
+	audit        audit.Recorder
 }
 
-func NewScheduleService(r ScheduleRepository) *ScheduleService {
+func NewScheduleService(r ScheduleRepository, occupancy availability.Occupancy, auditRec audit.Recorder) *ScheduleService {
 	return &ScheduleService{
 		scheduleRepo: r,
+		occupancy:    occupancy,
+		audit:        auditRec,
 	}
 }
 
-func (s *ScheduleService) CreateTrainingScehdule(ctx context.Context, data Schedule) error {
-	return s.scheduleRepo.CreateTrainingScehdule(ctx, data)
+// CreateTrainingScehdule creates the weekly schedule unless it collides on the facility. the report lists the
+// schedules, bookings and sessions in the way; with skipConflicts the dated ones do not stop the schedule
+func (s *ScheduleService) CreateTrainingScehdule(ctx context.Context, data Schedule, skipConflicts bool) (ConflictReport, error) {
+	report := ConflictReport{Schedule: data, Schedules: make([]Schedule, 0), Occupied: make([]availability.Busy, 0)}
+
+	tx, err := s.scheduleRepo.BeginTx(ctx)
+	if err != nil {
+		return report, fmt.Errorf("CreateTrainingScehdule: Failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := s.occupancy.LockFacility(ctx, tx, data.FacilityID); err != nil {
+		return report, err
+	}
+
+	overlap, err := s.scheduleRepo.TrainerHasOverlap(ctx, tx, data)
+	if err != nil {
+		return report, err
+	}
+	if overlap {
+		return report, ErrScheduleOverlap
+	}
+
+	report.Schedules, err = s.scheduleRepo.ListFacilityOverlaps(ctx, tx, data)
+	if err != nil {
+		return report, err
+	}
+	report.Occupied, err = s.occupancy.WeeklyConflicts(ctx, tx, availability.WeeklyClaim{
+		FacilityID: data.FacilityID,
+		WeekDay:    data.WeekDay,
+		Interval:   availability.ClockInterval(data.StartTime, data.EndTime),
+		From:       today(),
+		ScheduleID: data.ID,
+	})
+	if err != nil {
+		return report, err
+	}
+	if len(report.Schedules) > 0 || (len(report.Occupied) > 0 && !skipConflicts) {
+		return report, nil
+	}
+
+	if err := s.scheduleRepo.CreateTrainingScehdule(ctx, tx, data); err != nil {
+		return report, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return report, fmt.Errorf("CreateTrainingScehdule: Failed to commit: %w", err)
+	}
+	report.Created = true
+
+	s.audit.Record(ctx, "schedule.create", "schedule", data.ID, nil, data)
+	return report, nil
 }
 
 func (s *ScheduleService) DeleteTrainingSchedule(ctx context.Context, id uuid.UUID) error {
-	return s.scheduleRepo.DeleteTrainingSchedule(ctx, id)
+	before, err := s.scheduleRepo.GetSchedule(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.scheduleRepo.DeleteTrainingSchedule(ctx, id); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "schedule.delete", "schedule", id, before, nil)
+	return nil
 }
 
 func (s *ScheduleService) ListSchedulesForTrainer(ctx context.Context, trainerID uuid.UUID) ([]Schedule, error) {
@@ -31,3 +96,12 @@ func (s *ScheduleService) ListSchedulesForTrainer(ctx context.Context, trainerID
 func (s *ScheduleService) ListSchedulesForFacility(ctx context.Context, facilityID uuid.UUID) ([]Schedule, error) {
 	return s.scheduleRepo.ListSchedulesForFacility(ctx, facilityID)
 }
+
+func (s *ScheduleService) GetSchedule(ctx context.Context, id uuid.UUID) (Schedule, error) {
+	return s.scheduleRepo.GetSchedule(ctx, id)
+}
+
+func today() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,75 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Job is a unit of background work that is run periodically
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Scheduler runs the registered jobs in-process, every job gets its own goroutine and ticker.
+// jobs are expected to be safe to run on several replicas at once (advisory locks, idempotent inserts)
+type Scheduler struct {
+	jobs   []Job
+	logger *zap.Logger
+}
+
+func New(logger *zap.Logger) *Scheduler {
+	return &Scheduler{logger: logger}
+}
+
+// Register adds the job, a zero or negative interval turns it off instead of panicking in the ticker
+func (s *Scheduler) Register(job Job) {
+	if job.Interval <= 0 {
+		s.logger.Warn("job disabled, the interval is not positive", zap.String("job", job.Name), zap.Duration("interval", job.Interval))
+		return
+	}
+	s.jobs = append(s.jobs, job)
+}
+
+// Start launches all jobs and returns immediately, jobs stop when ctx is canceled
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		go s.loop(ctx, job)
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	//run once on the startup, so we dont wait the whole interval after deploy
+	s.runOnce(ctx, job)
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("job stopped", zap.String("job", job.Name))
+			return
+		case <-ticker.C:
+			s.runOnce(ctx, job)
+		}
+	}
+}
+
+func (s *Scheduler) runOnce(ctx context.Context, job Job) {
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("job panicked", zap.String("job", job.Name), zap.Any("panic", r))
+		}
+	}()
+
+	if err := job.Run(ctx); err != nil {
+		s.logger.Error("job failed", zap.String("job", job.Name), zap.Error(err), zap.Duration("took", time.Since(start)))
+		return
+	}
+	s.logger.Debug("job finished", zap.String("job", job.Name), zap.Duration("took", time.Since(start)))
+}
@@ -0,0 +1,38 @@
+package scheduler
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestRegisterSkipsNonPositiveIntervals(t *testing.T) {
+	s := New(zap.NewNop())
+	var runs atomic.Int32
+	run := func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	}
+
+	s.Register(Job{Name: "zero", Interval: 0, Run: run})
+	s.Register(Job{Name: "negative", Interval: -time.Minute, Run: run})
+	s.Register(Job{Name: "hourly", Interval: time.Hour, Run: run})
+	if len(s.jobs) != 1 || s.jobs[0].Name != "hourly" {
+		t.Fatalf("registered %v, want only hourly", s.jobs)
+	}
+
+	//the remaining job runs once on the start
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.Start(ctx)
+	deadline := time.Now().Add(time.Second)
+	for runs.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := runs.Load(); got != 1 {
+		t.Errorf("runs = %d, want 1", got)
+	}
+}
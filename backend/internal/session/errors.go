@@ -0,0 +1,8 @@
+package session
+
+import "errors"
+
+var (
+	ErrSessionNotFound        = errors.New("session not found")
+	ErrSessionAlreadyCanceled = errors.New("session already canceled")
+)
@@ -1,6 +1,7 @@
 package session
 
 import (
+	"t/internal/availability"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,7 +17,52 @@ type Session struct {
 	EndTime         time.Time
 	Capacity        int
 	IsCanceled      bool
+	CancelReason    string
+	CanceledAt      *time.Time
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
 	RegisteredCount int
 }
+
+// Refund is a penalty whose points were given back because its session was canceled
+type Refund struct {
+	PenaltyID uuid.UUID
+	UserID    uuid.UUID
+	Points    int
+}
+
+// Cancellation is what canceling a session did, AffectedUsers are everyone whose registration or waitlist entry
+// was canceled with it
+type Cancellation struct {
+	SessionID             uuid.UUID
+	Reason                string
+	AffectedUsers         []uuid.UUID
+	RegistrationsCanceled int
+	WaitlistCanceled      int
+	Refunds               []Refund
+}
+
+// GeneratedSession is one session that was materialized by the generator
+type GeneratedSession struct {
+	SessionID  uuid.UUID
+	ScheduleID uuid.UUID
+	Date       time.Time
+}
+
+// GenerationReport describes one run of the session generator
+type GenerationReport struct {
+	StartedAt        time.Time
+	FinishedAt       time.Time
+	HorizonWeeks     int
+	LockAcquired     bool // false when another replica was generating at the same time
+	SchedulesScanned int
+	Created          []GeneratedSession
+	Skipped          []SkippedSession
+}
+
+// SkippedSession is a date the generator left out because bookings or other sessions occupy the facility
+type SkippedSession struct {
+	ScheduleID uuid.UUID
+	Date       time.Time
+	Conflicts  []availability.Busy
+}
@@ -2,20 +2,37 @@ package session
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"t/internal/credit"
+	"t/internal/schedule"
+	"t/internal/user"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type SessionRepository interface {
-	CreateSession(ctx context.Context, data Session) error
-	DeleteSession(ctx context.Context, id uuid.UUID) error
-	CancelSession(ctx context.Context, id uuid.UUID) error
+	CreateSession(ctx context.Context, tx pgx.Tx, data Session) error
 	ListFacilitySessions(ctx context.Context, facilityID uuid.UUID, date time.Time) ([]Session, error)
 	ListTrainerSessions(ctx context.Context, trainerID uuid.UUID, date time.Time) ([]Session, error)
 	GetSession(ctx context.Context, id uuid.UUID) (*Session, error)
+
+	// GetSessionForUpdate is GetSession locking the row until the end of tx
+	GetSessionForUpdate(ctx context.Context, tx pgx.Tx, id uuid.UUID) (*Session, error)
+	HasRegistrations(ctx context.Context, tx pgx.Tx, id uuid.UUID) (bool, error)
+	DeleteSession(ctx context.Context, tx pgx.Tx, id uuid.UUID) error
+	// CancelSession cancels the session and every active registration and waitlist entry with the reason,
+	// and gives back the points of the penalties given for it
+	CancelSession(ctx context.Context, tx pgx.Tx, id uuid.UUID, reason string) (Cancellation, error)
+	// ListRegisteredUsers returns the users with an active registration or waitlist entry for the session
+	ListRegisteredUsers(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error)
+
+	ListActiveSchedules(ctx context.Context, tx pgx.Tx) ([]schedule.Schedule, error)
+	CreateSessionIfMissing(ctx context.Context, tx pgx.Tx, data Session) (bool, error)
+	BeginTx(ctx context.Context) (pgx.Tx, error)
 }
 
 type SessionRepositoryPostgres struct {
@@ -28,10 +45,14 @@ func NewSessionRepositoryPostgres(p *pgxpool.Pool) *SessionRepositoryPostgres {
 	}
 }
 
-func (r *SessionRepositoryPostgres) CreateSession(ctx context.Context, data Session) error {
+func (r *SessionRepositoryPostgres) BeginTx(ctx context.Context) (pgx.Tx, error) {
+	return r.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+}
+
+func (r *SessionRepositoryPostgres) CreateSession(ctx context.Context, tx pgx.Tx, data Session) error {
 	query := `INSERT INTO trainer_sessions (session_id, schedule_id, trainer_id, facility_id, date, start_time, end_time, capacity, is_canceled) VALUES ($1, $2,$3,$4,$5,$6,$7,$8,$9)`
 
-	_, err := r.pool.Query(ctx, query, data.ID, data.ScheduleID, data.TrainerID, data.FacilityID, data.Date, data.StartTime, data.EndTime, data.Capacity, data.IsCanceled)
+	_, err := tx.Exec(ctx, query, data.ID, data.ScheduleID, data.TrainerID, data.FacilityID, data.Date, data.StartTime, data.EndTime, data.Capacity, data.IsCanceled)
 	if err != nil {
 		return fmt.Errorf("CreateSession: Failed to INSERT: %w", err)
 	}
@@ -39,28 +60,104 @@ func (r *SessionRepositoryPostgres) CreateSession(ctx context.Context, data Sess
 	return nil
 }
 
-func (r *SessionRepositoryPostgres) DeleteSession(ctx context.Context, id uuid.UUID) error {
+// DeleteSession removes the session, only possible when nobody ever registered for it
+func (r *SessionRepositoryPostgres) DeleteSession(ctx context.Context, tx pgx.Tx, id uuid.UUID) error {
 	query := `DELETE FROM trainer_sessions WHERE session_id=$1`
-	_, err := r.pool.Exec(ctx, query, id)
+	_, err := tx.Exec(ctx, query, id)
 	if err != nil {
-		return fmt.Errorf("DeleteSession: Failed to INSERT: %w", err)
+		return fmt.Errorf("DeleteSession: Failed to DELETE: %w", err)
 	}
 
 	return nil
 }
 
-func (r *SessionRepositoryPostgres) CancelSession(ctx context.Context, id uuid.UUID) error {
-	query := `UPDATE trainer_sessions SET is_canceled=TRUE WHERE session_id=$1`
-	_, err := r.pool.Exec(ctx, query, id)
+func (r *SessionRepositoryPostgres) HasRegistrations(ctx context.Context, tx pgx.Tx, id uuid.UUID) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM training_session_register WHERE session_id = $1)`
+
+	var exists bool
+	if err := tx.QueryRow(ctx, query, id).Scan(&exists); err != nil {
+		return false, fmt.Errorf("HasRegistrations: Failed to SELECT: %w", err)
+	}
+	return exists, nil
+}
+
+func (r *SessionRepositoryPostgres) CancelSession(ctx context.Context, tx pgx.Tx, id uuid.UUID, reason string) (Cancellation, error) {
+	c := Cancellation{SessionID: id, Reason: reason, AffectedUsers: make([]uuid.UUID, 0), Refunds: make([]Refund, 0)}
+
+	query := `UPDATE trainer_sessions SET is_canceled = TRUE, cancel_reason = $2, canceled_at = NOW(), updated_at = NOW()
+			  WHERE session_id = $1`
+	if _, err := tx.Exec(ctx, query, id, reason); err != nil {
+		return c, fmt.Errorf("CancelSession: Failed to UPDATE session: %w", err)
+	}
+
+	query = `UPDATE training_session_register
+			 SET is_canceled = TRUE, cancel_reason = $2, canceled_at = NOW(), updated_at = NOW()
+			 WHERE session_id = $1 AND is_canceled = FALSE
+			 RETURNING user_id, is_waitlisted`
+
+	rows, err := tx.Query(ctx, query, id, reason)
 	if err != nil {
-		return fmt.Errorf("CancelSession: Failed to Update: %w", err)
+		return c, fmt.Errorf("CancelSession: Failed to UPDATE registrations: %w", err)
 	}
-	return nil
+	for rows.Next() {
+		var userID uuid.UUID
+		var waitlisted bool
+		if err := rows.Scan(&userID, &waitlisted); err != nil {
+			rows.Close()
+			return c, fmt.Errorf("CancelSession: Failed to SCAN registration: %w", err)
+		}
+		c.AffectedUsers = append(c.AffectedUsers, userID)
+		if waitlisted {
+			c.WaitlistCanceled++
+		} else {
+			c.RegistrationsCanceled++
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return c, fmt.Errorf("CancelSession: Failed to UPDATE registrations: %w", err)
+	}
+
+	//penalties for a session that did not take place give their points back, the row stays for the history
+	query = `UPDATE user_penalties SET restored_at = NOW(), updated_at = NOW()
+			 WHERE session_id = $1 AND restored_at IS NULL AND points > 0 AND user_id <> $2
+			 RETURNING penalty_id, user_id, points`
+
+	rows, err = tx.Query(ctx, query, id, user.DeletedUserID)
+	if err != nil {
+		return c, fmt.Errorf("CancelSession: Failed to UPDATE penalties: %w", err)
+	}
+	for rows.Next() {
+		var rf Refund
+		if err := rows.Scan(&rf.PenaltyID, &rf.UserID, &rf.Points); err != nil {
+			rows.Close()
+			return c, fmt.Errorf("CancelSession: Failed to SCAN penalty: %w", err)
+		}
+		c.Refunds = append(c.Refunds, rf)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return c, fmt.Errorf("CancelSession: Failed to UPDATE penalties: %w", err)
+	}
+
+	for _, rf := range c.Refunds {
+		_, err := credit.Apply(ctx, tx, credit.Entry{
+			UserID:    rf.UserID,
+			Delta:     rf.Points,
+			Reason:    credit.ReasonSessionCanceled,
+			PenaltyID: &rf.PenaltyID,
+			SessionID: &id,
+		}, 0)
+		if err != nil {
+			return c, fmt.Errorf("CancelSession: Failed to refund penalty: %w", err)
+		}
+	}
+	return c, nil
 }
 
 func (r *SessionRepositoryPostgres) ListFacilitySessions(ctx context.Context, facilityID uuid.UUID, date time.Time) ([]Session, error) {
-	query := `SELECT ts.session_id, ts.schedule_id, ts.trainer_id, ts.facility_id, ts.date, ts.start_time, ts.end_time, ts.capacity, ts.is_canceled,
-			  (SELECT COUNT(*) FROM training_session_register r WHERE r.session_id = ts.session_id AND r.is_canceled = FALSE) as registered_count
+	query := `SELECT ts.session_id, ts.schedule_id, ts.trainer_id, ts.facility_id, ts.date, ts.start_time, ts.end_time, ts.capacity, ts.is_canceled, COALESCE(ts.cancel_reason, ''), ts.canceled_at,
+			  (SELECT COUNT(*) FROM training_session_register r WHERE r.session_id = ts.session_id AND r.is_canceled = FALSE AND r.is_waitlisted = FALSE) as registered_count
 			  FROM trainer_sessions ts WHERE ts.facility_id=$1 AND ts.date=$2 ORDER BY ts.start_time`
 
 	rows, err := r.pool.Query(ctx, query, facilityID, date)
@@ -72,7 +169,7 @@ func (r *SessionRepositoryPostgres) ListFacilitySessions(ctx context.Context, fa
 	var sessions []Session
 	for rows.Next() {
 		var s Session
-		err := rows.Scan(&s.ID, &s.ScheduleID, &s.TrainerID, &s.FacilityID, &s.Date, &s.StartTime, &s.EndTime, &s.Capacity, &s.IsCanceled, &s.RegisteredCount)
+		err := rows.Scan(&s.ID, &s.ScheduleID, &s.TrainerID, &s.FacilityID, &s.Date, &s.StartTime, &s.EndTime, &s.Capacity, &s.IsCanceled, &s.CancelReason, &s.CanceledAt, &s.RegisteredCount)
 		if err != nil {
 			return nil, fmt.Errorf("ListFacilitySessions: Failed to scan: %w", err)
 		}
@@ -82,8 +179,8 @@ func (r *SessionRepositoryPostgres) ListFacilitySessions(ctx context.Context, fa
 }
 
 func (r *SessionRepositoryPostgres) ListTrainerSessions(ctx context.Context, trainerID uuid.UUID, date time.Time) ([]Session, error) {
-	query := `SELECT ts.session_id, ts.schedule_id, ts.trainer_id, ts.facility_id, ts.date, ts.start_time, ts.end_time, ts.capacity, ts.is_canceled,
-			  (SELECT COUNT(*) FROM training_session_register r WHERE r.session_id = ts.session_id AND r.is_canceled = FALSE) as registered_count
+	query := `SELECT ts.session_id, ts.schedule_id, ts.trainer_id, ts.facility_id, ts.date, ts.start_time, ts.end_time, ts.capacity, ts.is_canceled, COALESCE(ts.cancel_reason, ''), ts.canceled_at,
+			  (SELECT COUNT(*) FROM training_session_register r WHERE r.session_id = ts.session_id AND r.is_canceled = FALSE AND r.is_waitlisted = FALSE) as registered_count
 			  FROM trainer_sessions ts WHERE ts.trainer_id=$1 AND ts.date=$2 ORDER BY ts.start_time`
 
 	rows, err := r.pool.Query(ctx, query, trainerID, date)
@@ -95,7 +192,7 @@ func (r *SessionRepositoryPostgres) ListTrainerSessions(ctx context.Context, tra
 	var sessions []Session
 	for rows.Next() {
 		var s Session
-		err := rows.Scan(&s.ID, &s.ScheduleID, &s.TrainerID, &s.FacilityID, &s.Date, &s.StartTime, &s.EndTime, &s.Capacity, &s.IsCanceled, &s.RegisteredCount)
+		err := rows.Scan(&s.ID, &s.ScheduleID, &s.TrainerID, &s.FacilityID, &s.Date, &s.StartTime, &s.EndTime, &s.Capacity, &s.IsCanceled, &s.CancelReason, &s.CanceledAt, &s.RegisteredCount)
 		if err != nil {
 			return nil, fmt.Errorf("ListTrainerSessions: Failed to scan: %w", err)
 		}
@@ -105,14 +202,92 @@ func (r *SessionRepositoryPostgres) ListTrainerSessions(ctx context.Context, tra
 }
 
 func (r *SessionRepositoryPostgres) GetSession(ctx context.Context, id uuid.UUID) (*Session, error) {
-	query := `SELECT ts.session_id, ts.schedule_id, ts.trainer_id, ts.facility_id, ts.date, ts.start_time, ts.end_time, ts.capacity, ts.is_canceled,
-			  (SELECT COUNT(*) FROM training_session_register r WHERE r.session_id = ts.session_id AND r.is_canceled = FALSE) as registered_count
+	query := `SELECT ts.session_id, ts.schedule_id, ts.trainer_id, ts.facility_id, ts.date, ts.start_time, ts.end_time, ts.capacity, ts.is_canceled, COALESCE(ts.cancel_reason, ''), ts.canceled_at,
+			  (SELECT COUNT(*) FROM training_session_register r WHERE r.session_id = ts.session_id AND r.is_canceled = FALSE AND r.is_waitlisted = FALSE) as registered_count
 			  FROM trainer_sessions ts WHERE ts.session_id=$1`
 
 	var s Session
-	err := r.pool.QueryRow(ctx, query, id).Scan(&s.ID, &s.ScheduleID, &s.TrainerID, &s.FacilityID, &s.Date, &s.StartTime, &s.EndTime, &s.Capacity, &s.IsCanceled, &s.RegisteredCount)
+	err := r.pool.QueryRow(ctx, query, id).Scan(&s.ID, &s.ScheduleID, &s.TrainerID, &s.FacilityID, &s.Date, &s.StartTime, &s.EndTime, &s.Capacity, &s.IsCanceled, &s.CancelReason, &s.CanceledAt, &s.RegisteredCount)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrSessionNotFound
+		}
 		return nil, fmt.Errorf("GetSession: Failed to scan: %w", err)
 	}
 	return &s, nil
 }
+
+func (r *SessionRepositoryPostgres) GetSessionForUpdate(ctx context.Context, tx pgx.Tx, id uuid.UUID) (*Session, error) {
+	query := `SELECT ts.session_id, ts.schedule_id, ts.trainer_id, ts.facility_id, ts.date, ts.start_time, ts.end_time, ts.capacity, ts.is_canceled, COALESCE(ts.cancel_reason, ''), ts.canceled_at,
+			  (SELECT COUNT(*) FROM training_session_register r WHERE r.session_id = ts.session_id AND r.is_canceled = FALSE AND r.is_waitlisted = FALSE) as registered_count
+			  FROM trainer_sessions ts WHERE ts.session_id=$1
+			  FOR UPDATE OF ts`
+
+	var s Session
+	err := tx.QueryRow(ctx, query, id).Scan(&s.ID, &s.ScheduleID, &s.TrainerID, &s.FacilityID, &s.Date, &s.StartTime, &s.EndTime, &s.Capacity, &s.IsCanceled, &s.CancelReason, &s.CanceledAt, &s.RegisteredCount)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("GetSessionForUpdate: Failed to scan: %w", err)
+	}
+	return &s, nil
+}
+
+func (r *SessionRepositoryPostgres) ListActiveSchedules(ctx context.Context, tx pgx.Tx) ([]schedule.Schedule, error) {
+	query := `SELECT schedule_id, trainer_id, facility_id, weekday, start_time, end_time, capacity, is_active, created_at, updated_at
+			  FROM trainer_weekly_schedule WHERE is_active = TRUE`
+
+	rows, err := tx.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("ListActiveSchedules: Failed to query: %w", err)
+	}
+	defer rows.Close()
+
+	var schedules []schedule.Schedule
+	for rows.Next() {
+		var s schedule.Schedule
+		err := rows.Scan(&s.ID, &s.TrainerID, &s.FacilityID, &s.WeekDay, &s.StartTime, &s.EndTime, &s.Capacity, &s.IsActive, &s.CreatedAt, &s.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("ListActiveSchedules: Failed to scan: %w", err)
+		}
+		schedules = append(schedules, s)
+	}
+	return schedules, rows.Err()
+}
+
+// CreateSessionIfMissing relies on UNIQUE (schedule_id, date), returns false if the session already existed
+func (r *SessionRepositoryPostgres) CreateSessionIfMissing(ctx context.Context, tx pgx.Tx, data Session) (bool, error) {
+	query := `INSERT INTO trainer_sessions (session_id, schedule_id, trainer_id, facility_id, date, start_time, end_time, capacity, is_canceled)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			  ON CONFLICT (schedule_id, date) DO NOTHING`
+
+	tag, err := tx.Exec(ctx, query, data.ID, data.ScheduleID, data.TrainerID, data.FacilityID, data.Date, data.StartTime, data.EndTime, data.Capacity, data.IsCanceled)
+	if err != nil {
+		return false, fmt.Errorf("CreateSessionIfMissing: Failed to INSERT: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *SessionRepositoryPostgres) ListRegisteredUsers(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	query := `SELECT user_id FROM training_session_register WHERE session_id = $1 AND is_canceled = FALSE`
+
+	rows, err := r.pool.Query(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("ListRegisteredUsers: Failed to query: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var userID uuid.UUID
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("ListRegisteredUsers: Failed to scan: %w", err)
+		}
+		users = append(users, userID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ListRegisteredUsers: %w", err)
+	}
+	return users, nil
+}
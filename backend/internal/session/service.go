@@ -2,32 +2,170 @@ package session
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"t/internal/audit"
+	"t/internal/availability"
+	"t/internal/notification"
 	"t/internal/schedule"
+	pg "t/pkg/postgres"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
+// key of the advisory lock, so only one replica generates sessions at a time
+const generationLockKey int64 = 0x5E5510
+
 type SessionService struct {
-	sessionRepo SessionRepository
+	sessionRepo  SessionRepository
+	occupancy    availability.Occupancy
+	horizonWeeks int
+	audit        audit.Recorder
+	notifier     notification.Notifier
 }
 
-func NewSessionService(r SessionRepository) *SessionService {
+func NewSessionService(r SessionRepository, occupancy availability.Occupancy, horizonWeeks int, auditRec audit.Recorder, notifier notification.Notifier) *SessionService {
 	return &SessionService{
-		sessionRepo: r,
+		sessionRepo:  r,
+		occupancy:    occupancy,
+		horizonWeeks: horizonWeeks,
+		audit:        auditRec,
+		notifier:     notifier,
 	}
 }
 
+// CreateSession creates a one-off session, the facility has to be free at that time
 func (s *SessionService) CreateSession(ctx context.Context, data Session) error {
-	return s.sessionRepo.CreateSession(ctx, data)
+	tx, err := s.sessionRepo.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("CreateSession: Failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	conflicts, err := s.claim(ctx, tx, data)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%w: %s", availability.ErrFacilityOccupied, availability.Describe(conflicts))
+	}
+
+	if err := s.sessionRepo.CreateSession(ctx, tx, data); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("CreateSession: Failed to commit: %w", err)
+	}
+	s.audit.Record(ctx, "session.create", "session", data.ID, nil, data)
+	return nil
 }
 
-func (s *SessionService) DeleteSession(ctx context.Context, id uuid.UUID) error {
-	return s.sessionRepo.DeleteSession(ctx, id)
+// DeleteSession removes a session nobody registered for. once someone did, the registrations, the attendance
+// and the penalties hang on it, so the session is canceled instead and the cancellation is returned (nil when deleted)
+func (s *SessionService) DeleteSession(ctx context.Context, id uuid.UUID) (*Cancellation, error) {
+	tx, err := s.sessionRepo.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("DeleteSession: Failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := s.sessionRepo.GetSessionForUpdate(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	registered, err := s.sessionRepo.HasRegistrations(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if registered {
+		if before.IsCanceled {
+			return nil, ErrSessionAlreadyCanceled
+		}
+		return s.cancel(ctx, tx, before, "session deleted")
+	}
+
+	if err := s.sessionRepo.DeleteSession(ctx, tx, id); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("DeleteSession: Failed to commit: %w", err)
+	}
+	s.audit.Record(ctx, "session.delete", "session", id, before, nil)
+	return nil, nil
 }
 
-func (s *SessionService) CancelSession(ctx context.Context, id uuid.UUID) error {
-	return s.sessionRepo.CancelSession(ctx, id)
+// CancelSession cancels the session together with every registration and waitlist entry, refunds the penalties
+// given for it and tells the affected users, all in one transaction
+func (s *SessionService) CancelSession(ctx context.Context, id uuid.UUID, reason string) (Cancellation, error) {
+	tx, err := s.sessionRepo.BeginTx(ctx)
+	if err != nil {
+		return Cancellation{}, fmt.Errorf("CancelSession: Failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := s.sessionRepo.GetSessionForUpdate(ctx, tx, id)
+	if err != nil {
+		return Cancellation{}, err
+	}
+	if before.IsCanceled {
+		return Cancellation{}, ErrSessionAlreadyCanceled
+	}
+
+	c, err := s.cancel(ctx, tx, before, reason)
+	if err != nil {
+		return Cancellation{}, err
+	}
+	return *c, nil
+}
+
+// cancel runs the cancellation in tx, commits it and sends the audit entry and the notifications
+func (s *SessionService) cancel(ctx context.Context, tx pgx.Tx, before *Session, reason string) (*Cancellation, error) {
+	c, err := s.sessionRepo.CancelSession(ctx, tx, before.ID, reason)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("CancelSession: Failed to commit: %w", err)
+	}
+
+	after := *before
+	after.IsCanceled = true
+	after.CancelReason = reason
+	s.audit.Record(ctx, "session.cancel", "session", before.ID, before, c)
+	s.notifyCanceled(ctx, after, c)
+	return &c, nil
+}
+
+func (s *SessionService) notifyCanceled(ctx context.Context, sess Session, c Cancellation) {
+	users := append([]uuid.UUID{}, c.AffectedUsers...)
+	refunded := make(map[uuid.UUID]int)
+	for _, rf := range c.Refunds {
+		//penalized users whose registration was already canceled still hear about the refund
+		if _, seen := refunded[rf.UserID]; !seen && !slices.Contains(c.AffectedUsers, rf.UserID) {
+			users = append(users, rf.UserID)
+		}
+		refunded[rf.UserID] += rf.Points
+	}
+
+	for _, userID := range users {
+		body := fmt.Sprintf("The session on %s at %s was canceled.", sess.Date.Format("2006-01-02"), sess.StartTime.Format("15:04"))
+		if c.Reason != "" {
+			body += " Reason: " + c.Reason
+		}
+		if points := refunded[userID]; points > 0 {
+			body += fmt.Sprintf(" The %d penalty points you got for it were given back.", points)
+		}
+		s.notifier.Notify(ctx, notification.Notification{
+			UserID:     userID,
+			Type:       notification.TypeSessionCanceled,
+			Title:      "Your session was canceled",
+			Body:       body,
+			EntityType: "session",
+			EntityID:   &sess.ID,
+		})
+	}
 }
 
 func (s *SessionService) ListFacilitySessions(ctx context.Context, facilityID uuid.UUID, date time.Time) ([]Session, error) {
@@ -38,41 +176,145 @@ func (s *SessionService) ListTrainerSessions(ctx context.Context, trainerID uuid
 	return s.sessionRepo.ListTrainerSessions(ctx, trainerID, date)
 }
 
+// RegisteredUsers returns the users registered or waitlisted for the session
+func (s *SessionService) RegisteredUsers(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	return s.sessionRepo.ListRegisteredUsers(ctx, id)
+}
+
 func (s *SessionService) GetSession(ctx context.Context, id uuid.UUID) (*Session, error) {
 	return s.sessionRepo.GetSession(ctx, id)
 }
 
-// for the next n weeks we it will create sessions
-func (s *SessionService) CreateSessionsForNextWeeks(schedule schedule.Schedule, weeks int) error {
-	days := NextWeekdays(schedule.WeekDay, 2)
-
-	session := Session{
-		ScheduleID: schedule.ID,
-		TrainerID:  schedule.TrainerID,
-		FacilityID: schedule.FacilityID,
-		StartTime:  schedule.StartTime,
-		EndTime:    schedule.EndTime,
-		Capacity:   schedule.Capacity,
-		IsCanceled: false,
-	}
-	for _, day := range days {
-		var i Session
-		i = session
-		id, _ := uuid.NewUUID()
-		i.ID = id
-		i.Date = day
-		err := s.CreateSession(context.TODO(), i)
+// for the next n weeks it will create the sessions which are not created yet, returns how many were created
+func (s *SessionService) CreateSessionsForNextWeeks(ctx context.Context, sch schedule.Schedule, weeks int) (int, error) {
+	tx, err := s.sessionRepo.BeginTx(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("CreateSessionsForNextWeeks: Failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	created, _, err := s.materialize(ctx, tx, sch, weeks)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("CreateSessionsForNextWeeks: Failed to commit: %w", err)
+	}
+	if len(created) > 0 {
+		s.audit.Record(ctx, "session.generate", "schedule", sch.ID, nil, created)
+	}
+	return len(created), nil
+}
+
+// GenerateForSchedule fills the configured horizon for a single (usually just created) schedule
+func (s *SessionService) GenerateForSchedule(ctx context.Context, sch schedule.Schedule) (int, error) {
+	return s.CreateSessionsForNextWeeks(ctx, sch, s.horizonWeeks)
+}
+
+// GenerateSessions goes over every active weekly schedule and creates the missing sessions up to the horizon.
+// it is safe to run from several replicas, only the one holding the advisory lock does the work
+func (s *SessionService) GenerateSessions(ctx context.Context) (GenerationReport, error) {
+	report := GenerationReport{StartedAt: time.Now(), HorizonWeeks: s.horizonWeeks}
+
+	tx, err := s.sessionRepo.BeginTx(ctx)
+	if err != nil {
+		return report, fmt.Errorf("GenerateSessions: Failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	locked, err := pg.TryAdvisoryXactLock(ctx, tx, generationLockKey)
+	if err != nil {
+		return report, err
+	}
+	if !locked {
+		report.FinishedAt = time.Now()
+		return report, nil
+	}
+	report.LockAcquired = true
+
+	schedules, err := s.sessionRepo.ListActiveSchedules(ctx, tx)
+	if err != nil {
+		return report, err
+	}
+	report.SchedulesScanned = len(schedules)
+
+	for _, sch := range schedules {
+		created, skipped, err := s.materialize(ctx, tx, sch, s.horizonWeeks)
 		if err != nil {
-			return err
+			return report, err
 		}
+		report.Created = append(report.Created, created...)
+		report.Skipped = append(report.Skipped, skipped...)
 	}
-	return nil
 
+	if err := tx.Commit(ctx); err != nil {
+		return report, fmt.Errorf("GenerateSessions: Failed to commit: %w", err)
+	}
+	report.FinishedAt = time.Now()
+	if len(report.Created) > 0 {
+		s.audit.Record(ctx, "session.generate", "session", uuid.Nil, nil, report)
+	}
+	return report, nil
+}
+
+// materialize creates the missing sessions of the schedule, dates on which the facility is occupied are skipped
+func (s *SessionService) materialize(ctx context.Context, tx pgx.Tx, sch schedule.Schedule, weeks int) ([]GeneratedSession, []SkippedSession, error) {
+	created := make([]GeneratedSession, 0)
+	skipped := make([]SkippedSession, 0)
+	for _, day := range NextWeekdays(sch.WeekDay, weeks) {
+		i := Session{
+			ID:         uuid.New(),
+			ScheduleID: sch.ID,
+			TrainerID:  sch.TrainerID,
+			FacilityID: sch.FacilityID,
+			Date:       day,
+			StartTime:  sch.StartTime,
+			EndTime:    sch.EndTime,
+			Capacity:   sch.Capacity,
+			IsCanceled: false,
+		}
+		conflicts, err := s.claim(ctx, tx, i)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(conflicts) > 0 {
+			skipped = append(skipped, SkippedSession{ScheduleID: sch.ID, Date: day, Conflicts: conflicts})
+			continue
+		}
+
+		ok, err := s.sessionRepo.CreateSessionIfMissing(ctx, tx, i)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ok {
+			created = append(created, GeneratedSession{SessionID: i.ID, ScheduleID: sch.ID, Date: day})
+		}
+	}
+	return created, skipped, nil
+}
+
+// claim locks the facility for the rest of tx and returns the bookings and other sessions in the way of the session.
+// sessions of its own schedule are not in the way, generating the same date again is a no-op anyway
+func (s *SessionService) claim(ctx context.Context, tx pgx.Tx, data Session) ([]availability.Busy, error) {
+	if err := s.occupancy.LockFacility(ctx, tx, data.FacilityID); err != nil {
+		return nil, err
+	}
+	return s.occupancy.Conflicts(ctx, tx, availability.Claim{
+		FacilityID: data.FacilityID,
+		Date:       data.Date,
+		Interval:   availability.ClockInterval(data.StartTime, data.EndTime),
+		ScheduleID: data.ScheduleID,
+	})
 }
 
 func NextWeekdays(weekday int, count int) []time.Time {
 	now := time.Now()
+	//date only, the column is DATE anyway and this keeps the (schedule_id, date) comparison stable
+	now = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
 	results := make([]time.Time, 0, count)
+	if count <= 0 {
+		return results
+	}
 
 	// Convert int → time.Weekday
 	target := time.Weekday((weekday) % 7) // because Go uses 0=Sunday, 1=Monday
@@ -0,0 +1,98 @@
+package sms
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type Message struct {
+	To   string // phone number as the user entered it
+	Text string
+}
+
+// Sender sends text messages, swap the implementation to plug in a provider
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// OutboxSender does not send anything, it writes every message as a .txt file into a local directory and logs it.
+// It is the default so the flows can be tested without an SMS provider.
+type OutboxSender struct {
+	dir    string
+	logger *zap.Logger
+}
+
+func NewOutboxSender(dir string, logger *zap.Logger) (*OutboxSender, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("sms.NewOutboxSender: %w", err)
+	}
+	return &OutboxSender{dir: dir, logger: logger}, nil
+}
+
+func (s *OutboxSender) Send(ctx context.Context, msg Message) error {
+	now := time.Now()
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Errorf("OutboxSender.Send: %w", err)
+	}
+	name := fmt.Sprintf("%s-%s.txt", now.UTC().Format("20060102T150405.000000000"), hex.EncodeToString(suffix))
+
+	content := fmt.Sprintf("To: %s\nDate: %s\n\n%s\n", msg.To, now.Format(time.RFC1123Z), msg.Text)
+	path := filepath.Join(s.dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("OutboxSender.Send: %w", err)
+	}
+
+	s.logger.Info("sms written to outbox", zap.String("to", msg.To), zap.String("file", path))
+	return nil
+}
+
+// HTTPSender posts {"to": ..., "text": ...} as JSON to a provider or gateway webhook, enabled with SMS_PROVIDER=http.
+// any 2xx answer counts as sent
+type HTTPSender struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+func NewHTTPSender(url, token string) *HTTPSender {
+	return &HTTPSender{url: url, token: token, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (s *HTTPSender) Send(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(map[string]string{"to": msg.To, "text": msg.Text})
+	if err != nil {
+		return fmt.Errorf("HTTPSender.Send: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("HTTPSender.Send: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("HTTPSender.Send: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("HTTPSender.Send: provider answered %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,5 @@
+package trainer
+
+import "errors"
+
+var ErrTrainerNotFound = errors.New("trainer not found")
@@ -2,9 +2,11 @@ package trainer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -33,7 +35,7 @@ func (r *TrainerRepositoryPostgres) CreateTrainer(ctx context.Context, userID uu
 	}
 	defer tx.Rollback(ctx)
 
-	query := `UPDATE users SET role='trainer' WHERE user_id=$1`
+	query := `UPDATE users SET role='trainer', token_version = token_version + 1 WHERE user_id=$1`
 	_, err = tx.Exec(ctx, query, userID)
 	if err != nil {
 		return fmt.Errorf("CreateTrainer: failed to Exec: %w", err)
@@ -57,7 +59,7 @@ func (r *TrainerRepositoryPostgres) UpdateTrainer(ctx context.Context, trainer T
 		return fmt.Errorf("UpdateTrainer: failed to Exec: %w", err)
 	}
 	if tag.RowsAffected() == 0 {
-		return fmt.Errorf("Trainer Not Found")
+		return ErrTrainerNotFound
 	}
 	return nil
 }
@@ -76,7 +78,7 @@ func (r *TrainerRepositoryPostgres) DeleteTrainer(ctx context.Context, id uuid.U
 		return fmt.Errorf("DeleteTrainer: failed to Exec: %w", err)
 	}
 
-	query = `UPDATE users SET role='user' WHERE user_id=$1`
+	query = `UPDATE users SET role='student', token_version = token_version + 1 WHERE user_id=$1`
 	_, err = tx.Exec(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("DeleteTrainer: failed to Exec: %w", err)
@@ -99,6 +101,9 @@ func (r *TrainerRepositoryPostgres) GetTrainer(ctx context.Context, id uuid.UUID
 		&t.User.ID, &t.User.FirstName, &t.User.LastName, &t.User.Email, &t.User.Role, &t.User.CreatedAt, &t.User.UpdatedAt,
 	)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Trainer{}, ErrTrainerNotFound
+		}
 		return Trainer{}, fmt.Errorf("GetTrainer: Failed to Query: %w", err)
 	}
 	return t, nil
@@ -2,30 +2,66 @@ package trainer
 
 import (
 	"context"
+	"t/internal/audit"
 
 	"github.com/google/uuid"
 )
 
 type TrainerService struct {
 	trainerRepo TrainerRepository
+	audit       audit.Recorder
 }
 
-func NewTrainerService(repo TrainerRepository) *TrainerService {
+func NewTrainerService(repo TrainerRepository, auditRec audit.Recorder) *TrainerService {
 	return &TrainerService{
 		trainerRepo: repo,
+		audit:       auditRec,
 	}
 }
 
+// CreateTrainer promotes the user to trainer
 func (s *TrainerService) CreateTrainer(ctx context.Context, userID uuid.UUID) error {
-	return s.trainerRepo.CreateTrainer(ctx, userID)
+	if err := s.trainerRepo.CreateTrainer(ctx, userID); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "trainer.create", "trainer", userID, nil, auditTrainer{ID: userID})
+	return nil
 }
 
 func (s *TrainerService) UpdateTrainer(ctx context.Context, data Trainer) error {
-	return s.trainerRepo.UpdateTrainer(ctx, data)
+	before, err := s.trainerRepo.GetTrainer(ctx, data.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.trainerRepo.UpdateTrainer(ctx, data); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "trainer.update", "trainer", data.ID, newAuditTrainer(before), newAuditTrainer(data))
+	return nil
 }
 
+// DeleteTrainer demotes the trainer back to student
 func (s *TrainerService) DeleteTrainer(ctx context.Context, id uuid.UUID) error {
-	return s.trainerRepo.DeleteTrainer(ctx, id)
+	before, err := s.trainerRepo.GetTrainer(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.trainerRepo.DeleteTrainer(ctx, id); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "trainer.delete", "trainer", id, newAuditTrainer(before), nil)
+	return nil
+}
+
+// auditTrainer is what the audit log keeps of a trainer, without the embedded user and its personal data
+type auditTrainer struct {
+	ID        uuid.UUID
+	Bio       string
+	Specialty string
+}
+
+func newAuditTrainer(t Trainer) auditTrainer {
+	return auditTrainer{ID: t.ID, Bio: t.Bio, Specialty: t.Specialty}
 }
 
 func (s *TrainerService) GetTrainer(ctx context.Context, id uuid.UUID) (Trainer, error) {
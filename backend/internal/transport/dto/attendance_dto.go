@@ -0,0 +1,126 @@
+package dto
+
+import (
+	"t/internal/attendance"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SetAttendanceRequest struct {
+	Status string `json:"status" validate:"required,oneof=pending attended late no_show"`
+}
+
+// TokenCheckInRequest is what the front desk or the trainer sends after scanning a QR code
+type TokenCheckInRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type CheckInTokenResponse struct {
+	Kind      string    `json:"kind"`
+	ID        uuid.UUID `json:"id"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func NewCheckInTokenResponse(t attendance.Token) CheckInTokenResponse {
+	return CheckInTokenResponse{
+		Kind:      string(t.Kind),
+		ID:        t.ID,
+		Token:     t.Value,
+		ExpiresAt: t.ExpiresAt,
+	}
+}
+
+type AttendanceResponse struct {
+	Kind         string     `json:"kind"`
+	ID           uuid.UUID  `json:"id"`
+	UserID       uuid.UUID  `json:"user_id"`
+	Status       string     `json:"status"`
+	CheckedInAt  *time.Time `json:"checked_in_at,omitempty"`
+	CheckedOutAt *time.Time `json:"checked_out_at,omitempty"`
+	CheckedInBy  *uuid.UUID `json:"checked_in_by,omitempty"`
+}
+
+func NewAttendanceResponse(r attendance.Record) AttendanceResponse {
+	return AttendanceResponse{
+		Kind:         string(r.Kind),
+		ID:           r.ID,
+		UserID:       r.UserID,
+		Status:       string(r.Status),
+		CheckedInAt:  r.CheckedInAt,
+		CheckedOutAt: r.CheckedOutAt,
+		CheckedInBy:  r.CheckedInBy,
+	}
+}
+
+type ClosingReportResponse struct {
+	StartedAt       time.Time `json:"started_at"`
+	FinishedAt      time.Time `json:"finished_at"`
+	LockAcquired    bool      `json:"lock_acquired"`
+	Closed          int       `json:"closed"`
+	NoShows         int       `json:"no_shows"`
+	PenaltiesIssued int       `json:"penalties_issued"`
+}
+
+func NewClosingReportResponse(r attendance.ClosingReport) ClosingReportResponse {
+	return ClosingReportResponse{
+		StartedAt:       r.StartedAt,
+		FinishedAt:      r.FinishedAt,
+		LockAcquired:    r.LockAcquired,
+		Closed:          r.Closed,
+		NoShows:         r.NoShows,
+		PenaltiesIssued: r.PenaltiesIssued,
+	}
+}
+
+type RosterEntryResponse struct {
+	RegistrationID uuid.UUID  `json:"registration_id"`
+	UserID         uuid.UUID  `json:"user_id"`
+	FirstName      string     `json:"first_name"`
+	LastName       string     `json:"last_name"`
+	Email          string     `json:"email"`
+	Status         string     `json:"status"`
+	CheckedInAt    *time.Time `json:"checked_in_at,omitempty"`
+	CheckedOutAt   *time.Time `json:"checked_out_at,omitempty"`
+}
+
+type RosterResponse struct {
+	SessionID uuid.UUID             `json:"session_id"`
+	TrainerID uuid.UUID             `json:"trainer_id"`
+	StartsAt  time.Time             `json:"starts_at"`
+	EndsAt    time.Time             `json:"ends_at"`
+	Attended  int                   `json:"attended"`
+	Late      int                   `json:"late"`
+	NoShow    int                   `json:"no_show"`
+	Pending   int                   `json:"pending"`
+	Entries   []RosterEntryResponse `json:"entries"`
+}
+
+func NewRosterResponse(r attendance.Roster) RosterResponse {
+	entries := make([]RosterEntryResponse, 0, len(r.Entries))
+	for _, e := range r.Entries {
+		entries = append(entries, RosterEntryResponse{
+			RegistrationID: e.RegistrationID,
+			UserID:         e.UserID,
+			FirstName:      e.FirstName,
+			LastName:       e.LastName,
+			Email:          e.Email,
+			Status:         string(e.Status),
+			CheckedInAt:    e.CheckedInAt,
+			CheckedOutAt:   e.CheckedOutAt,
+		})
+	}
+
+	return RosterResponse{
+		SessionID: r.SessionID,
+		TrainerID: r.TrainerID,
+		StartsAt:  r.Start,
+		EndsAt:    r.End,
+		Attended:  r.Attended,
+		Late:      r.Late,
+		NoShow:    r.NoShow,
+		Pending:   r.Pending,
+		Entries:   entries,
+	}
+}
@@ -0,0 +1,42 @@
+package dto
+
+import (
+	"t/internal/auth"
+	"time"
+)
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=6,max=100"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type TokenResponse struct {
+	// kept as "token" so existing clients reading the login response keep working
+	AccessToken      string    `json:"token"`
+	RefreshToken     string    `json:"refresh_token"`
+	ExpiresIn        int       `json:"expires_in"`
+	AccessExpiresAt  time.Time `json:"access_expires_at"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+func NewTokenResponse(p auth.TokenPair) TokenResponse {
+	return TokenResponse{
+		AccessToken:      p.AccessToken,
+		RefreshToken:     p.RefreshToken,
+		ExpiresIn:        int(time.Until(p.AccessExpiresAt).Seconds()),
+		AccessExpiresAt:  p.AccessExpiresAt,
+		RefreshExpiresAt: p.RefreshExpiresAt,
+	}
+}
@@ -0,0 +1,114 @@
+package dto
+
+import (
+	"t/internal/availability"
+
+	"github.com/google/uuid"
+)
+
+type IntervalResponse struct {
+	Start string `json:"start"` // "10:00"
+	End   string `json:"end"`   // "11:30", "24:00" is the end of the day
+}
+
+type BusyIntervalResponse struct {
+	Start  string     `json:"start"`
+	End    string     `json:"end"`
+	Kind   string     `json:"kind"` // booking, session, closure, holiday or past
+	RefID  *uuid.UUID `json:"ref_id,omitempty"`
+	Reason string     `json:"reason,omitempty"`
+}
+
+type SlotResponse struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+	Free  bool   `json:"free"`
+}
+
+type DayAvailabilityResponse struct {
+	Date   string                 `json:"date"`
+	Open   string                 `json:"open,omitempty"`  // first opening of the day
+	Close  string                 `json:"close,omitempty"` // last closing of the day
+	Hours  []IntervalResponse     `json:"hours"`
+	Closed bool                   `json:"closed"`
+	Busy   []BusyIntervalResponse `json:"busy"`
+	Free   []IntervalResponse     `json:"free"`
+	Slots  []SlotResponse         `json:"slots"`
+}
+
+type AvailabilityResponse struct {
+	FacilityID  uuid.UUID                 `json:"facility_id"`
+	SlotMinutes int                       `json:"slot_minutes"`
+	Days        []DayAvailabilityResponse `json:"days"`
+}
+
+func NewAvailabilityResponse(a availability.Availability) AvailabilityResponse {
+	resp := AvailabilityResponse{
+		FacilityID:  a.FacilityID,
+		SlotMinutes: a.SlotMinutes,
+		Days:        make([]DayAvailabilityResponse, 0, len(a.Days)),
+	}
+	for _, d := range a.Days {
+		day := DayAvailabilityResponse{
+			Date:   d.Date.Format("2006-01-02"),
+			Hours:  make([]IntervalResponse, 0, len(d.Open)),
+			Closed: d.Closed,
+			Busy:   make([]BusyIntervalResponse, 0, len(d.Busy)),
+			Free:   make([]IntervalResponse, 0, len(d.Free)),
+			Slots:  make([]SlotResponse, 0, len(d.Slots)),
+		}
+		for _, b := range d.Busy {
+			item := BusyIntervalResponse{
+				Start:  availability.FormatMinutes(b.Start),
+				End:    availability.FormatMinutes(b.End),
+				Kind:   string(b.Kind),
+				Reason: b.Reason,
+			}
+			if b.RefID != uuid.Nil {
+				id := b.RefID
+				item.RefID = &id
+			}
+			day.Busy = append(day.Busy, item)
+		}
+		for _, o := range d.Open {
+			day.Hours = append(day.Hours, IntervalResponse{Start: availability.FormatMinutes(o.Start), End: availability.FormatMinutes(o.End)})
+		}
+		if n := len(d.Open); n > 0 {
+			day.Open = availability.FormatMinutes(d.Open[0].Start)
+			day.Close = availability.FormatMinutes(d.Open[n-1].End)
+		}
+		for _, f := range d.Free {
+			day.Free = append(day.Free, IntervalResponse{Start: availability.FormatMinutes(f.Start), End: availability.FormatMinutes(f.End)})
+		}
+		for _, s := range d.Slots {
+			day.Slots = append(day.Slots, SlotResponse{Start: availability.FormatMinutes(s.Start), End: availability.FormatMinutes(s.End), Free: s.Free})
+		}
+		resp.Days = append(resp.Days, day)
+	}
+	return resp
+}
+
+// OccupiedResponse is a booking or session in the way of a new claim on the facility
+type OccupiedResponse struct {
+	Date   string    `json:"date"`
+	Start  string    `json:"start"`
+	End    string    `json:"end"`
+	Kind   string    `json:"kind"`
+	RefID  uuid.UUID `json:"ref_id"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+func NewOccupiedResponses(busy []availability.Busy) []OccupiedResponse {
+	resp := make([]OccupiedResponse, 0, len(busy))
+	for _, b := range busy {
+		resp = append(resp, OccupiedResponse{
+			Date:   b.Date.Format("2006-01-02"),
+			Start:  availability.FormatMinutes(b.Start),
+			End:    availability.FormatMinutes(b.End),
+			Kind:   string(b.Kind),
+			RefID:  b.RefID,
+			Reason: b.Reason,
+		})
+	}
+	return resp
+}
@@ -73,6 +73,7 @@ type BookingResponse struct {
 	Note       string `json:"note"`
 	IsCanceled bool   `json:"is_canceled"`
 	AdminNote  string `json:"admin_note"`
+	SeriesID   string `json:"series_id,omitempty"`
 	CreatedAt  string `json:"created_at"`
 }
 
@@ -87,6 +88,170 @@ func ToBookingResponse(b booking.Booking) BookingResponse {
 		Note:       b.Note,
 		IsCanceled: b.IsCanceled,
 		AdminNote:  b.AdminNote,
+		SeriesID:   seriesIDString(b.SeriesID),
 		CreatedAt:  b.CreatedAt.Format(time.RFC3339),
 	}
 }
+
+func seriesIDString(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
+
+// CreateSeriesRequest books the same slot on every date of the rule, e.g. rrule "FREQ=WEEKLY;BYDAY=TU"
+type CreateSeriesRequest struct {
+	FacilityID    string `json:"facility_id" validate:"required,uuid4"`
+	Rule          string `json:"rrule" validate:"required,max=200"`
+	StartDate     string `json:"start_date" validate:"required"` // "2025-02-14"
+	Until         string `json:"until" validate:"required"`      // "2025-06-30", inclusive
+	StartTime     string `json:"start_time" validate:"required"` // "18:00"
+	EndTime       string `json:"end_time" validate:"required"`   // "19:00"
+	Note          string `json:"note"`
+	SkipConflicts bool   `json:"skip_conflicts"` // book the free occurrences even if some conflict
+}
+
+func (b *CreateSeriesRequest) ToModel(userID uuid.UUID) (booking.Series, error) {
+	facilID, err := uuid.Parse(b.FacilityID)
+	if err != nil {
+		return booking.Series{}, fmt.Errorf("invalid facility_id: %w", err)
+	}
+
+	startDate, err := time.Parse("2006-01-02", b.StartDate)
+	if err != nil {
+		return booking.Series{}, fmt.Errorf("invalid start_date format, expected YYYY-MM-DD: %w", err)
+	}
+	until, err := time.Parse("2006-01-02", b.Until)
+	if err != nil {
+		return booking.Series{}, fmt.Errorf("invalid until format, expected YYYY-MM-DD: %w", err)
+	}
+
+	start, err := time.Parse("15:04", b.StartTime)
+	if err != nil {
+		return booking.Series{}, fmt.Errorf("invalid start_time format, expected HH:MM: %w", err)
+	}
+	end, err := time.Parse("15:04", b.EndTime)
+	if err != nil {
+		return booking.Series{}, fmt.Errorf("invalid end_time format, expected HH:MM: %w", err)
+	}
+
+	return booking.Series{
+		UserID:     userID,
+		FacilityID: facilID,
+		Rule:       b.Rule,
+		StartDate:  startDate,
+		Until:      until,
+		StartTime:  start,
+		EndTime:    end,
+		Note:       b.Note,
+	}, nil
+}
+
+type SeriesResponse struct {
+	ID         string `json:"id"`
+	UserID     string `json:"user_id"`
+	FacilityID string `json:"facility_id"`
+	Rule       string `json:"rrule"`
+	StartDate  string `json:"start_date"`
+	Until      string `json:"until"`
+	StartTime  string `json:"start_time"`
+	EndTime    string `json:"end_time"`
+	Note       string `json:"note"`
+	IsCanceled bool   `json:"is_canceled"`
+	CreatedAt  string `json:"created_at,omitempty"`
+}
+
+func ToSeriesResponse(s booking.Series) SeriesResponse {
+	resp := SeriesResponse{
+		ID:         s.ID.String(),
+		UserID:     s.UserID.String(),
+		FacilityID: s.FacilityID.String(),
+		Rule:       s.Rule,
+		StartDate:  s.StartDate.Format("2006-01-02"),
+		Until:      s.Until.Format("2006-01-02"),
+		StartTime:  s.StartTime.Format("15:04"),
+		EndTime:    s.EndTime.Format("15:04"),
+		Note:       s.Note,
+		IsCanceled: s.IsCanceled,
+	}
+	if !s.CreatedAt.IsZero() {
+		resp.CreatedAt = s.CreatedAt.Format(time.RFC3339)
+	}
+	return resp
+}
+
+// OccurrenceResponse is one date of the series, code and reason are set for conflicts
+type OccurrenceResponse struct {
+	Date      string     `json:"date"`
+	Status    string     `json:"status"`
+	BookingID *uuid.UUID `json:"booking_id,omitempty"`
+	Code      string     `json:"code,omitempty"`
+	Reason    string     `json:"reason,omitempty"`
+}
+
+type SeriesReportResponse struct {
+	Series      SeriesResponse       `json:"series"`
+	Created     bool                 `json:"created"`
+	Booked      int                  `json:"booked"`
+	Conflicts   int                  `json:"conflicts"`
+	Occurrences []OccurrenceResponse `json:"occurrences"`
+}
+
+// NewSeriesReportResponse converts the report, code resolves the stable error code of a conflict
+func NewSeriesReportResponse(r booking.SeriesReport, code func(error) string) SeriesReportResponse {
+	resp := SeriesReportResponse{
+		Series:      ToSeriesResponse(r.Series),
+		Created:     r.Created,
+		Booked:      r.Booked,
+		Conflicts:   r.Conflicts,
+		Occurrences: make([]OccurrenceResponse, 0, len(r.Occurrences)),
+	}
+	for _, o := range r.Occurrences {
+		item := OccurrenceResponse{Date: o.Date.Format("2006-01-02"), Status: string(o.Status), BookingID: o.BookingID}
+		if o.Err != nil {
+			item.Code = code(o.Err)
+			item.Reason = o.Err.Error()
+		}
+		resp.Occurrences = append(resp.Occurrences, item)
+	}
+	return resp
+}
+
+// SeriesDetailResponse is the series with every occurrence booked for it
+type SeriesDetailResponse struct {
+	Series   SeriesResponse    `json:"series"`
+	Bookings []BookingResponse `json:"bookings"`
+}
+
+func NewSeriesDetailResponse(s booking.Series, bookings []booking.Booking) SeriesDetailResponse {
+	resp := SeriesDetailResponse{Series: ToSeriesResponse(s), Bookings: make([]BookingResponse, 0, len(bookings))}
+	for _, b := range bookings {
+		resp.Bookings = append(resp.Bookings, ToBookingResponse(b))
+	}
+	return resp
+}
+
+// SeriesCancellationResponse lists what was canceled and the occurrences kept because they are inside the cancel cutoff
+type SeriesCancellationResponse struct {
+	SeriesID string            `json:"series_id"`
+	From     string            `json:"from"`
+	Canceled []BookingResponse `json:"canceled"`
+	Kept     []BookingResponse `json:"kept"`
+}
+
+func NewSeriesCancellationResponse(c booking.SeriesCancellation) SeriesCancellationResponse {
+	resp := SeriesCancellationResponse{
+		SeriesID: c.SeriesID.String(),
+		From:     c.From.Format("2006-01-02"),
+		Canceled: make([]BookingResponse, 0, len(c.Canceled)),
+		Kept:     make([]BookingResponse, 0, len(c.Kept)),
+	}
+	for _, b := range c.Canceled {
+		resp.Canceled = append(resp.Canceled, ToBookingResponse(b))
+	}
+	for _, b := range c.Kept {
+		resp.Kept = append(resp.Kept, ToBookingResponse(b))
+	}
+	return resp
+}
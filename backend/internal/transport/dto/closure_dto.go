@@ -0,0 +1,136 @@
+package dto
+
+import (
+	"fmt"
+	"t/internal/availability"
+	"t/internal/closure"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateClosureRequest closes the facility. without times the whole day, without rrule every day from start_date
+// to end_date (end_date defaults to start_date), with rrule only its occurrences up to end_date (open ended if empty)
+type CreateClosureRequest struct {
+	Reason    string `json:"reason" validate:"required,max=500"`
+	StartDate string `json:"start_date" validate:"required"` // "2025-03-07"
+	EndDate   string `json:"end_date"`                       // "2025-03-09", inclusive
+	StartTime string `json:"start_time"`                     // "08:00", optional
+	EndTime   string `json:"end_time"`                       // "12:00", optional
+	Rule      string `json:"rrule" validate:"max=200"`       // "FREQ=WEEKLY;BYDAY=MO"
+	// cancel the bookings and sessions inside the closure instead of only reporting them
+	CancelAffected bool `json:"cancel_affected"`
+}
+
+func (req *CreateClosureRequest) ToModel(facilityID, createdBy uuid.UUID) (availability.Closure, error) {
+	c := availability.Closure{
+		FacilityID: facilityID,
+		Reason:     req.Reason,
+		Rule:       req.Rule,
+		CreatedBy:  &createdBy,
+	}
+
+	start, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return c, fmt.Errorf("invalid start_date format, expected YYYY-MM-DD: %w", err)
+	}
+	c.StartDate = start
+
+	if req.EndDate != "" {
+		end, err := time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return c, fmt.Errorf("invalid end_date format, expected YYYY-MM-DD: %w", err)
+		}
+		c.EndDate = &end
+	}
+
+	if (req.StartTime == "") != (req.EndTime == "") {
+		return c, fmt.Errorf("start_time and end_time go together")
+	}
+	if req.StartTime != "" {
+		st, err := time.Parse("15:04", req.StartTime)
+		if err != nil {
+			return c, fmt.Errorf("invalid start_time format, expected HH:MM: %w", err)
+		}
+		et, err := time.Parse("15:04", req.EndTime)
+		if err != nil {
+			return c, fmt.Errorf("invalid end_time format, expected HH:MM: %w", err)
+		}
+		w := availability.ClockInterval(st, et)
+		c.Window = &w
+	}
+	return c, nil
+}
+
+type ClosureResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	FacilityID uuid.UUID  `json:"facility_id"`
+	Reason     string     `json:"reason"`
+	StartDate  string     `json:"start_date"`
+	EndDate    string     `json:"end_date,omitempty"`
+	StartTime  string     `json:"start_time,omitempty"`
+	EndTime    string     `json:"end_time,omitempty"`
+	Rule       string     `json:"rrule,omitempty"`
+	CreatedBy  *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt  *time.Time `json:"created_at,omitempty"`
+}
+
+func NewClosureResponse(c availability.Closure) ClosureResponse {
+	resp := ClosureResponse{
+		ID:         c.ID,
+		FacilityID: c.FacilityID,
+		Reason:     c.Reason,
+		StartDate:  c.StartDate.Format("2006-01-02"),
+		Rule:       c.Rule,
+		CreatedBy:  c.CreatedBy,
+	}
+	if c.EndDate != nil {
+		resp.EndDate = c.EndDate.Format("2006-01-02")
+	}
+	if c.Window != nil {
+		resp.StartTime = availability.FormatMinutes(c.Window.Start)
+		resp.EndTime = availability.FormatMinutes(c.Window.End)
+	}
+	if !c.CreatedAt.IsZero() {
+		resp.CreatedAt = &c.CreatedAt
+	}
+	return resp
+}
+
+type ClosureReportResponse struct {
+	Closure          ClosureResponse          `json:"closure"`
+	Affected         []OccupiedResponse       `json:"affected"`
+	Canceled         bool                     `json:"canceled"`
+	CanceledBookings []uuid.UUID              `json:"canceled_bookings"`
+	CanceledSessions []uuid.UUID              `json:"canceled_sessions"`
+	Failed           []ClosureFailureResponse `json:"failed"`
+}
+
+// ClosureFailureResponse is a booking or session the closure could not cancel
+type ClosureFailureResponse struct {
+	Kind   string    `json:"kind"` // "booking" or "session"
+	RefID  uuid.UUID `json:"ref_id"`
+	Code   string    `json:"code"`
+	Reason string    `json:"reason"`
+}
+
+// NewClosureReportResponse converts the report, code resolves the stable error code of a failed cancellation
+func NewClosureReportResponse(r closure.Report, code func(error) string) ClosureReportResponse {
+	resp := ClosureReportResponse{
+		Closure:          NewClosureResponse(r.Closure),
+		Affected:         NewOccupiedResponses(r.Affected),
+		Canceled:         r.Canceled,
+		CanceledBookings: r.CanceledBookings,
+		CanceledSessions: r.CanceledSessions,
+		Failed:           make([]ClosureFailureResponse, 0, len(r.Failed)),
+	}
+	for _, f := range r.Failed {
+		resp.Failed = append(resp.Failed, ClosureFailureResponse{
+			Kind:   string(f.Kind),
+			RefID:  f.RefID,
+			Code:   code(f.Err),
+			Reason: f.Err.Error(),
+		})
+	}
+	return resp
+}
@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"t/internal/credit"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreditEntryResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	Delta     int        `json:"delta"`
+	Balance   int        `json:"balance"`
+	Reason    string     `json:"reason"`
+	PenaltyID *uuid.UUID `json:"penalty_id,omitempty"`
+	SessionID *uuid.UUID `json:"session_id,omitempty"`
+	BookingID *uuid.UUID `json:"booking_id,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func NewCreditEntryResponse(e credit.Entry) CreditEntryResponse {
+	return CreditEntryResponse{
+		ID:        e.ID,
+		UserID:    e.UserID,
+		Delta:     e.Delta,
+		Balance:   e.Balance,
+		Reason:    string(e.Reason),
+		PenaltyID: e.PenaltyID,
+		SessionID: e.SessionID,
+		BookingID: e.BookingID,
+		CreatedAt: e.CreatedAt,
+	}
+}
+
+type RecoveryReportResponse struct {
+	StartedAt        time.Time `json:"started_at"`
+	FinishedAt       time.Time `json:"finished_at"`
+	LockAcquired     bool      `json:"lock_acquired"`
+	PenaltiesExpired int       `json:"penalties_expired"`
+	SessionsRewarded int       `json:"sessions_rewarded"`
+	BookingsRewarded int       `json:"bookings_rewarded"`
+	PointsRestored   int       `json:"points_restored"`
+}
+
+func NewRecoveryReportResponse(r credit.RecoveryReport) RecoveryReportResponse {
+	return RecoveryReportResponse{
+		StartedAt:        r.StartedAt,
+		FinishedAt:       r.FinishedAt,
+		LockAcquired:     r.LockAcquired,
+		PenaltiesExpired: r.PenaltiesExpired,
+		SessionsRewarded: r.SessionsRewarded,
+		BookingsRewarded: r.BookingsRewarded,
+		PointsRestored:   r.PointsRestored,
+	}
+}
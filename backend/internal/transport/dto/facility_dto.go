@@ -9,38 +9,51 @@ import (
 )
 
 type CreateFacilityDTO struct {
-	Name        string `json:"name" validate:"required,min=2,max=100"`
-	Type        string `json:"type" validate:"required"`
-	Description string `json:"description" validate:"required,min=5"`
-	Capacity    int    `json:"capacity" validate:"required,min=1"`
-	OpenTime    string `json:"open_time" validate:"required,datetime=15:04"`
-	CloseTime   string `json:"close_time" validate:"required,datetime=15:04"`
-	ImageURL    string `json:"image_url" validate:"url"`
+	Name         string   `json:"name" validate:"required,min=2,max=100"`
+	Type         string   `json:"type" validate:"required"` // name of a facility type, the service checks it exists
+	Description  string   `json:"description" validate:"required,min=5"`
+	Capacity     int      `json:"capacity" validate:"required,min=1"`
+	OpenTime     string   `json:"open_time" validate:"required_without=OpeningHours,omitempty,datetime=15:04"`
+	CloseTime    string   `json:"close_time" validate:"required_without=OpeningHours,omitempty,datetime=15:04"`
+	OpeningHours *WeekDTO `json:"opening_hours"` // the regular week, instead of the same open_time/close_time every day
+	ImageURL     string   `json:"image_url" validate:"url"`
 }
 
 type FacilityResponseDTO struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Type        string    `json:"type"`
-	Description string    `json:"description"`
-	Capacity    int       `json:"capacity"`
-	OpenTime    string    `json:"open_time"`
-	CloseTime   string    `json:"close_time"`
-	ImageURL    string    `json:"image_url"`
-	IsActive    bool      `json:"is_active"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID           string      `json:"id"`
+	Name         string      `json:"name"`
+	Type         string      `json:"type"`
+	Description  string      `json:"description"`
+	Capacity     int         `json:"capacity"`
+	OpenTime     string      `json:"open_time"`  // earliest opening of the regular week, empty when closed all week
+	CloseTime    string      `json:"close_time"` // latest closing of the regular week
+	OpeningHours WeekDTO     `json:"opening_hours"`
+	Seasons      []SeasonDTO `json:"seasons"`
+	ImageURL     string      `json:"image_url"`
+	IsActive     bool        `json:"is_active"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
 }
 
 func (d *CreateFacilityDTO) ToModel() (facility.Facility, error) {
-	openTime, err := time.Parse("15:04", d.OpenTime)
-	if err != nil {
-		return facility.Facility{}, fmt.Errorf("invalid open_time format, expected HH:MM")
-	}
+	var week facility.Week
+	if d.OpeningHours != nil {
+		var err error
+		week, err = d.OpeningHours.ToModel()
+		if err != nil {
+			return facility.Facility{}, err
+		}
+	} else {
+		openTime, err := time.Parse("15:04", d.OpenTime)
+		if err != nil {
+			return facility.Facility{}, fmt.Errorf("invalid open_time format, expected HH:MM")
+		}
 
-	closeTime, err := time.Parse("15:04", d.CloseTime)
-	if err != nil {
-		return facility.Facility{}, fmt.Errorf("invalid close_time format, expected HH:MM")
+		closeTime, err := time.Parse("15:04", d.CloseTime)
+		if err != nil {
+			return facility.Facility{}, fmt.Errorf("invalid close_time format, expected HH:MM")
+		}
+		week = facility.EveryDay(openTime, closeTime)
 	}
 
 	now := time.Now()
@@ -51,8 +64,7 @@ func (d *CreateFacilityDTO) ToModel() (facility.Facility, error) {
 		Type:        d.Type,
 		Description: d.Description,
 		Capacity:    d.Capacity,
-		OpenTime:    openTime,
-		CloseTime:   closeTime,
+		Hours:       facility.OpeningHours{Regular: week},
 		ImageURL:    d.ImageURL,
 		IsActive:    true,
 		CreatedAt:   now,
@@ -66,8 +78,12 @@ func (d *FacilityResponseDTO) ToDTO(f facility.Facility) {
 	d.Type = f.Type
 	d.Description = f.Description
 	d.Capacity = f.Capacity
-	d.OpenTime = f.OpenTime.Format("15:04")
-	d.CloseTime = f.CloseTime.Format("15:04")
+	if open, close, ok := f.Hours.Regular.Span(); ok {
+		d.OpenTime = open.Format("15:04")
+		d.CloseTime = close.Format("15:04")
+	}
+	d.OpeningHours = NewWeekDTO(f.Hours.Regular)
+	d.Seasons = NewSeasonDTOs(f.Hours.Seasons)
 	d.ImageURL = f.ImageURL
 	d.IsActive = f.IsActive
 	d.CreatedAt = f.CreatedAt
@@ -79,10 +95,11 @@ type UpdateFacilityDTO struct {
 	Type        *string `json:"type,omitempty"`
 	Description *string `json:"description,omitempty"`
 	Capacity    *int    `json:"capacity,omitempty"`
-	OpenTime    *string `json:"open_time,omitempty"`
-	CloseTime   *string `json:"close_time,omitempty"`
-	ImageURL    *string `json:"image_url,omitempty"`
-	IsActive    *bool   `json:"is_active,omitempty"`
+	// replaces the regular week. open_time/close_time are only a summary of it in the responses and are ignored here,
+	// clients echo them back on every edit
+	OpeningHours *WeekDTO `json:"opening_hours,omitempty"`
+	ImageURL     *string  `json:"image_url,omitempty"`
+	IsActive     *bool    `json:"is_active,omitempty"`
 }
 
 func (d *UpdateFacilityDTO) ApplyToFacility(f *facility.Facility) error {
@@ -98,19 +115,12 @@ func (d *UpdateFacilityDTO) ApplyToFacility(f *facility.Facility) error {
 	if d.Capacity != nil {
 		f.Capacity = *d.Capacity
 	}
-	if d.OpenTime != nil {
-		t, err := time.Parse("15:04", *d.OpenTime)
-		if err != nil {
-			return err
-		}
-		f.OpenTime = t
-	}
-	if d.CloseTime != nil {
-		t, err := time.Parse("15:04", *d.CloseTime)
+	if d.OpeningHours != nil {
+		week, err := d.OpeningHours.ToModel()
 		if err != nil {
 			return err
 		}
-		f.CloseTime = t
+		f.Hours.Regular = week
 	}
 	if d.ImageURL != nil {
 		f.ImageURL = *d.ImageURL
@@ -122,3 +132,118 @@ func (d *UpdateFacilityDTO) ApplyToFacility(f *facility.Facility) error {
 	f.UpdatedAt = time.Now()
 	return nil
 }
+
+type HoursDTO struct {
+	Open  string `json:"open" validate:"required,datetime=15:04"`  // "08:00"
+	Close string `json:"close" validate:"required,datetime=15:04"` // "22:00"
+}
+
+// WeekDTO is the opening intervals of every weekday, a missing or empty day is closed
+type WeekDTO struct {
+	Monday    []HoursDTO `json:"monday" validate:"dive"`
+	Tuesday   []HoursDTO `json:"tuesday" validate:"dive"`
+	Wednesday []HoursDTO `json:"wednesday" validate:"dive"`
+	Thursday  []HoursDTO `json:"thursday" validate:"dive"`
+	Friday    []HoursDTO `json:"friday" validate:"dive"`
+	Saturday  []HoursDTO `json:"saturday" validate:"dive"`
+	Sunday    []HoursDTO `json:"sunday" validate:"dive"`
+}
+
+func (d *WeekDTO) days() [7]*[]HoursDTO {
+	return [7]*[]HoursDTO{&d.Sunday, &d.Monday, &d.Tuesday, &d.Wednesday, &d.Thursday, &d.Friday, &d.Saturday}
+}
+
+func (d *WeekDTO) ToModel() (facility.Week, error) {
+	var week facility.Week
+	for wd, day := range d.days() {
+		for _, h := range *day {
+			open, err := time.Parse("15:04", h.Open)
+			if err != nil {
+				return week, fmt.Errorf("invalid open format on %s, expected HH:MM", time.Weekday(wd))
+			}
+			close, err := time.Parse("15:04", h.Close)
+			if err != nil {
+				return week, fmt.Errorf("invalid close format on %s, expected HH:MM", time.Weekday(wd))
+			}
+			week[wd] = append(week[wd], facility.Hours{Open: open, Close: close})
+		}
+	}
+	return week, nil
+}
+
+func NewWeekDTO(week facility.Week) WeekDTO {
+	var d WeekDTO
+	for wd, day := range d.days() {
+		*day = make([]HoursDTO, 0, len(week[wd]))
+		for _, h := range week[wd] {
+			*day = append(*day, HoursDTO{Open: h.Open.Format("15:04"), Close: h.Close.Format("15:04")})
+		}
+	}
+	return d
+}
+
+type SeasonRequest struct {
+	Name         string  `json:"name" validate:"required,max=100"`
+	StartDate    string  `json:"start_date" validate:"required"` // "2025-06-01"
+	EndDate      string  `json:"end_date" validate:"required"`   // "2025-08-31", inclusive
+	OpeningHours WeekDTO `json:"opening_hours"`
+}
+
+func (req *SeasonRequest) ToModel(facilityID uuid.UUID) (facility.Season, error) {
+	s := facility.Season{FacilityID: facilityID, Name: req.Name}
+
+	start, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return s, fmt.Errorf("invalid start_date format, expected YYYY-MM-DD: %w", err)
+	}
+	end, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		return s, fmt.Errorf("invalid end_date format, expected YYYY-MM-DD: %w", err)
+	}
+	s.StartDate, s.EndDate = start, end
+
+	s.Week, err = req.OpeningHours.ToModel()
+	return s, err
+}
+
+type SeasonDTO struct {
+	ID           uuid.UUID `json:"id"`
+	FacilityID   uuid.UUID `json:"facility_id"`
+	Name         string    `json:"name"`
+	StartDate    string    `json:"start_date"`
+	EndDate      string    `json:"end_date"`
+	OpeningHours WeekDTO   `json:"opening_hours"`
+}
+
+func NewSeasonDTO(s facility.Season) SeasonDTO {
+	return SeasonDTO{
+		ID:           s.ID,
+		FacilityID:   s.FacilityID,
+		Name:         s.Name,
+		StartDate:    s.StartDate.Format("2006-01-02"),
+		EndDate:      s.EndDate.Format("2006-01-02"),
+		OpeningHours: NewWeekDTO(s.Week),
+	}
+}
+
+func NewSeasonDTOs(seasons []facility.Season) []SeasonDTO {
+	resp := make([]SeasonDTO, 0, len(seasons))
+	for _, s := range seasons {
+		resp = append(resp, NewSeasonDTO(s))
+	}
+	return resp
+}
+
+type OpeningHoursResponse struct {
+	FacilityID   uuid.UUID   `json:"facility_id"`
+	OpeningHours WeekDTO     `json:"opening_hours"`
+	Seasons      []SeasonDTO `json:"seasons"`
+}
+
+func NewOpeningHoursResponse(facilityID uuid.UUID, o facility.OpeningHours) OpeningHoursResponse {
+	return OpeningHoursResponse{
+		FacilityID:   facilityID,
+		OpeningHours: NewWeekDTO(o.Regular),
+		Seasons:      NewSeasonDTOs(o.Seasons),
+	}
+}
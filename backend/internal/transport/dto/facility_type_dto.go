@@ -0,0 +1,64 @@
+package dto
+
+import (
+	"t/internal/facility"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FacilityTypeRequest creates or replaces a facility type, the rules are the default booking rules of its facilities
+type FacilityTypeRequest struct {
+	Name                string `json:"name" validate:"required,min=2,max=50"`
+	Icon                string `json:"icon" validate:"max=100"`
+	Description         string `json:"description" validate:"max=500"`
+	MaxUpcomingBookings *int   `json:"max_upcoming_bookings" validate:"omitempty,min=0"`
+	MinCreditScore      *int   `json:"min_credit_score"`
+	MaxBookingsPerDay   *int   `json:"max_bookings_per_day" validate:"omitempty,min=0"`
+	MinSlotMinutes      *int   `json:"min_slot_minutes" validate:"omitempty,min=1"`
+	MaxSlotMinutes      *int   `json:"max_slot_minutes" validate:"omitempty,min=1"`
+}
+
+func (r *FacilityTypeRequest) ToModel(id uuid.UUID) facility.FacilityType {
+	return facility.FacilityType{
+		ID:                  id,
+		Name:                r.Name,
+		Icon:                r.Icon,
+		Description:         r.Description,
+		MaxUpcomingBookings: r.MaxUpcomingBookings,
+		MinCreditScore:      r.MinCreditScore,
+		MaxBookingsPerDay:   r.MaxBookingsPerDay,
+		MinSlotMinutes:      r.MinSlotMinutes,
+		MaxSlotMinutes:      r.MaxSlotMinutes,
+	}
+}
+
+type FacilityTypeResponse struct {
+	ID                  uuid.UUID `json:"id"`
+	Name                string    `json:"name"`
+	Icon                string    `json:"icon"`
+	Description         string    `json:"description"`
+	MaxUpcomingBookings *int      `json:"max_upcoming_bookings"`
+	MinCreditScore      *int      `json:"min_credit_score"`
+	MaxBookingsPerDay   *int      `json:"max_bookings_per_day"`
+	MinSlotMinutes      *int      `json:"min_slot_minutes"`
+	MaxSlotMinutes      *int      `json:"max_slot_minutes"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+func NewFacilityTypeResponse(t facility.FacilityType) FacilityTypeResponse {
+	return FacilityTypeResponse{
+		ID:                  t.ID,
+		Name:                t.Name,
+		Icon:                t.Icon,
+		Description:         t.Description,
+		MaxUpcomingBookings: t.MaxUpcomingBookings,
+		MinCreditScore:      t.MinCreditScore,
+		MaxBookingsPerDay:   t.MaxBookingsPerDay,
+		MinSlotMinutes:      t.MinSlotMinutes,
+		MaxSlotMinutes:      t.MaxSlotMinutes,
+		CreatedAt:           t.CreatedAt,
+		UpdatedAt:           t.UpdatedAt,
+	}
+}
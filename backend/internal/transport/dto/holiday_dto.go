@@ -0,0 +1,105 @@
+package dto
+
+import (
+	"fmt"
+	"t/internal/holiday"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateHolidayRequest struct {
+	Name      string `json:"name" validate:"required,max=200"`
+	StartDate string `json:"start_date" validate:"required"` // "2025-12-22"
+	EndDate   string `json:"end_date"`                       // "2026-01-02", inclusive, defaults to start_date
+}
+
+func (req *CreateHolidayRequest) ToModel(createdBy uuid.UUID) (holiday.Holiday, error) {
+	h := holiday.Holiday{Name: req.Name, CreatedBy: &createdBy}
+
+	start, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return h, fmt.Errorf("invalid start_date format, expected YYYY-MM-DD: %w", err)
+	}
+	h.StartDate, h.EndDate = start, start
+
+	if req.EndDate != "" {
+		h.EndDate, err = time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return h, fmt.Errorf("invalid end_date format, expected YYYY-MM-DD: %w", err)
+		}
+	}
+	return h, nil
+}
+
+type HolidayResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	Name      string     `json:"name"`
+	StartDate string     `json:"start_date"`
+	EndDate   string     `json:"end_date"`
+	UID       string     `json:"uid,omitempty"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func NewHolidayResponse(h holiday.Holiday) HolidayResponse {
+	return HolidayResponse{
+		ID:        h.ID,
+		Name:      h.Name,
+		StartDate: h.StartDate.Format("2006-01-02"),
+		EndDate:   h.EndDate.Format("2006-01-02"),
+		UID:       h.UID,
+		CreatedBy: h.CreatedBy,
+		CreatedAt: h.CreatedAt,
+	}
+}
+
+func NewHolidayResponses(holidays []holiday.Holiday) []HolidayResponse {
+	resp := make([]HolidayResponse, 0, len(holidays))
+	for _, h := range holidays {
+		resp = append(resp, NewHolidayResponse(h))
+	}
+	return resp
+}
+
+type HolidayResultResponse struct {
+	Holiday          HolidayResponse `json:"holiday"`
+	CanceledSessions []uuid.UUID     `json:"canceled_sessions"`
+}
+
+func NewHolidayResultResponse(r holiday.Result) HolidayResultResponse {
+	canceled := r.CanceledSessions
+	if canceled == nil {
+		canceled = make([]uuid.UUID, 0)
+	}
+	return HolidayResultResponse{
+		Holiday:          NewHolidayResponse(r.Holiday),
+		CanceledSessions: canceled,
+	}
+}
+
+type SkippedEventResponse struct {
+	UID     string `json:"uid,omitempty"`
+	Summary string `json:"summary"`
+	Reason  string `json:"reason"`
+}
+
+type HolidayImportResponse struct {
+	Created          []HolidayResponse      `json:"created"`
+	Updated          []HolidayResponse      `json:"updated"`
+	Skipped          []SkippedEventResponse `json:"skipped"`
+	CanceledSessions []uuid.UUID            `json:"canceled_sessions"`
+}
+
+func NewHolidayImportResponse(r holiday.ImportReport) HolidayImportResponse {
+	skipped := make([]SkippedEventResponse, 0, len(r.Skipped))
+	for _, e := range r.Skipped {
+		skipped = append(skipped, SkippedEventResponse{UID: e.UID, Summary: e.Summary, Reason: e.Reason})
+	}
+	return HolidayImportResponse{
+		Created:          NewHolidayResponses(r.Created),
+		Updated:          NewHolidayResponses(r.Updated),
+		Skipped:          skipped,
+		CanceledSessions: r.CanceledSessions,
+	}
+}
@@ -0,0 +1,106 @@
+package dto
+
+import (
+	"t/internal/notification"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type NotificationResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Type       string     `json:"type"`
+	Title      string     `json:"title"`
+	Body       string     `json:"body"`
+	EntityType string     `json:"entity_type,omitempty"`
+	EntityID   *uuid.UUID `json:"entity_id,omitempty"`
+	Read       bool       `json:"read"`
+	ReadAt     *time.Time `json:"read_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func NewNotificationResponse(n notification.Notification) NotificationResponse {
+	return NotificationResponse{
+		ID:         n.ID,
+		Type:       string(n.Type),
+		Title:      n.Title,
+		Body:       n.Body,
+		EntityType: n.EntityType,
+		EntityID:   n.EntityID,
+		Read:       n.ReadAt != nil,
+		ReadAt:     n.ReadAt,
+		CreatedAt:  n.CreatedAt,
+	}
+}
+
+type UnreadCountResponse struct {
+	Unread int `json:"unread"`
+}
+
+type MarkedReadResponse struct {
+	Marked int `json:"marked"`
+}
+
+type PreferenceResponse struct {
+	Channel string `json:"channel"`
+	Enabled bool   `json:"enabled"`
+	Default bool   `json:"default"` // the user never chose, the configured default applies
+}
+
+func NewPreferenceResponse(p notification.Preference) PreferenceResponse {
+	return PreferenceResponse{
+		Channel: string(p.Channel),
+		Enabled: p.Enabled,
+		Default: p.Default,
+	}
+}
+
+// UpdatePreferencesRequest turns channels on or off, channels left out keep their setting
+type UpdatePreferencesRequest struct {
+	Channels map[string]bool `json:"channels" validate:"required,min=1"`
+}
+
+type DeliveryResponse struct {
+	ID            uuid.UUID  `json:"id"`
+	Channel       string     `json:"channel"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"last_error,omitempty"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+func NewDeliveryResponse(d notification.Delivery) DeliveryResponse {
+	resp := DeliveryResponse{
+		ID:        d.ID,
+		Channel:   string(d.Channel),
+		Status:    string(d.Status),
+		Attempts:  d.Attempts,
+		LastError: d.LastError,
+		SentAt:    d.SentAt,
+		CreatedAt: d.CreatedAt,
+	}
+	if d.Status == notification.DeliveryPending {
+		resp.NextAttemptAt = &d.NextAttemptAt
+	}
+	return resp
+}
+
+type DeliveryReportResponse struct {
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Sent       int       `json:"sent"`
+	Retrying   int       `json:"retrying"`
+	Failed     int       `json:"failed"`
+}
+
+func NewDeliveryReportResponse(r notification.DeliveryReport) DeliveryReportResponse {
+	return DeliveryReportResponse{
+		StartedAt:  r.StartedAt,
+		FinishedAt: r.FinishedAt,
+		Sent:       r.Sent,
+		Retrying:   r.Retrying,
+		Failed:     r.Failed,
+	}
+}
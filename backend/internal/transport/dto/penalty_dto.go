@@ -40,6 +40,8 @@ type PenaltyResponse struct {
 	PenaltyType  string     `json:"penalty_type"`
 	CreatedAt    time.Time  `json:"created_at"`
 	UpdatedAt    time.Time  `json:"updated_at"`
+	RestoredAt   *time.Time `json:"restored_at,omitempty"`
+	IsAutomatic  bool       `json:"is_automatic"`
 	FacilityName string     `json:"facility_name,omitempty"`
 	SessionDate  *time.Time `json:"session_date,omitempty"`
 	BookingDate  *time.Time `json:"booking_date,omitempty"`
@@ -58,9 +60,31 @@ func (p *PenaltyResponse) FromModel(m penalty.Penalty) {
 	p.PenaltyType = m.PenaltyType
 	p.CreatedAt = m.CreatedAt
 	p.UpdatedAt = m.UpdatedAt
+	p.RestoredAt = m.RestoredAt
+	p.IsAutomatic = m.IsAutomatic
 	p.FacilityName = m.FacilityName
 	p.SessionDate = m.SessionDate
 	p.BookingDate = m.BookingDate
 	p.ContextInfo = m.ContextInfo
 	p.UserName = m.UserName
 }
+
+// CancelResultResponse tells the owner whether the cancellation was on time or late, and the late penalty if one was given
+type CancelResultResponse struct {
+	Outcome string               `json:"outcome"`
+	Penalty *LatePenaltyResponse `json:"penalty,omitempty"`
+}
+
+type LatePenaltyResponse struct {
+	ID     uuid.UUID `json:"id"`
+	Points int       `json:"points"`
+	Reason string    `json:"reason"`
+}
+
+func NewCancelResultResponse(r penalty.CancelResult) CancelResultResponse {
+	resp := CancelResultResponse{Outcome: string(r.Outcome)}
+	if r.Penalty != nil {
+		resp.Penalty = &LatePenaltyResponse{ID: r.Penalty.ID, Points: r.Penalty.Points, Reason: r.Penalty.Reason}
+	}
+	return resp
+}
@@ -0,0 +1,149 @@
+package dto
+
+import (
+	"t/internal/policy"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreatePolicyRequest struct {
+	Name                string     `json:"name" validate:"required,min=2,max=100"`
+	FacilityID          *uuid.UUID `json:"facility_id"`
+	Role                *string    `json:"role" validate:"omitempty,oneof=admin staff student trainer"`
+	MaxUpcomingBookings *int       `json:"max_upcoming_bookings" validate:"omitempty,min=0"`
+	MinCreditScore      *int       `json:"min_credit_score"`
+	MaxBookingsPerDay   *int       `json:"max_bookings_per_day" validate:"omitempty,min=0"`
+	MinSlotMinutes      *int       `json:"min_slot_minutes" validate:"omitempty,min=1"`
+	MaxSlotMinutes      *int       `json:"max_slot_minutes" validate:"omitempty,min=1"`
+}
+
+func (r *CreatePolicyRequest) ToModel() policy.Policy {
+	return policy.Policy{
+		Name:                r.Name,
+		FacilityID:          r.FacilityID,
+		Role:                r.Role,
+		MaxUpcomingBookings: r.MaxUpcomingBookings,
+		MinCreditScore:      r.MinCreditScore,
+		MaxBookingsPerDay:   r.MaxBookingsPerDay,
+		MinSlotMinutes:      r.MinSlotMinutes,
+		MaxSlotMinutes:      r.MaxSlotMinutes,
+		IsActive:            true,
+	}
+}
+
+// the scope (facility/role) of a policy cannot be changed, create a new one instead
+type UpdatePolicyRequest struct {
+	Name                *string `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
+	MaxUpcomingBookings *int    `json:"max_upcoming_bookings,omitempty" validate:"omitempty,min=0"`
+	MinCreditScore      *int    `json:"min_credit_score,omitempty"`
+	MaxBookingsPerDay   *int    `json:"max_bookings_per_day,omitempty" validate:"omitempty,min=0"`
+	MinSlotMinutes      *int    `json:"min_slot_minutes,omitempty" validate:"omitempty,min=1"`
+	MaxSlotMinutes      *int    `json:"max_slot_minutes,omitempty" validate:"omitempty,min=1"`
+	IsActive            *bool   `json:"is_active,omitempty"`
+}
+
+func (r *UpdatePolicyRequest) ApplyToPolicy(p *policy.Policy) {
+	if r.Name != nil {
+		p.Name = *r.Name
+	}
+	if r.MaxUpcomingBookings != nil {
+		p.MaxUpcomingBookings = r.MaxUpcomingBookings
+	}
+	if r.MinCreditScore != nil {
+		p.MinCreditScore = r.MinCreditScore
+	}
+	if r.MaxBookingsPerDay != nil {
+		p.MaxBookingsPerDay = r.MaxBookingsPerDay
+	}
+	if r.MinSlotMinutes != nil {
+		p.MinSlotMinutes = r.MinSlotMinutes
+	}
+	if r.MaxSlotMinutes != nil {
+		p.MaxSlotMinutes = r.MaxSlotMinutes
+	}
+	if r.IsActive != nil {
+		p.IsActive = *r.IsActive
+	}
+}
+
+type PolicyResponse struct {
+	ID                  uuid.UUID  `json:"id"`
+	Name                string     `json:"name"`
+	Scope               string     `json:"scope"`
+	FacilityID          *uuid.UUID `json:"facility_id"`
+	Role                *string    `json:"role"`
+	MaxUpcomingBookings *int       `json:"max_upcoming_bookings"`
+	MinCreditScore      *int       `json:"min_credit_score"`
+	MaxBookingsPerDay   *int       `json:"max_bookings_per_day"`
+	MinSlotMinutes      *int       `json:"min_slot_minutes"`
+	MaxSlotMinutes      *int       `json:"max_slot_minutes"`
+	IsActive            bool       `json:"is_active"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+func NewPolicyResponse(p policy.Policy) PolicyResponse {
+	return PolicyResponse{
+		ID:                  p.ID,
+		Name:                p.Name,
+		Scope:               p.Scope(),
+		FacilityID:          p.FacilityID,
+		Role:                p.Role,
+		MaxUpcomingBookings: p.MaxUpcomingBookings,
+		MinCreditScore:      p.MinCreditScore,
+		MaxBookingsPerDay:   p.MaxBookingsPerDay,
+		MinSlotMinutes:      p.MinSlotMinutes,
+		MaxSlotMinutes:      p.MaxSlotMinutes,
+		IsActive:            p.IsActive,
+		CreatedAt:           p.CreatedAt,
+		UpdatedAt:           p.UpdatedAt,
+	}
+}
+
+type EffectiveRuleResponse struct {
+	Rule       string    `json:"rule"`
+	Value      int       `json:"value"`
+	PolicyID   uuid.UUID `json:"policy_id"`
+	PolicyName string    `json:"policy_name"`
+	Scope      string    `json:"scope"`
+}
+
+func NewEffectivePolicyResponse(e policy.Effective) []EffectiveRuleResponse {
+	resp := make([]EffectiveRuleResponse, 0, len(e.Rules))
+	for _, rule := range policy.Rules {
+		r, ok := e.Get(rule)
+		if !ok {
+			continue
+		}
+		resp = append(resp, EffectiveRuleResponse{
+			Rule:       string(rule),
+			Value:      r.Value,
+			PolicyID:   r.PolicyID,
+			PolicyName: r.PolicyName,
+			Scope:      r.Scope,
+		})
+	}
+	return resp
+}
+
+// PolicyViolationResponse is sent with the error when a booking policy rejected the request
+type PolicyViolationResponse struct {
+	Rule       string    `json:"rule"`
+	Limit      int       `json:"limit"`
+	Actual     int       `json:"actual"`
+	PolicyID   uuid.UUID `json:"policy_id"`
+	PolicyName string    `json:"policy_name"`
+	Scope      string    `json:"scope"`
+}
+
+func NewPolicyViolationResponse(v *policy.Violation) PolicyViolationResponse {
+	return PolicyViolationResponse{
+		Rule:       string(v.Rule),
+		Limit:      v.Limit,
+		Actual:     v.Actual,
+		PolicyID:   v.Source.PolicyID,
+		PolicyName: v.Source.PolicyName,
+		Scope:      v.Source.Scope,
+	}
+}
@@ -18,15 +18,22 @@ func (r *CreateRegistrationRequest) ToDomain(userID uuid.UUID) registration.Regi
 	}
 }
 
+const (
+	RegistrationStatusRegistered = "registered"
+	RegistrationStatusWaitlisted = "waitlisted"
+)
+
 type RegistrationResponse struct {
-	ID         uuid.UUID        `json:"id"`
-	SessionID  uuid.UUID        `json:"session_id"`
-	UserID     uuid.UUID        `json:"user_id"`
-	IsCanceled bool             `json:"is_canceled"`
-	CreatedAt  string           `json:"created_at"`
-	UpdatedAt  string           `json:"updated_at"`
-	User       *UserResponseDTO `json:"user,omitempty"`
-	Session    *SessionResponse `json:"session,omitempty"`
+	ID               uuid.UUID        `json:"id"`
+	SessionID        uuid.UUID        `json:"session_id"`
+	UserID           uuid.UUID        `json:"user_id"`
+	IsCanceled       bool             `json:"is_canceled"`
+	Status           string           `json:"status"`
+	WaitlistPosition int              `json:"waitlist_position,omitempty"`
+	CreatedAt        string           `json:"created_at"`
+	UpdatedAt        string           `json:"updated_at"`
+	User             *UserResponseDTO `json:"user,omitempty"`
+	Session          *SessionResponse `json:"session,omitempty"`
 }
 
 func NewRegistrationResponse(r registration.Registration) RegistrationResponse {
@@ -35,10 +42,16 @@ func NewRegistrationResponse(r registration.Registration) RegistrationResponse {
 		SessionID:  r.SessionID,
 		UserID:     r.UserID,
 		IsCanceled: r.IsCanceled,
+		Status:     RegistrationStatusRegistered,
 		CreatedAt:  r.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:  r.UpdatedAt.Format(time.RFC3339),
 	}
 
+	if r.IsWaitlisted {
+		resp.Status = RegistrationStatusWaitlisted
+		resp.WaitlistPosition = r.WaitlistPosition
+	}
+
 	if r.User != nil {
 		userDTO := &UserResponseDTO{}
 		userDTO.FromModel(*r.User)
@@ -14,6 +14,8 @@ type CreateScheduleRequest struct {
 	StartTime  string    `json:"start_time" validate:"required"` // Format: "15:04"
 	EndTime    string    `json:"end_time" validate:"required"`   // Format: "15:04"
 	Capacity   int       `json:"capacity" validate:"required,min=1"`
+	// create the schedule even if bookings or sessions occupy some of its dates, those dates get no session
+	SkipConflicts bool `json:"skip_conflicts"`
 }
 
 func (req *CreateScheduleRequest) ToDomain() (*schedule.Schedule, error) {
@@ -62,3 +64,24 @@ func NewScheduleResponse(s schedule.Schedule) ScheduleResponse {
 		UpdatedAt:  s.UpdatedAt,
 	}
 }
+
+// ScheduleConflictResponse lists what the new schedule collides with on the facility
+type ScheduleConflictResponse struct {
+	Schedule  ScheduleResponse   `json:"schedule"`
+	Created   bool               `json:"created"`
+	Schedules []ScheduleResponse `json:"schedules"`
+	Occupied  []OccupiedResponse `json:"occupied"`
+}
+
+func NewScheduleConflictResponse(r schedule.ConflictReport) ScheduleConflictResponse {
+	resp := ScheduleConflictResponse{
+		Schedule:  NewScheduleResponse(r.Schedule),
+		Created:   r.Created,
+		Schedules: make([]ScheduleResponse, 0, len(r.Schedules)),
+		Occupied:  NewOccupiedResponses(r.Occupied),
+	}
+	for _, sch := range r.Schedules {
+		resp.Schedules = append(resp.Schedules, NewScheduleResponse(sch))
+	}
+	return resp
+}
@@ -53,6 +53,7 @@ type SessionResponse struct {
 	EndTime         string    `json:"end_time"`
 	Capacity        int       `json:"capacity"`
 	IsCanceled      bool      `json:"is_canceled"`
+	CancelReason    string    `json:"cancel_reason,omitempty"`
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
 	RegisteredCount int       `json:"registered_count"`
@@ -69,8 +70,99 @@ func NewSessionResponse(s session.Session) SessionResponse {
 		EndTime:         s.EndTime.Format("15:04"),
 		Capacity:        s.Capacity,
 		IsCanceled:      s.IsCanceled,
+		CancelReason:    s.CancelReason,
 		CreatedAt:       s.CreatedAt,
 		UpdatedAt:       s.UpdatedAt,
 		RegisteredCount: s.RegisteredCount,
 	}
 }
+
+type GeneratedSessionResponse struct {
+	SessionID  uuid.UUID `json:"session_id"`
+	ScheduleID uuid.UUID `json:"schedule_id"`
+	Date       string    `json:"date"`
+}
+
+type GenerationReportResponse struct {
+	StartedAt        time.Time                  `json:"started_at"`
+	FinishedAt       time.Time                  `json:"finished_at"`
+	HorizonWeeks     int                        `json:"horizon_weeks"`
+	LockAcquired     bool                       `json:"lock_acquired"`
+	SchedulesScanned int                        `json:"schedules_scanned"`
+	CreatedCount     int                        `json:"created_count"`
+	Created          []GeneratedSessionResponse `json:"created"`
+	Skipped          []SkippedSessionResponse   `json:"skipped"`
+}
+
+// SkippedSessionResponse is a date left out because the facility is occupied
+type SkippedSessionResponse struct {
+	ScheduleID uuid.UUID          `json:"schedule_id"`
+	Date       string             `json:"date"`
+	Conflicts  []OccupiedResponse `json:"conflicts"`
+}
+
+func NewGenerationReportResponse(r session.GenerationReport) GenerationReportResponse {
+	created := make([]GeneratedSessionResponse, 0, len(r.Created))
+	for _, c := range r.Created {
+		created = append(created, GeneratedSessionResponse{
+			SessionID:  c.SessionID,
+			ScheduleID: c.ScheduleID,
+			Date:       c.Date.Format("2006-01-02"),
+		})
+	}
+
+	skipped := make([]SkippedSessionResponse, 0, len(r.Skipped))
+	for _, sk := range r.Skipped {
+		skipped = append(skipped, SkippedSessionResponse{
+			ScheduleID: sk.ScheduleID,
+			Date:       sk.Date.Format("2006-01-02"),
+			Conflicts:  NewOccupiedResponses(sk.Conflicts),
+		})
+	}
+
+	return GenerationReportResponse{
+		StartedAt:        r.StartedAt,
+		FinishedAt:       r.FinishedAt,
+		HorizonWeeks:     r.HorizonWeeks,
+		LockAcquired:     r.LockAcquired,
+		SchedulesScanned: r.SchedulesScanned,
+		CreatedCount:     len(r.Created),
+		Created:          created,
+		Skipped:          skipped,
+	}
+}
+
+type CancelSessionRequest struct {
+	Reason string `json:"reason" validate:"max=500"` // optional, sent to the registered users
+}
+
+type RefundResponse struct {
+	PenaltyID uuid.UUID `json:"penalty_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Points    int       `json:"points"`
+}
+
+type CancellationResponse struct {
+	SessionID             uuid.UUID        `json:"session_id"`
+	Reason                string           `json:"reason"`
+	AffectedUsers         []uuid.UUID      `json:"affected_users"`
+	RegistrationsCanceled int              `json:"registrations_canceled"`
+	WaitlistCanceled      int              `json:"waitlist_canceled"`
+	Refunds               []RefundResponse `json:"refunds"`
+}
+
+func NewCancellationResponse(c session.Cancellation) CancellationResponse {
+	refunds := make([]RefundResponse, 0, len(c.Refunds))
+	for _, rf := range c.Refunds {
+		refunds = append(refunds, RefundResponse{PenaltyID: rf.PenaltyID, UserID: rf.UserID, Points: rf.Points})
+	}
+
+	return CancellationResponse{
+		SessionID:             c.SessionID,
+		Reason:                c.Reason,
+		AffectedUsers:         c.AffectedUsers,
+		RegistrationsCanceled: c.RegistrationsCanceled,
+		WaitlistCanceled:      c.WaitlistCanceled,
+		Refunds:               refunds,
+	}
+}
@@ -35,17 +35,57 @@ type UpdateUserDTO struct {
 	Phone     string `json:"phone,omitempty" validate:"omitempty,e164"`
 }
 
+func (d *UpdateUserDTO) ApplyToUser(u *user.User) {
+	if d.FirstName != "" {
+		u.FirstName = d.FirstName
+	}
+	if d.LastName != "" {
+		u.LastName = d.LastName
+	}
+	if d.Phone != "" {
+		u.Phone = d.Phone
+	}
+}
+
+// AdminUpdateUserDTO is what an admin can change on any account
+type AdminUpdateUserDTO struct {
+	UpdateUserDTO
+	Role     *string `json:"role,omitempty" validate:"omitempty,oneof=student staff admin"`
+	IsActive *bool   `json:"is_active,omitempty"`
+}
+
+func (d *AdminUpdateUserDTO) ApplyToUser(u *user.User) {
+	d.UpdateUserDTO.ApplyToUser(u)
+	if d.Role != nil {
+		u.Role = *d.Role
+	}
+	if d.IsActive != nil {
+		u.IsActive = *d.IsActive
+	}
+}
+
+type ChangePasswordDTO struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=6,max=100"`
+}
+
+type DeleteAccountDTO struct {
+	Password string `json:"password" validate:"required"`
+}
+
 type UserResponseDTO struct {
-	ID          string    `json:"id"`
-	Email       string    `json:"email"`
-	FirstName   string    `json:"first_name"`
-	LastName    string    `json:"last_name"`
-	Role        string    `json:"role"`
-	Phone       string    `json:"phone"`
-	CreditScore int       `json:"credit_score"`
-	IsActive    bool      `json:"is_active"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          string `json:"id"`
+	Email       string `json:"email"`
+	FirstName   string `json:"first_name"`
+	LastName    string `json:"last_name"`
+	Role        string `json:"role"`
+	Phone       string `json:"phone"`
+	CreditScore int    `json:"credit_score"`
+	IsActive    bool   `json:"is_active"`
+	// false until the email verification link was used
+	EmailVerified bool      `json:"email_verified"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
 }
 
 func (d *UserResponseDTO) FromModel(u user.User) {
@@ -57,6 +97,7 @@ func (d *UserResponseDTO) FromModel(u user.User) {
 	d.Phone = u.Phone
 	d.CreditScore = u.CreditScore
 	d.IsActive = u.IsActive
+	d.EmailVerified = u.EmailVerifiedAt != nil
 	d.CreatedAt = u.CreatedAt
 	d.UpdatedAt = u.UpdatedAt
 }
@@ -0,0 +1,275 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"t/internal/attendance"
+	"t/internal/auth"
+	"t/internal/transport/dto"
+	"t/pkg/qrcode"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// the registered user, the trainer of the session, staff or admin, enforced on the route
+func (s *Server) CheckInRegistrationHandler(w http.ResponseWriter, r *http.Request) {
+	s.checkIn(w, r, attendance.KindRegistration, "id")
+}
+
+func (s *Server) CheckOutRegistrationHandler(w http.ResponseWriter, r *http.Request) {
+	s.checkOut(w, r, attendance.KindRegistration, "id")
+}
+
+// the trainer of the session, staff or admin, enforced on the route
+func (s *Server) SetRegistrationAttendanceHandler(w http.ResponseWriter, r *http.Request) {
+	s.setAttendance(w, r, attendance.KindRegistration, "id")
+}
+
+// the owner of the booking, staff or admin, enforced on the route
+func (s *Server) CheckInBookingHandler(w http.ResponseWriter, r *http.Request) {
+	s.checkIn(w, r, attendance.KindBooking, "booking_id")
+}
+
+func (s *Server) CheckOutBookingHandler(w http.ResponseWriter, r *http.Request) {
+	s.checkOut(w, r, attendance.KindBooking, "booking_id")
+}
+
+// staff or admin, enforced on the route
+func (s *Server) SetBookingAttendanceHandler(w http.ResponseWriter, r *http.Request) {
+	s.setAttendance(w, r, attendance.KindBooking, "booking_id")
+}
+
+// the owner of the registration, staff or admin, enforced on the route
+func (s *Server) GetRegistrationCheckInTokenHandler(w http.ResponseWriter, r *http.Request) {
+	s.checkInToken(w, r, attendance.KindRegistration, "id")
+}
+
+func (s *Server) GetRegistrationCheckInQRHandler(w http.ResponseWriter, r *http.Request) {
+	s.checkInQR(w, r, attendance.KindRegistration, "id")
+}
+
+// the owner of the booking, staff or admin, enforced on the route
+func (s *Server) GetBookingCheckInTokenHandler(w http.ResponseWriter, r *http.Request) {
+	s.checkInToken(w, r, attendance.KindBooking, "booking_id")
+}
+
+func (s *Server) GetBookingCheckInQRHandler(w http.ResponseWriter, r *http.Request) {
+	s.checkInQR(w, r, attendance.KindBooking, "booking_id")
+}
+
+// TokenCheckInHandler checks in the owner of a scanned QR code. trainers, staff and admins on the route,
+// trainers only for registrations of their own sessions, checked by the service
+func (s *Server) TokenCheckInHandler(w http.ResponseWriter, r *http.Request) {
+	var req dto.TokenCheckInRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "malformed input")
+		return
+	}
+	if err := s.validator.Struct(req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "Validation failed: "+err.Error())
+		return
+	}
+
+	userID, err := GetID(r.Context())
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, nil, "invalid userID in context")
+		return
+	}
+	scanner := attendance.Actor{UserID: userID, Privileged: hasRole(r.Context(), auth.STAFF, auth.ADMIN)}
+
+	rec, err := s.attendanceService.CheckInWithToken(r.Context(), req.Token, scanner)
+	if err != nil {
+		s.respondWithError(w, err, "Failed to check in")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, dto.NewAttendanceResponse(rec), "Successfully checked in")
+}
+
+// the trainer of the session, staff or admin, enforced on the route
+func (s *Server) GetSessionRosterHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "Invalid session ID")
+		return
+	}
+
+	roster, err := s.attendanceService.GetRoster(r.Context(), sessionID)
+	if err != nil {
+		s.respondWithError(w, err, "Failed to get the roster")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, dto.NewRosterResponse(roster), "Successfully got the roster")
+}
+
+// CloseAttendanceHandler runs the attendance closing job right away, admin only
+func (s *Server) CloseAttendanceHandler(w http.ResponseWriter, r *http.Request) {
+	report, err := s.attendanceService.CloseFinished(r.Context())
+	if err != nil {
+		s.respondWithError(w, err, "Failed to close the attendance")
+		return
+	}
+
+	if !report.LockAcquired {
+		respondWithJSON(w, http.StatusConflict, dto.NewClosingReportResponse(report), "Closing is already running on another instance")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, dto.NewClosingReportResponse(report), "Successfully closed the attendance")
+}
+
+func (s *Server) checkIn(w http.ResponseWriter, r *http.Request, kind attendance.Kind, param string) {
+	id, actor, ok := s.attendanceRequest(w, r, kind, param)
+	if !ok {
+		return
+	}
+
+	rec, err := s.attendanceService.CheckIn(r.Context(), kind, id, actor)
+	if err != nil {
+		s.respondWithError(w, err, "Failed to check in")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, dto.NewAttendanceResponse(rec), "Successfully checked in")
+}
+
+func (s *Server) checkOut(w http.ResponseWriter, r *http.Request, kind attendance.Kind, param string) {
+	id, actor, ok := s.attendanceRequest(w, r, kind, param)
+	if !ok {
+		return
+	}
+
+	rec, err := s.attendanceService.CheckOut(r.Context(), kind, id, actor)
+	if err != nil {
+		s.respondWithError(w, err, "Failed to check out")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, dto.NewAttendanceResponse(rec), "Successfully checked out")
+}
+
+func (s *Server) setAttendance(w http.ResponseWriter, r *http.Request, kind attendance.Kind, param string) {
+	var req dto.SetAttendanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "malformed input")
+		return
+	}
+	if err := s.validator.Struct(req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "Validation failed: "+err.Error())
+		return
+	}
+
+	id, actor, ok := s.attendanceRequest(w, r, kind, param)
+	if !ok {
+		return
+	}
+
+	rec, err := s.attendanceService.SetStatus(r.Context(), kind, id, attendance.Status(req.Status), actor)
+	if err != nil {
+		s.respondWithError(w, err, "Failed to set the attendance")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, dto.NewAttendanceResponse(rec), "Successfully set the attendance")
+}
+
+// attendanceRequest parses the id from the path and works out whether the caller is bound to the self check-in window.
+// staff and admins never are, for registrations neither is the trainer of the session
+func (s *Server) attendanceRequest(w http.ResponseWriter, r *http.Request, kind attendance.Kind, param string) (uuid.UUID, attendance.Actor, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, param))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "Invalid ID")
+		return uuid.Nil, attendance.Actor{}, false
+	}
+
+	userID, err := GetID(r.Context())
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, nil, "invalid userID in context")
+		return uuid.Nil, attendance.Actor{}, false
+	}
+
+	actor := attendance.Actor{UserID: userID, Privileged: hasRole(r.Context(), auth.STAFF, auth.ADMIN)}
+	if !actor.Privileged && kind == attendance.KindRegistration {
+		trainerID, err := s.registrationTrainer(r)
+		if err != nil {
+			s.respondWithError(w, err, "Failed to get the registration")
+			return uuid.Nil, attendance.Actor{}, false
+		}
+		actor.Privileged = trainerID == userID
+	}
+	return id, actor, true
+}
+
+func (s *Server) checkInToken(w http.ResponseWriter, r *http.Request, kind attendance.Kind, param string) {
+	id, err := uuid.Parse(chi.URLParam(r, param))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "Invalid ID")
+		return
+	}
+
+	token, err := s.attendanceService.IssueToken(r.Context(), kind, id)
+	if err != nil {
+		s.respondWithError(w, err, "Failed to issue the check-in token")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, dto.NewCheckInTokenResponse(token), "Successfully issued the check-in token")
+}
+
+// checkInQR renders a fresh check-in token as a QR code, ?format=png (default) or svg, ?scale= pixels per module
+func (s *Server) checkInQR(w http.ResponseWriter, r *http.Request, kind attendance.Kind, param string) {
+	id, err := uuid.Parse(chi.URLParam(r, param))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "Invalid ID")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "png"
+	}
+	if format != "png" && format != "svg" {
+		respondWithJSON(w, http.StatusBadRequest, nil, "format must be png or svg")
+		return
+	}
+
+	scale := 8
+	if v := r.URL.Query().Get("scale"); v != "" {
+		scale, err = strconv.Atoi(v)
+		if err != nil || scale < 1 || scale > 32 {
+			respondWithJSON(w, http.StatusBadRequest, nil, "scale must be between 1 and 32")
+			return
+		}
+	}
+
+	token, err := s.attendanceService.IssueToken(r.Context(), kind, id)
+	if err != nil {
+		s.respondWithError(w, err, "Failed to issue the check-in token")
+		return
+	}
+
+	code, err := qrcode.Encode([]byte(token.Value), qrcode.M)
+	if err != nil {
+		s.respondWithError(w, err, "Failed to render the QR code")
+		return
+	}
+
+	//the token inside is short-lived, the image must not be cached
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Token-Expires-At", token.ExpiresAt.UTC().Format(time.RFC3339))
+	if format == "svg" {
+		w.Header().Set("Content-Type", "image/svg+xml")
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, code.SVG(scale))
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := code.WritePNG(&buf, scale); err != nil {
+		s.respondWithError(w, err, "Failed to render the QR code")
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"t/internal/audit"
+	"t/internal/transport/dto"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ListAuditLogHandler lists the audit log newest first, admin only.
+// filters: actor_id, entity_type, entity_id, action, from, to (RFC3339 or YYYY-MM-DD, to is exclusive), offset
+func (s *Server) ListAuditLogHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	f := audit.Filter{
+		EntityType: q.Get("entity_type"),
+		Action:     q.Get("action"),
+	}
+
+	for param, dst := range map[string]**uuid.UUID{"actor_id": &f.ActorID, "entity_id": &f.EntityID} {
+		if v := q.Get(param); v != "" {
+			id, err := uuid.Parse(v)
+			if err != nil {
+				respondWithJSON(w, http.StatusBadRequest, nil, "Invalid "+param)
+				return
+			}
+			*dst = &id
+		}
+	}
+
+	for param, dst := range map[string]**time.Time{"from": &f.From, "to": &f.To} {
+		if v := q.Get(param); v != "" {
+			t, err := parseAuditTime(v)
+			if err != nil {
+				respondWithJSON(w, http.StatusBadRequest, nil, "Invalid "+param+" (RFC3339 or YYYY-MM-DD)")
+				return
+			}
+			*dst = &t
+		}
+	}
+
+	if v := q.Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil {
+			respondWithJSON(w, http.StatusBadRequest, nil, "offset should be interger")
+			return
+		}
+		f.Offset = offset
+	}
+
+	entries, err := s.auditService.List(r.Context(), f)
+	if err != nil {
+		s.respondWithError(w, err, "Failed to list the audit log")
+		return
+	}
+
+	resp := make([]dto.AuditEntryResponse, 0, len(entries))
+	for _, e := range entries {
+		resp = append(resp, dto.NewAuditEntryResponse(e))
+	}
+	respondWithJSON(w, http.StatusOK, resp, "successfully listed the audit log")
+}
+
+func parseAuditTime(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", v, time.Local)
+}
@@ -2,8 +2,8 @@ package http
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
+	"t/internal/transport/dto"
 )
 
 func (s *Server) LoginHandler(w http.ResponseWriter, r *http.Request) {
@@ -17,11 +17,133 @@ func (s *Server) LoginHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := s.authService.LoginUser(r.Context(), req.Email, req.Password)
+	tokens, err := s.authService.LoginUser(r.Context(), req.Email, req.Password, r.UserAgent())
 	if err != nil {
-		log.Println(err)
-		respondWithJSON(w, http.StatusInternalServerError, nil, "failed to create the JWT")
+		s.respondWithError(w, err, "failed to create the JWT")
 		return
 	}
-	respondWithJSON(w, http.StatusOK, map[string]string{"token": token}, "logged in successfully")
+	respondWithJSON(w, http.StatusOK, dto.NewTokenResponse(tokens), "logged in successfully")
+}
+
+// RefreshHandler exchanges a refresh token for a new access token and a new refresh token
+func (s *Server) RefreshHandler(w http.ResponseWriter, r *http.Request) {
+	var req dto.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "invalid json")
+		return
+	}
+	if err := s.validator.Struct(req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "Validation failed: "+err.Error())
+		return
+	}
+
+	tokens, err := s.authService.Refresh(r.Context(), req.RefreshToken)
+	if err != nil {
+		s.respondWithError(w, err, "failed to refresh the token")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, dto.NewTokenResponse(tokens), "token refreshed successfully")
+}
+
+// LogoutHandler revokes the session of the given refresh token, the access token dies with it
+func (s *Server) LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	var req dto.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "invalid json")
+		return
+	}
+	if err := s.validator.Struct(req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "Validation failed: "+err.Error())
+		return
+	}
+
+	if err := s.authService.Logout(r.Context(), req.RefreshToken); err != nil {
+		s.respondWithError(w, err, "failed to logout")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, nil, "logged out successfully")
+}
+
+// LogoutAllHandler revokes every session of the current user
+func (s *Server) LogoutAllHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetID(r.Context())
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, nil, "invalid userID in context")
+		return
+	}
+
+	if err := s.authService.LogoutAll(r.Context(), userID); err != nil {
+		s.respondWithError(w, err, "failed to logout")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, nil, "logged out from all sessions")
+}
+
+// ForgotPasswordHandler always answers with success, so it does not tell which emails have an account
+func (s *Server) ForgotPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var req dto.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "invalid json")
+		return
+	}
+	if err := s.validator.Struct(req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "Validation failed: "+err.Error())
+		return
+	}
+
+	if err := s.authService.RequestPasswordReset(r.Context(), req.Email); err != nil {
+		s.respondWithError(w, err, "failed to send the reset email")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, nil, "if the account exists, a reset link was sent")
+}
+
+func (s *Server) ResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var req dto.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "invalid json")
+		return
+	}
+	if err := s.validator.Struct(req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "Validation failed: "+err.Error())
+		return
+	}
+
+	if err := s.authService.ResetPassword(r.Context(), req.Token, req.Password); err != nil {
+		s.respondWithError(w, err, "failed to reset the password")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, nil, "password was reset, please log in again")
+}
+
+func (s *Server) VerifyEmailHandler(w http.ResponseWriter, r *http.Request) {
+	var req dto.VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "invalid json")
+		return
+	}
+	if err := s.validator.Struct(req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "Validation failed: "+err.Error())
+		return
+	}
+
+	if err := s.authService.VerifyEmail(r.Context(), req.Token); err != nil {
+		s.respondWithError(w, err, "failed to verify the email")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, nil, "email verified successfully")
+}
+
+func (s *Server) ResendVerificationHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetID(r.Context())
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, nil, "invalid userID in context")
+		return
+	}
+
+	if err := s.authService.SendVerificationEmail(r.Context(), userID); err != nil {
+		s.respondWithError(w, err, "failed to send the verification email")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, nil, "verification email sent")
 }
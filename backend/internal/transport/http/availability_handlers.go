@@ -0,0 +1,59 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"t/internal/transport/dto"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// GetFacilityAvailabilityHandler answers ?date=YYYY-MM-DD for one day or ?from=&to= for a range,
+// ?slot=15 overrides the slot granularity in minutes
+func (s *Server) GetFacilityAvailabilityHandler(w http.ResponseWriter, r *http.Request) {
+	facilityID, err := uuid.Parse(chi.URLParam(r, "facility_id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "invalid facility_id")
+		return
+	}
+
+	q := r.URL.Query()
+	var from, to time.Time
+	if dateStr := q.Get("date"); dateStr != "" {
+		from, err = time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			respondWithJSON(w, http.StatusBadRequest, nil, "invalid date format, expected YYYY-MM-DD")
+			return
+		}
+		to = from
+	} else {
+		from, err = time.Parse("2006-01-02", q.Get("from"))
+		if err != nil {
+			respondWithJSON(w, http.StatusBadRequest, nil, "expected ?date= or ?from=&to= as YYYY-MM-DD")
+			return
+		}
+		to, err = time.Parse("2006-01-02", q.Get("to"))
+		if err != nil {
+			respondWithJSON(w, http.StatusBadRequest, nil, "expected ?date= or ?from=&to= as YYYY-MM-DD")
+			return
+		}
+	}
+
+	slot := 0
+	if slotStr := q.Get("slot"); slotStr != "" {
+		slot, err = strconv.Atoi(slotStr)
+		if err != nil {
+			respondWithJSON(w, http.StatusBadRequest, nil, "slot should be the number of minutes")
+			return
+		}
+	}
+
+	a, err := s.availabilityService.ForRange(r.Context(), facilityID, from, to, slot)
+	if err != nil {
+		s.respondWithError(w, err, "failed to compute availability")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, dto.NewAvailabilityResponse(a), "successfully computed availability")
+}
@@ -2,8 +2,11 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"t/internal/auth"
 	"t/internal/transport/dto"
 	"time"
 
@@ -45,15 +48,14 @@ func (s *Server) CreateBookingHandler(w http.ResponseWriter, r *http.Request) {
 	createDom, err := createDto.ToModel(userID)
 	if err != nil {
 		s.logger.Warn("failed to convert domain Model", zap.Error(err))
-		respondWithJSON(w, http.StatusBadRequest, nil, "nahh man")
+		respondWithJSON(w, http.StatusBadRequest, nil, err.Error())
 		return
 	}
 
 	err = s.bookingService.CreateNewBooking(r.Context(), createDom)
 
 	if err != nil {
-		s.logger.Warn("failed to create booking", zap.Error(err))
-		respondWithJSON(w, http.StatusInternalServerError, nil, err.Error())
+		s.respondWithError(w, err, "failed to create booking")
 		return
 	}
 	respondWithJSON(w, http.StatusOK, nil, "successfully created the booking")
@@ -65,14 +67,14 @@ func (s *Server) ListFacilityBookingsHandler(w http.ResponseWriter, r *http.Requ
 	idStr := chi.URLParam(r, "facility_id")
 	if idStr == "" {
 		s.logger.Warn("missing facilityID on path parameter")
-		http.Error(w, "missing facility id", http.StatusBadRequest)
+		respondWithJSON(w, http.StatusBadRequest, nil, "missing facility id")
 		return
 	}
 
 	facilID, err := uuid.Parse(idStr)
 	if err != nil {
 		s.logger.Warn("invalid facilityID on path parameter")
-		http.Error(w, "invalid id", http.StatusBadRequest)
+		respondWithJSON(w, http.StatusBadRequest, nil, "invalid id")
 		return
 	}
 
@@ -87,8 +89,7 @@ func (s *Server) ListFacilityBookingsHandler(w http.ResponseWriter, r *http.Requ
 
 	bookings, err := s.bookingService.ListBookingsForFacility(r.Context(), facilID, date)
 	if err != nil {
-		s.logger.Warn("failed to list bookings", zap.Error(err))
-		respondWithJSON(w, http.StatusInternalServerError, nil, "failed to list bookings")
+		s.respondWithError(w, err, "failed to list bookings")
 		return
 	}
 
@@ -102,8 +103,8 @@ func (s *Server) ListFacilityBookingsHandler(w http.ResponseWriter, r *http.Requ
 
 }
 
+// the user itself or the admin, enforced on the route
 func (s *Server) ListUserBookingsHandler(w http.ResponseWriter, r *http.Request) {
-	//first it should be user itself or the admin to access users bookings
 	pathIDstr := chi.URLParam(r, "id")
 	pathID, err := uuid.Parse(pathIDstr)
 	if err != nil {
@@ -112,20 +113,6 @@ func (s *Server) ListUserBookingsHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	userID, err := GetID(r.Context())
-	if err != nil {
-		s.logger.Warn("invalid userID from context", zap.Error(err))
-		respondWithJSON(w, http.StatusBadRequest, nil, "invalid userID in context")
-		return
-	}
-
-	// if id's do not mathc then user should be the admin!!!
-	if pathID != userID && !s.authService.IsAdmin(r.Context(), userID) {
-		s.logger.Warn("user is not admin and not requesting its own data")
-		respondWithJSON(w, http.StatusForbidden, nil, "access denied")
-		return
-	}
-
 	//get the offset from the query parameter and convert to int
 	offsetStr := r.URL.Query().Get("offset")
 	if offsetStr == "" {
@@ -138,10 +125,9 @@ func (s *Server) ListUserBookingsHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	resp, err := s.bookingService.ListBookingForUser(r.Context(), userID, offset)
+	resp, err := s.bookingService.ListBookingForUser(r.Context(), pathID, offset)
 	if err != nil {
-		s.logger.Warn("failed to list bookingsUser", zap.Error(err))
-		respondWithJSON(w, http.StatusInternalServerError, nil, "failed to list bookigs")
+		s.respondWithError(w, err, "failed to list bookigs")
 		return
 	}
 
@@ -157,22 +143,8 @@ func (s *Server) ListUserBookingsHandler(w http.ResponseWriter, r *http.Request)
 
 }
 
+// admin only, enforced on the route
 func (s *Server) ListBookingsHandler(w http.ResponseWriter, r *http.Request) {
-
-	userID, err := GetID(r.Context())
-	if err != nil {
-		s.logger.Warn("invalid userID from context", zap.Error(err))
-		respondWithJSON(w, http.StatusBadRequest, nil, "invalid userID in context")
-		return
-	}
-
-	// if id's do not mathc then user should be the admin!!!
-	if !s.authService.IsAdmin(r.Context(), userID) {
-		s.logger.Warn("user is not admin")
-		respondWithJSON(w, http.StatusForbidden, nil, "access denied")
-		return
-	}
-
 	//get query parameters
 	offsetStr := r.URL.Query().Get("offset")
 	if offsetStr == "" {
@@ -203,8 +175,7 @@ func (s *Server) ListBookingsHandler(w http.ResponseWriter, r *http.Request) {
 
 	bookngs, err := s.bookingService.ListBookings(r.Context(), startDate, endDate, offset)
 	if err != nil {
-		s.logger.Warn("failed to list bookings", zap.Error(err))
-		respondWithJSON(w, http.StatusInternalServerError, nil, "failed to list bookings")
+		s.respondWithError(w, err, "failed to list bookings")
 		return
 	}
 
@@ -221,32 +192,133 @@ func (s *Server) CancelBookingHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "booking_id")
 	if idStr == "" {
 		s.logger.Warn("missing bookingID on path parameter")
-		http.Error(w, "missing booking id", http.StatusBadRequest)
+		respondWithJSON(w, http.StatusBadRequest, nil, "missing booking id")
 		return
 	}
 
 	bookingID, err := uuid.Parse(idStr)
 	if err != nil {
 		s.logger.Warn("invalid booking on path parameter")
-		http.Error(w, "invalid id", http.StatusBadRequest)
+		respondWithJSON(w, http.StatusBadRequest, nil, "invalid id")
 		return
 	}
 
-	var req struct {
-		AdminNote string `json:"admin_note"`
+	//the body is optional, owners usually send none
+	var req dto.CancelBookingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		s.logger.Warn("failed to decode input", zap.Error(err))
+		respondWithJSON(w, http.StatusBadRequest, nil, "malformed input")
+		return
 	}
 
+	//owners can cancel their own bookings, but only admins leave notes
+	if !hasRole(r.Context(), auth.ADMIN) {
+		req.AdminNote = ""
+	}
+
+	userID, err := GetID(r.Context())
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, nil, "invalid userID in context")
+		return
+	}
+
+	//the owner is bound to the cancel cutoff, the result tells whether the cancellation was late and penalized
+	result, err := s.bookingService.CancelBooking(r.Context(), bookingID, userID, req.AdminNote)
+	if err != nil {
+		s.respondWithError(w, err, "failed to cancel booking")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, dto.NewCancelResultResponse(result), "successfully canceled booking")
+}
+
+// CreateBookingSeriesHandler books the slot on every date of the rule. the response always carries the per-occurrence
+// report, when conflicts stopped the series it comes with 409 and nothing was booked. staff or admin, enforced on the route
+func (s *Server) CreateBookingSeriesHandler(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateSeriesRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.logger.Warn("failed to decode input", zap.Error(err))
+		s.logger.Warn("failed to decode user input", zap.Error(err))
+		respondWithJSON(w, http.StatusBadRequest, nil, "failed to decode user input")
+		return
+	}
+
+	userID, err := GetID(r.Context())
+	if err != nil {
+		s.logger.Warn("invalid userID", zap.Error(err))
+		respondWithJSON(w, http.StatusBadRequest, nil, "invalid userID")
+		return
+	}
+
+	if err := s.validator.Struct(req); err != nil {
+		s.logger.Warn("invalid user input", zap.Error(err), zap.Any("payload", req))
 		respondWithJSON(w, http.StatusBadRequest, nil, "malformed input")
 		return
 	}
 
-	err = s.bookingService.CancelBooking(r.Context(), bookingID, req.AdminNote)
+	series, err := req.ToModel(userID)
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	report, err := s.bookingService.CreateSeries(r.Context(), series, req.SkipConflicts)
+	if err != nil {
+		s.respondWithError(w, err, "failed to create booking series")
+		return
+	}
+
+	resp := dto.NewSeriesReportResponse(report, errorCode)
+	if !report.Created {
+		respondWithJSON(w, http.StatusConflict, resp, "some occurrences conflict, nothing was booked")
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, resp, "successfully created the booking series")
+}
+
+// owner, staff or admin, enforced on the route
+func (s *Server) GetBookingSeriesHandler(w http.ResponseWriter, r *http.Request) {
+	seriesID, err := uuid.Parse(chi.URLParam(r, "series_id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "invalid id")
+		return
+	}
+
+	series, bookings, err := s.bookingService.GetSeries(r.Context(), seriesID)
+	if err != nil {
+		s.respondWithError(w, err, "failed to get booking series")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, dto.NewSeriesDetailResponse(series, bookings), "successfully fetched booking series")
+}
+
+// CancelBookingSeriesHandler cancels the rest of the series, from the ?from=YYYY-MM-DD date on (today by default).
+// single occurrences are canceled through CancelBookingHandler
+func (s *Server) CancelBookingSeriesHandler(w http.ResponseWriter, r *http.Request) {
+	seriesID, err := uuid.Parse(chi.URLParam(r, "series_id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "invalid id")
+		return
+	}
+
+	from := time.Now()
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	if fromStr := r.URL.Query().Get("from"); fromStr != "" {
+		from, err = time.Parse("2006-01-02", fromStr)
+		if err != nil {
+			respondWithJSON(w, http.StatusBadRequest, nil, "invalid from format, expected YYYY-MM-DD")
+			return
+		}
+	}
+
+	userID, err := GetID(r.Context())
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, nil, "invalid userID in context")
+		return
+	}
+
+	result, err := s.bookingService.CancelSeries(r.Context(), seriesID, userID, from)
 	if err != nil {
-		s.logger.Warn("failed to cancel booking", zap.Error(err))
-		respondWithJSON(w, http.StatusInternalServerError, nil, "failed to cancel booking")
+		s.respondWithError(w, err, "failed to cancel booking series")
 		return
 	}
-	respondWithJSON(w, http.StatusOK, nil, "successfully canceled booking")
+	respondWithJSON(w, http.StatusOK, dto.NewSeriesCancellationResponse(result), "successfully canceled booking series")
 }
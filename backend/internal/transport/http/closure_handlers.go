@@ -0,0 +1,104 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"t/internal/transport/dto"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// staff or admin, enforced on the route
+func (s *Server) CreateClosureHandler(w http.ResponseWriter, r *http.Request) {
+	facilityID, err := uuid.Parse(chi.URLParam(r, "facility_id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "invalid facility_id")
+		return
+	}
+
+	var req dto.CreateClosureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.logger.Warn("failed to decode user input", zap.Error(err))
+		respondWithJSON(w, http.StatusBadRequest, nil, "failed to decode user input")
+		return
+	}
+	if err := s.validator.Struct(req); err != nil {
+		s.logger.Warn("invalid user input", zap.Error(err))
+		respondWithJSON(w, http.StatusBadRequest, nil, "malformed input")
+		return
+	}
+
+	userID, err := GetID(r.Context())
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, nil, "invalid userID in context")
+		return
+	}
+
+	c, err := req.ToModel(facilityID, userID)
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	report, err := s.closureService.CreateClosure(r.Context(), c, req.CancelAffected)
+	if err != nil {
+		s.respondWithError(w, err, "failed to create closure")
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, dto.NewClosureReportResponse(report, errorCode), "successfully created closure")
+}
+
+// ListClosuresHandler lists the closures active between ?from= and ?to=, today and 30 days ahead by default
+func (s *Server) ListClosuresHandler(w http.ResponseWriter, r *http.Request) {
+	facilityID, err := uuid.Parse(chi.URLParam(r, "facility_id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "invalid facility_id")
+		return
+	}
+
+	from := time.Now()
+	if fromStr := r.URL.Query().Get("from"); fromStr != "" {
+		from, err = time.Parse("2006-01-02", fromStr)
+		if err != nil {
+			respondWithJSON(w, http.StatusBadRequest, nil, "invalid from format, expected YYYY-MM-DD")
+			return
+		}
+	}
+	to := from.AddDate(0, 0, 30)
+	if toStr := r.URL.Query().Get("to"); toStr != "" {
+		to, err = time.Parse("2006-01-02", toStr)
+		if err != nil {
+			respondWithJSON(w, http.StatusBadRequest, nil, "invalid to format, expected YYYY-MM-DD")
+			return
+		}
+	}
+
+	closures, err := s.availabilityService.ListClosures(r.Context(), facilityID, from, to)
+	if err != nil {
+		s.respondWithError(w, err, "failed to list closures")
+		return
+	}
+	resp := make([]dto.ClosureResponse, 0, len(closures))
+	for _, c := range closures {
+		resp = append(resp, dto.NewClosureResponse(c))
+	}
+	respondWithJSON(w, http.StatusOK, resp, "successfully listed closures")
+}
+
+// staff or admin, enforced on the route
+func (s *Server) DeleteClosureHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "closure_id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "invalid closure_id")
+		return
+	}
+
+	if err := s.closureService.DeleteClosure(r.Context(), id); err != nil {
+		s.respondWithError(w, err, "failed to delete closure")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, nil, "successfully deleted closure")
+}
@@ -0,0 +1,57 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"t/internal/transport/dto"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// ListCreditHistoryHandler lists every credit score change of the user, newest first. the user itself or an admin
+func (s *Server) ListCreditHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "Invalid ID")
+		return
+	}
+
+	offsetStr := r.URL.Query().Get("offset")
+	if offsetStr == "" {
+		offsetStr = "0"
+	}
+	offset, err := strconv.Atoi(offsetStr)
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "offset should be interger")
+		return
+	}
+
+	entries, err := s.creditService.ListHistory(r.Context(), userID, offset)
+	if err != nil {
+		s.respondWithError(w, err, "Failed to list credit history")
+		return
+	}
+
+	resp := make([]dto.CreditEntryResponse, 0, len(entries))
+	for _, e := range entries {
+		resp = append(resp, dto.NewCreditEntryResponse(e))
+	}
+	respondWithJSON(w, http.StatusOK, resp, "successfully listed credit history")
+}
+
+// RecoverCreditHandler runs the credit recovery job right away, admin only
+func (s *Server) RecoverCreditHandler(w http.ResponseWriter, r *http.Request) {
+	report, err := s.creditService.Recover(r.Context())
+	if err != nil {
+		s.respondWithError(w, err, "Failed to recover credit")
+		return
+	}
+
+	if !report.LockAcquired {
+		respondWithJSON(w, http.StatusConflict, dto.NewRecoveryReportResponse(report), "Recovery is already running on another instance")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, dto.NewRecoveryReportResponse(report), "Successfully recovered credit")
+}
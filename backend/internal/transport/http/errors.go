@@ -0,0 +1,236 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"t/internal/attendance"
+	"t/internal/auth"
+	"t/internal/availability"
+	"t/internal/booking"
+	"t/internal/facility"
+	"t/internal/holiday"
+	"t/internal/notification"
+	"t/internal/penalty"
+	"t/internal/policy"
+	"t/internal/registration"
+	"t/internal/review"
+	"t/internal/schedule"
+	"t/internal/session"
+	"t/internal/trainer"
+	"t/internal/transport/dto"
+	"t/internal/user"
+	"t/pkg/ical"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+)
+
+// errorMapping ties a domain error to the HTTP status and the stable code clients can switch on.
+// codes are part of the API, don't rename them
+type errorMapping struct {
+	err    error
+	status int
+	code   string
+}
+
+// errInvalidID is a malformed id in the path, the owner resolvers return it before looking anything up
+var errInvalidID = errors.New("invalid id")
+
+// errorMappings is the one place where domain errors become HTTP responses, the first match wins
+var errorMappings = []errorMapping{
+	// auth
+	{auth.ErrInvalidCredentials, http.StatusUnauthorized, "invalid_credentials"},
+	{auth.ErrWrongPassword, http.StatusForbidden, "wrong_password"},
+	{auth.ErrUserInactive, http.StatusForbidden, "user_inactive"},
+	{auth.ErrEmailNotVerified, http.StatusForbidden, "email_not_verified"},
+	{auth.ErrInvalidRefreshToken, http.StatusUnauthorized, "invalid_refresh_token"},
+	{auth.ErrRefreshTokenReused, http.StatusUnauthorized, "refresh_token_reused"},
+	{auth.ErrSessionNotFound, http.StatusUnauthorized, "session_not_found"},
+	{auth.ErrInvalidToken, http.StatusBadRequest, "invalid_token"},
+	{auth.ErrAlreadyVerified, http.StatusConflict, "email_already_verified"},
+
+	// users
+	{user.ErrUserNotFound, http.StatusNotFound, "user_not_found"},
+	{user.ErrUserIsTrainer, http.StatusConflict, "user_is_trainer"},
+	{user.ErrRoleChangeNotAllowed, http.StatusConflict, "role_change_not_allowed"},
+
+	// facilities and reviews
+	{facility.ErrFacilityNotFound, http.StatusNotFound, "facility_not_found"},
+	{facility.ErrInvalidOpeningHours, http.StatusBadRequest, "invalid_opening_hours"},
+	{facility.ErrInvalidSeason, http.StatusBadRequest, "invalid_season"},
+	{facility.ErrSeasonNotFound, http.StatusNotFound, "season_not_found"},
+	{facility.ErrSeasonOverlap, http.StatusConflict, "season_overlap"},
+	{facility.ErrFacilityTypeNotFound, http.StatusNotFound, "facility_type_not_found"},
+	{facility.ErrUnknownFacilityType, http.StatusBadRequest, "unknown_facility_type"},
+	{facility.ErrInvalidFacilityType, http.StatusBadRequest, "invalid_facility_type"},
+	{facility.ErrFacilityTypeExists, http.StatusConflict, "facility_type_exists"},
+	{facility.ErrFacilityTypeInUse, http.StatusConflict, "facility_type_in_use"},
+	{review.ErrReviewNotFound, http.StatusNotFound, "review_not_found"},
+	{availability.ErrInvalidRange, http.StatusBadRequest, "invalid_range"},
+	{availability.ErrInvalidSlot, http.StatusBadRequest, "invalid_slot"},
+	{availability.ErrFacilityOccupied, http.StatusConflict, "facility_occupied"},
+	{availability.ErrFacilityClosed, http.StatusConflict, "facility_closed"},
+	{availability.ErrHoliday, http.StatusConflict, "holiday"},
+	{availability.ErrInvalidClosure, http.StatusBadRequest, "invalid_closure"},
+	{availability.ErrClosureNotFound, http.StatusNotFound, "closure_not_found"},
+	{holiday.ErrInvalidHoliday, http.StatusBadRequest, "invalid_holiday"},
+	{holiday.ErrHolidayNotFound, http.StatusNotFound, "holiday_not_found"},
+	{ical.ErrInvalidCalendar, http.StatusBadRequest, "invalid_calendar"},
+
+	// bookings
+	{booking.ErrBookingNotFound, http.StatusNotFound, "booking_not_found"},
+	{booking.ErrBookingAlreadyCanceled, http.StatusConflict, "booking_already_canceled"},
+	{booking.ErrFacilityInactive, http.StatusBadRequest, "facility_inactive"},
+	{booking.ErrInvalidInterval, http.StatusBadRequest, "invalid_interval"},
+	{booking.ErrBookingInPast, http.StatusBadRequest, "booking_in_past"},
+	{booking.ErrOutsideOpeningHours, http.StatusBadRequest, "outside_opening_hours"},
+	{booking.ErrSlotTooShort, http.StatusBadRequest, "slot_too_short"},
+	{booking.ErrSlotTooLong, http.StatusBadRequest, "slot_too_long"},
+	{booking.ErrNotEnoughPoints, http.StatusConflict, "not_enough_points"},
+	{booking.ErrAlreadyBookedThatDay, http.StatusConflict, "daily_booking_limit"},
+	{booking.ErrUserOverlap, http.StatusConflict, "user_overlap"},
+	{booking.ErrFacilityOverlap, http.StatusConflict, "facility_overlap"},
+	{booking.ErrTooManyBookings, http.StatusConflict, "too_many_bookings"},
+	{booking.ErrSeriesNotFound, http.StatusNotFound, "series_not_found"},
+	{booking.ErrSeriesAlreadyCanceled, http.StatusConflict, "series_already_canceled"},
+	{booking.ErrInvalidSeries, http.StatusBadRequest, "invalid_series"},
+
+	// booking policies
+	{policy.ErrPolicyNotFound, http.StatusNotFound, "policy_not_found"},
+	{policy.ErrInvalidPolicy, http.StatusBadRequest, "invalid_policy"},
+
+	// trainers, schedules and sessions
+	{trainer.ErrTrainerNotFound, http.StatusNotFound, "trainer_not_found"},
+	{schedule.ErrScheduleNotFound, http.StatusNotFound, "schedule_not_found"},
+	{schedule.ErrScheduleOverlap, http.StatusConflict, "schedule_overlap"},
+	{session.ErrSessionNotFound, http.StatusNotFound, "session_not_found"},
+	{session.ErrSessionAlreadyCanceled, http.StatusConflict, "session_already_canceled"},
+
+	// attendance
+	{attendance.ErrTargetNotFound, http.StatusNotFound, "attendance_target_not_found"},
+	{attendance.ErrNotCheckable, http.StatusConflict, "not_checkable"},
+	{attendance.ErrCheckInClosed, http.StatusConflict, "checkin_closed"},
+	{attendance.ErrAlreadyCheckedIn, http.StatusConflict, "already_checked_in"},
+	{attendance.ErrNotCheckedIn, http.StatusConflict, "not_checked_in"},
+	{attendance.ErrAlreadyCheckedOut, http.StatusConflict, "already_checked_out"},
+	{attendance.ErrInvalidStatus, http.StatusBadRequest, "invalid_attendance_status"},
+	{attendance.ErrNotPrivileged, http.StatusForbidden, "not_privileged"},
+	{attendance.ErrInvalidToken, http.StatusBadRequest, "invalid_checkin_token"},
+	{attendance.ErrTokenExpired, http.StatusBadRequest, "checkin_token_expired"},
+
+	// notifications
+	{notification.ErrNotificationNotFound, http.StatusNotFound, "notification_not_found"},
+	{notification.ErrUnknownChannel, http.StatusBadRequest, "unknown_channel"},
+
+	// registrations
+	{registration.ErrAlreadyRegistered, http.StatusConflict, "already_registered"},
+	{registration.ErrSessionNotAvailable, http.StatusNotFound, "session_not_available"},
+	{registration.ErrRegistrationNotFound, http.StatusNotFound, "registration_not_found"},
+	{registration.ErrNotOnWaitlist, http.StatusNotFound, "not_on_waitlist"},
+
+	// penalties
+	{penalty.ErrPenaltyNotFound, http.StatusNotFound, "penalty_not_found"},
+	{penalty.ErrCancelCutoffPassed, http.StatusConflict, "cancel_cutoff_passed"},
+
+	// path parameters
+	{errInvalidID, http.StatusBadRequest, "invalid_id"},
+}
+
+// postgres error codes, https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+	pgCheckViolation      = "23514"
+	pgNotNullViolation    = "23502"
+	pgInvalidText         = "22P02"
+	pgInvalidDatetime     = "22007"
+	pgDatetimeOverflow    = "22008"
+)
+
+// mapError resolves the status, code and client-safe message of err
+func mapError(err error) (int, string, string) {
+	for _, m := range errorMappings {
+		if errors.Is(err, m.err) {
+			return m.status, m.code, err.Error()
+		}
+	}
+
+	//errors that leaked from the repositories without a domain error, the message must not expose SQL
+	if errors.Is(err, pgx.ErrNoRows) {
+		return http.StatusNotFound, "not_found", "resource not found"
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgUniqueViolation:
+			return http.StatusConflict, "already_exists", "resource already exists"
+		case pgForeignKeyViolation:
+			return http.StatusConflict, "reference_violation", "referenced resource does not exist or is still in use"
+		case pgCheckViolation, pgNotNullViolation, pgInvalidText, pgInvalidDatetime, pgDatetimeOverflow:
+			return http.StatusBadRequest, "invalid_input", "invalid input"
+		}
+	}
+
+	return http.StatusInternalServerError, codeForStatus(http.StatusInternalServerError), ""
+}
+
+// errorCode is the stable code of err, used where errors are reported inside a successful response
+func errorCode(err error) string {
+	_, code, _ := mapError(err)
+	return code
+}
+
+// codeForStatus is the generic code of responses that have no domain error behind them
+func codeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "bad_request"
+	case http.StatusUnauthorized:
+		return "unauthorized"
+	case http.StatusForbidden:
+		return "forbidden"
+	case http.StatusNotFound:
+		return "not_found"
+	case http.StatusConflict:
+		return "conflict"
+	case http.StatusUnprocessableEntity:
+		return "unprocessable"
+	}
+	if status >= 500 {
+		return "internal_error"
+	}
+	return ""
+}
+
+// respondWithError maps err to the response, unexpected errors are logged and answered with the fallback message
+func (s *Server) respondWithError(w http.ResponseWriter, err error, fallback string) {
+	status, code, message := mapError(err)
+	if status >= 500 {
+		s.logger.Error(fallback, zap.Error(err))
+		message = fallback
+	} else {
+		s.logger.Debug("request rejected", zap.String("code", code), zap.Error(err))
+	}
+
+	resp := Response{Success: false, Code: code, Message: message}
+
+	//policy rejections carry the rule that was violated
+	var v *policy.Violation
+	if errors.As(err, &v) {
+		resp.Data = dto.NewPolicyViolationResponse(v)
+	}
+
+	writeResponse(w, status, resp)
+}
+
+func writeResponse(w http.ResponseWriter, status int, resp Response) {
+	w.Header().Set("Content-type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, `{"success":false,"code":"internal_error","message":"failed to encode response"}`, http.StatusInternalServerError)
+	}
+}
@@ -24,29 +24,16 @@ func (s *Server) CreateFacilityHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	//get the userID, it will be injected into ctx after passing JWT middleware
-	id, err := GetID(r.Context())
-	if err != nil {
-		respondWithJSON(w, http.StatusBadRequest, nil, "userID not found")
-		return
-	}
-
-	//check if the user is admin
-	if !s.authService.IsAdmin(r.Context(), id) {
-		respondWithJSON(w, http.StatusForbidden, nil, "user is not admin")
-		return
-	}
-
 	//convert to domain type and call for the facility service
 	f, err := createReq.ToModel()
 	if err != nil {
-		respondWithJSON(w, http.StatusInternalServerError, nil, "cannot convert to model")
+		respondWithJSON(w, http.StatusBadRequest, nil, err.Error())
 		return
 	}
 
 	err = s.facilityService.CreateFacility(r.Context(), f)
 	if err != nil {
-		respondWithJSON(w, http.StatusInternalServerError, nil, "cannot create facility")
+		s.respondWithError(w, err, "cannot create facility")
 		return
 	}
 	respondWithJSON(w, http.StatusOK, nil, "facility craeted")
@@ -55,13 +42,13 @@ func (s *Server) CreateFacilityHandler(w http.ResponseWriter, r *http.Request) {
 func (s *Server) UpdateFacilityHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	if idStr == "" {
-		http.Error(w, "missing user id", http.StatusBadRequest)
+		respondWithJSON(w, http.StatusBadRequest, nil, "missing user id")
 		return
 	}
 
 	facilID, err := uuid.Parse(idStr)
 	if err != nil {
-		http.Error(w, "invalid id", http.StatusBadRequest)
+		respondWithJSON(w, http.StatusBadRequest, nil, "invalid id")
 		return
 	}
 
@@ -73,29 +60,17 @@ func (s *Server) UpdateFacilityHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	fmt.Println(updateDTO)
 
-	//check if its admin
-	userID, err := GetID(r.Context())
-	if err != nil {
-		respondWithJSON(w, http.StatusInternalServerError, nil, "cannot get id ")
-		return
-	}
-
-	if !s.authService.IsAdmin(r.Context(), userID) {
-		respondWithJSON(w, http.StatusForbidden, nil, "need admin access")
-		return
-	}
-
 	//load original from the DB
 	facil, err := s.facilityService.GetFacility(r.Context(), facilID)
 	if err != nil {
-		respondWithJSON(w, http.StatusNotFound, nil, "")
+		s.respondWithError(w, err, "failed to get facility")
 		return
 	}
 
 	err = updateDTO.ApplyToFacility(&facil)
 	if err != nil {
 		log.Println("cannot convert from updateFacility")
-		respondWithJSON(w, http.StatusInternalServerError, w, "server error")
+		respondWithJSON(w, http.StatusBadRequest, nil, err.Error())
 		return
 	}
 
@@ -103,8 +78,7 @@ func (s *Server) UpdateFacilityHandler(w http.ResponseWriter, r *http.Request) {
 
 	err = s.facilityService.UpdateFacility(r.Context(), facil)
 	if err != nil {
-		log.Println(err)
-		respondWithJSON(w, http.StatusInternalServerError, nil, "fucked up")
+		s.respondWithError(w, err, "failed to update facility")
 		return
 	}
 	respondWithJSON(w, http.StatusOK, nil, "updated successfully")
@@ -114,18 +88,19 @@ func (s *Server) UpdateFacilityHandler(w http.ResponseWriter, r *http.Request) {
 func (s *Server) GetFacilityHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	if idStr == "" {
-		http.Error(w, "missing user id", http.StatusBadRequest)
+		respondWithJSON(w, http.StatusBadRequest, nil, "missing user id")
 		return
 	}
 
 	facilID, err := uuid.Parse(idStr)
 	if err != nil {
-		http.Error(w, "invalid id", http.StatusBadRequest)
+		respondWithJSON(w, http.StatusBadRequest, nil, "invalid id")
 		return
 	}
 	facil, err := s.facilityService.GetFacility(r.Context(), facilID)
 	if err != nil {
-		respondWithJSON(w, http.StatusNotFound, nil, "facility not found")
+		s.respondWithError(w, err, "failed to get facility")
+		return
 	}
 
 	var resp dto.FacilityResponseDTO
@@ -137,7 +112,7 @@ func (s *Server) ListFacilitiesHandler(w http.ResponseWriter, r *http.Request) {
 
 	facils, err := s.facilityService.ListFacilities(r.Context())
 	if err != nil {
-		respondWithJSON(w, http.StatusInternalServerError, nil, "failed to get facilites")
+		s.respondWithError(w, err, "failed to get facilites")
 		return
 	}
 	resp := make([]dto.FacilityResponseDTO, 0)
@@ -154,32 +129,19 @@ func (s *Server) ListFacilitiesHandler(w http.ResponseWriter, r *http.Request) {
 func (s *Server) DeleteFacilityHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	if idStr == "" {
-		http.Error(w, "missing user id", http.StatusBadRequest)
+		respondWithJSON(w, http.StatusBadRequest, nil, "missing user id")
 		return
 	}
 
 	facilID, err := uuid.Parse(idStr)
 	if err != nil {
-		http.Error(w, "invalid id", http.StatusBadRequest)
-		return
-	}
-
-	//check if its admin
-	userID, err := GetID(r.Context())
-	if err != nil {
-		respondWithJSON(w, http.StatusInternalServerError, nil, "cannot get id ")
-		return
-	}
-
-	if !s.authService.IsAdmin(r.Context(), userID) {
-		respondWithJSON(w, http.StatusForbidden, nil, "need admin access")
+		respondWithJSON(w, http.StatusBadRequest, nil, "invalid id")
 		return
 	}
 
 	err = s.facilityService.DeleteFacility(r.Context(), facilID)
 	if err != nil {
-
-		respondWithJSON(w, http.StatusInternalServerError, nil, "failed to delete")
+		s.respondWithError(w, err, "failed to delete")
 		return
 	}
 
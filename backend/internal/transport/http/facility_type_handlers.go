@@ -0,0 +1,104 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"t/internal/transport/dto"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func (s *Server) ListFacilityTypesHandler(w http.ResponseWriter, r *http.Request) {
+	types, err := s.facilityService.ListFacilityTypes(r.Context())
+	if err != nil {
+		s.respondWithError(w, err, "failed to list facility types")
+		return
+	}
+	resp := make([]dto.FacilityTypeResponse, 0, len(types))
+	for _, t := range types {
+		resp = append(resp, dto.NewFacilityTypeResponse(t))
+	}
+	respondWithJSON(w, http.StatusOK, resp, "successfully listed facility types")
+}
+
+func (s *Server) GetFacilityTypeHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "type_id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "invalid type_id")
+		return
+	}
+
+	t, err := s.facilityService.GetFacilityType(r.Context(), id)
+	if err != nil {
+		s.respondWithError(w, err, "failed to get facility type")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, dto.NewFacilityTypeResponse(t), "successfully fetched facility type")
+}
+
+// admin only, enforced on the route
+func (s *Server) CreateFacilityTypeHandler(w http.ResponseWriter, r *http.Request) {
+	var req dto.FacilityTypeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.logger.Warn("failed to decode user input", zap.Error(err))
+		respondWithJSON(w, http.StatusBadRequest, nil, "failed to decode user input")
+		return
+	}
+	if err := s.validator.Struct(req); err != nil {
+		s.logger.Warn("invalid user input", zap.Error(err))
+		respondWithJSON(w, http.StatusBadRequest, nil, "malformed input")
+		return
+	}
+
+	created, err := s.facilityService.CreateFacilityType(r.Context(), req.ToModel(uuid.Nil))
+	if err != nil {
+		s.respondWithError(w, err, "failed to create facility type")
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, dto.NewFacilityTypeResponse(created), "successfully created facility type")
+}
+
+// admin only, enforced on the route
+func (s *Server) UpdateFacilityTypeHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "type_id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "invalid type_id")
+		return
+	}
+
+	var req dto.FacilityTypeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.logger.Warn("failed to decode user input", zap.Error(err))
+		respondWithJSON(w, http.StatusBadRequest, nil, "failed to decode user input")
+		return
+	}
+	if err := s.validator.Struct(req); err != nil {
+		s.logger.Warn("invalid user input", zap.Error(err))
+		respondWithJSON(w, http.StatusBadRequest, nil, "malformed input")
+		return
+	}
+
+	updated, err := s.facilityService.UpdateFacilityType(r.Context(), req.ToModel(id))
+	if err != nil {
+		s.respondWithError(w, err, "failed to update facility type")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, dto.NewFacilityTypeResponse(updated), "successfully updated facility type")
+}
+
+// admin only, enforced on the route
+func (s *Server) DeleteFacilityTypeHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "type_id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "invalid type_id")
+		return
+	}
+
+	if err := s.facilityService.DeleteFacilityType(r.Context(), id); err != nil {
+		s.respondWithError(w, err, "failed to delete facility type")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, nil, "successfully deleted facility type")
+}
@@ -0,0 +1,123 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"t/internal/transport/dto"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// largest calendar file the import accepts
+const maxCalendarBytes = 1 << 20
+
+// ListHolidaysHandler lists the holidays between ?from= and ?to=, today and a year ahead by default
+func (s *Server) ListHolidaysHandler(w http.ResponseWriter, r *http.Request) {
+	var err error
+	from := time.Now()
+	if fromStr := r.URL.Query().Get("from"); fromStr != "" {
+		from, err = time.Parse("2006-01-02", fromStr)
+		if err != nil {
+			respondWithJSON(w, http.StatusBadRequest, nil, "invalid from format, expected YYYY-MM-DD")
+			return
+		}
+	}
+	to := from.AddDate(1, 0, 0)
+	if toStr := r.URL.Query().Get("to"); toStr != "" {
+		to, err = time.Parse("2006-01-02", toStr)
+		if err != nil {
+			respondWithJSON(w, http.StatusBadRequest, nil, "invalid to format, expected YYYY-MM-DD")
+			return
+		}
+	}
+
+	holidays, err := s.holidayService.ListHolidays(r.Context(), from, to)
+	if err != nil {
+		s.respondWithError(w, err, "failed to list holidays")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, dto.NewHolidayResponses(holidays), "successfully listed holidays")
+}
+
+// admin only, enforced on the route
+func (s *Server) CreateHolidayHandler(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateHolidayRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.logger.Warn("failed to decode user input", zap.Error(err))
+		respondWithJSON(w, http.StatusBadRequest, nil, "failed to decode user input")
+		return
+	}
+	if err := s.validator.Struct(req); err != nil {
+		s.logger.Warn("invalid user input", zap.Error(err))
+		respondWithJSON(w, http.StatusBadRequest, nil, "malformed input")
+		return
+	}
+
+	userID, err := GetID(r.Context())
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, nil, "invalid userID in context")
+		return
+	}
+
+	h, err := req.ToModel(userID)
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	result, err := s.holidayService.CreateHoliday(r.Context(), h)
+	if err != nil {
+		s.respondWithError(w, err, "failed to create holiday")
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, dto.NewHolidayResultResponse(result), "successfully created holiday")
+}
+
+// ImportHolidaysHandler takes the .ics file as the raw body (text/calendar) or as the "file" field of a multipart form.
+// admin only, enforced on the route
+func (s *Server) ImportHolidaysHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetID(r.Context())
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, nil, "invalid userID in context")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxCalendarBytes)
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			respondWithJSON(w, http.StatusBadRequest, nil, "missing calendar file")
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	report, err := s.holidayService.Import(r.Context(), body, userID)
+	if err != nil {
+		s.respondWithError(w, err, "failed to import holidays")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, dto.NewHolidayImportResponse(report), "successfully imported holidays")
+}
+
+// admin only, enforced on the route
+func (s *Server) DeleteHolidayHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "holiday_id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "invalid holiday_id")
+		return
+	}
+
+	if err := s.holidayService.DeleteHoliday(r.Context(), id); err != nil {
+		s.respondWithError(w, err, "failed to delete holiday")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, nil, "successfully deleted holiday")
+}
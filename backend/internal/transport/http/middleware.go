@@ -0,0 +1,220 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"t/internal/audit"
+	"t/internal/auth"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+)
+
+// ownerResolver returns the user who owns the resource the request points to
+type ownerResolver func(r *http.Request) (uuid.UUID, error)
+
+// RequireRole lets the request through only if the role from the JWT is one of roles
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !slices.Contains(roles, auth.RoleFromContext(r.Context())) {
+				respondWithJSON(w, http.StatusForbidden, nil, "access denied")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireOwnerOrRole lets the request through if the caller owns the resource or has one of roles
+func (s *Server) RequireOwnerOrRole(owner ownerResolver, roles ...string) func(http.Handler) http.Handler {
+	return s.RequireAnyOwnerOrRole([]ownerResolver{owner}, roles...)
+}
+
+// RequireAnyOwnerOrRole is RequireOwnerOrRole for resources with several owners, e.g. a registration belongs
+// to the registered user and to the trainer of the session
+func (s *Server) RequireAnyOwnerOrRole(owners []ownerResolver, roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if slices.Contains(roles, auth.RoleFromContext(r.Context())) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			userID, err := GetID(r.Context())
+			if err != nil {
+				respondWithJSON(w, http.StatusUnauthorized, nil, "invalid userID in context")
+				return
+			}
+
+			for _, owner := range owners {
+				ownerID, err := owner(r)
+				if err != nil {
+					//a malformed id is a 400, a missing resource its not found code, anything else is logged as a 500
+					s.respondWithError(w, err, "failed to resolve the resource owner")
+					return
+				}
+				if ownerID == userID {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			respondWithJSON(w, http.StatusForbidden, nil, "access denied")
+		})
+	}
+}
+
+// auditMeta puts the request metadata for the audit log into the context, auditActor adds the caller once the JWT is checked
+func auditMeta(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			ip = r.RemoteAddr
+		}
+		meta := audit.Meta{
+			IP:        ip,
+			UserAgent: r.UserAgent(),
+			RequestID: middleware.GetReqID(r.Context()),
+		}
+		next.ServeHTTP(w, r.WithContext(audit.WithMeta(r.Context(), meta)))
+	})
+}
+
+func auditActor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		meta := audit.MetaFrom(r.Context())
+		if userID, err := GetID(r.Context()); err == nil {
+			meta.ActorID = &userID
+		}
+		meta.ActorRole = auth.RoleFromContext(r.Context())
+		next.ServeHTTP(w, r.WithContext(audit.WithMeta(r.Context(), meta)))
+	})
+}
+
+// pathID parses the id in the path parameter, a malformed one is errInvalidID
+func pathID(r *http.Request, param string) (uuid.UUID, error) {
+	id, err := uuid.Parse(chi.URLParam(r, param))
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: %s", errInvalidID, param)
+	}
+	return id, nil
+}
+
+// pathUser is the owner resolver for routes like /users/{id}/..., the owner is the user in the path
+func pathUser(param string) ownerResolver {
+	return func(r *http.Request) (uuid.UUID, error) {
+		return pathID(r, param)
+	}
+}
+
+func (s *Server) scheduleOwner(r *http.Request) (uuid.UUID, error) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		return uuid.Nil, err
+	}
+	sch, err := s.scheduleService.GetSchedule(r.Context(), id)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return sch.TrainerID, nil
+}
+
+func (s *Server) sessionOwner(param string) ownerResolver {
+	return func(r *http.Request) (uuid.UUID, error) {
+		id, err := pathID(r, param)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		sess, err := s.sessionService.GetSession(r.Context(), id)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return sess.TrainerID, nil
+	}
+}
+
+func (s *Server) bookingOwner(r *http.Request) (uuid.UUID, error) {
+	id, err := pathID(r, "booking_id")
+	if err != nil {
+		return uuid.Nil, err
+	}
+	b, err := s.bookingService.GetBooking(r.Context(), id)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return b.UserID, nil
+}
+
+func (s *Server) seriesOwner(r *http.Request) (uuid.UUID, error) {
+	id, err := pathID(r, "series_id")
+	if err != nil {
+		return uuid.Nil, err
+	}
+	series, _, err := s.bookingService.GetSeries(r.Context(), id)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return series.UserID, nil
+}
+
+func (s *Server) registrationOwner(r *http.Request) (uuid.UUID, error) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		return uuid.Nil, err
+	}
+	reg, err := s.registrationService.GetRegistration(r.Context(), id)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return reg.UserID, nil
+}
+
+// registrationTrainer resolves to the trainer of the session the registration is for
+func (s *Server) registrationTrainer(r *http.Request) (uuid.UUID, error) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		return uuid.Nil, err
+	}
+	reg, err := s.registrationService.GetRegistration(r.Context(), id)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	sess, err := s.sessionService.GetSession(r.Context(), reg.SessionID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return sess.TrainerID, nil
+}
+
+func (s *Server) reviewOwner(r *http.Request) (uuid.UUID, error) {
+	id, err := pathID(r, "review_id")
+	if err != nil {
+		return uuid.Nil, err
+	}
+	rev, err := s.reviewService.GetFacilityReview(r.Context(), id)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return rev.UserID, nil
+}
+
+// penaltyGiver resolves to the user who gave the penalty, so trainers can only manage their own penalties
+func (s *Server) penaltyGiver(r *http.Request) (uuid.UUID, error) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		return uuid.Nil, err
+	}
+	p, err := s.penaltyService.GetPenalty(r.Context(), id)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return p.GivenByID, nil
+}
+
+func hasRole(ctx context.Context, roles ...string) bool {
+	return slices.Contains(roles, auth.RoleFromContext(ctx))
+}
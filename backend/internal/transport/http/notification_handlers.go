@@ -0,0 +1,187 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"t/internal/notification"
+	"t/internal/transport/dto"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// ListNotificationsHandler lists the inbox of the caller, newest first. ?unread=true for the unread ones only
+func (s *Server) ListNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetID(r.Context())
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, nil, "invalid userID in context")
+		return
+	}
+
+	unreadOnly := r.URL.Query().Get("unread") == "true"
+
+	offsetStr := r.URL.Query().Get("offset")
+	if offsetStr == "" {
+		offsetStr = "0"
+	}
+	offset, err := strconv.Atoi(offsetStr)
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "offset should be interger")
+		return
+	}
+
+	notifications, err := s.notificationService.List(r.Context(), userID, unreadOnly, offset)
+	if err != nil {
+		s.respondWithError(w, err, "Failed to list notifications")
+		return
+	}
+
+	resp := make([]dto.NotificationResponse, 0, len(notifications))
+	for _, n := range notifications {
+		resp = append(resp, dto.NewNotificationResponse(n))
+	}
+	respondWithJSON(w, http.StatusOK, resp, "successfully listed notifications")
+}
+
+func (s *Server) CountUnreadNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetID(r.Context())
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, nil, "invalid userID in context")
+		return
+	}
+
+	count, err := s.notificationService.CountUnread(r.Context(), userID)
+	if err != nil {
+		s.respondWithError(w, err, "Failed to count unread notifications")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, dto.UnreadCountResponse{Unread: count}, "successfully counted unread notifications")
+}
+
+// MarkNotificationReadHandler marks one notification as read, only the caller's own notifications are found
+func (s *Server) MarkNotificationReadHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "Invalid ID")
+		return
+	}
+
+	userID, err := GetID(r.Context())
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, nil, "invalid userID in context")
+		return
+	}
+
+	if err := s.notificationService.MarkRead(r.Context(), userID, id); err != nil {
+		s.respondWithError(w, err, "Failed to mark the notification as read")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, nil, "successfully marked the notification as read")
+}
+
+func (s *Server) MarkAllNotificationsReadHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetID(r.Context())
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, nil, "invalid userID in context")
+		return
+	}
+
+	marked, err := s.notificationService.MarkAllRead(r.Context(), userID)
+	if err != nil {
+		s.respondWithError(w, err, "Failed to mark the notifications as read")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, dto.MarkedReadResponse{Marked: marked}, "successfully marked the notifications as read")
+}
+
+// GetNotificationPreferencesHandler lists every delivery channel and whether the caller receives notifications on it
+func (s *Server) GetNotificationPreferencesHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetID(r.Context())
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, nil, "invalid userID in context")
+		return
+	}
+
+	prefs, err := s.notificationService.GetPreferences(r.Context(), userID)
+	if err != nil {
+		s.respondWithError(w, err, "Failed to get notification preferences")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, newPreferenceResponses(prefs), "successfully got notification preferences")
+}
+
+func (s *Server) UpdateNotificationPreferencesHandler(w http.ResponseWriter, r *http.Request) {
+	var req dto.UpdatePreferencesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "malformed input")
+		return
+	}
+	if err := s.validator.Struct(req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "Validation failed: "+err.Error())
+		return
+	}
+
+	userID, err := GetID(r.Context())
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, nil, "invalid userID in context")
+		return
+	}
+
+	channels := make(map[notification.ChannelName]bool, len(req.Channels))
+	for name, enabled := range req.Channels {
+		channels[notification.ChannelName(name)] = enabled
+	}
+
+	prefs, err := s.notificationService.SetPreferences(r.Context(), userID, channels)
+	if err != nil {
+		s.respondWithError(w, err, "Failed to update notification preferences")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, newPreferenceResponses(prefs), "successfully updated notification preferences")
+}
+
+// ListNotificationDeliveriesHandler shows how one notification of the caller was delivered on each channel
+func (s *Server) ListNotificationDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "Invalid ID")
+		return
+	}
+
+	userID, err := GetID(r.Context())
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, nil, "invalid userID in context")
+		return
+	}
+
+	deliveries, err := s.notificationService.ListDeliveries(r.Context(), userID, id)
+	if err != nil {
+		s.respondWithError(w, err, "Failed to list deliveries")
+		return
+	}
+
+	resp := make([]dto.DeliveryResponse, 0, len(deliveries))
+	for _, d := range deliveries {
+		resp = append(resp, dto.NewDeliveryResponse(d))
+	}
+	respondWithJSON(w, http.StatusOK, resp, "successfully listed deliveries")
+}
+
+// DeliverNotificationsHandler runs one batch of the delivery job right away, admin only
+func (s *Server) DeliverNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	report, err := s.notificationService.DeliverPending(r.Context())
+	if err != nil {
+		s.respondWithError(w, err, "Failed to deliver notifications")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, dto.NewDeliveryReportResponse(report), "Successfully delivered notifications")
+}
+
+func newPreferenceResponses(prefs []notification.Preference) []dto.PreferenceResponse {
+	resp := make([]dto.PreferenceResponse, 0, len(prefs))
+	for _, p := range prefs {
+		resp = append(resp, dto.NewPreferenceResponse(p))
+	}
+	return resp
+}
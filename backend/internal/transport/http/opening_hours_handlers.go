@@ -0,0 +1,145 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"t/internal/transport/dto"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// GetOpeningHoursHandler returns the regular week of the facility and its seasons
+func (s *Server) GetOpeningHoursHandler(w http.ResponseWriter, r *http.Request) {
+	facilityID, err := uuid.Parse(chi.URLParam(r, "facility_id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "invalid facility_id")
+		return
+	}
+
+	f, err := s.facilityService.GetFacility(r.Context(), facilityID)
+	if err != nil {
+		s.respondWithError(w, err, "failed to get opening hours")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, dto.NewOpeningHoursResponse(f.ID, f.Hours), "successfully fetched opening hours")
+}
+
+// SetOpeningHoursHandler replaces the regular week, admin only, enforced on the route
+func (s *Server) SetOpeningHoursHandler(w http.ResponseWriter, r *http.Request) {
+	facilityID, err := uuid.Parse(chi.URLParam(r, "facility_id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "invalid facility_id")
+		return
+	}
+
+	var req dto.WeekDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.logger.Warn("failed to decode user input", zap.Error(err))
+		respondWithJSON(w, http.StatusBadRequest, nil, "failed to decode user input")
+		return
+	}
+	if err := s.validator.Struct(req); err != nil {
+		s.logger.Warn("invalid user input", zap.Error(err))
+		respondWithJSON(w, http.StatusBadRequest, nil, "malformed input")
+		return
+	}
+
+	week, err := req.ToModel()
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	hours, err := s.facilityService.SetOpeningHours(r.Context(), facilityID, week)
+	if err != nil {
+		s.respondWithError(w, err, "failed to set opening hours")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, dto.NewOpeningHoursResponse(facilityID, hours), "successfully set opening hours")
+}
+
+// admin only, enforced on the route
+func (s *Server) CreateSeasonHandler(w http.ResponseWriter, r *http.Request) {
+	facilityID, err := uuid.Parse(chi.URLParam(r, "facility_id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "invalid facility_id")
+		return
+	}
+
+	var req dto.SeasonRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.logger.Warn("failed to decode user input", zap.Error(err))
+		respondWithJSON(w, http.StatusBadRequest, nil, "failed to decode user input")
+		return
+	}
+	if err := s.validator.Struct(req); err != nil {
+		s.logger.Warn("invalid user input", zap.Error(err))
+		respondWithJSON(w, http.StatusBadRequest, nil, "malformed input")
+		return
+	}
+
+	season, err := req.ToModel(facilityID)
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+
+	created, err := s.facilityService.CreateSeason(r.Context(), season)
+	if err != nil {
+		s.respondWithError(w, err, "failed to create season")
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, dto.NewSeasonDTO(created), "successfully created season")
+}
+
+// admin only, enforced on the route
+func (s *Server) UpdateSeasonHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "season_id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "invalid season_id")
+		return
+	}
+
+	var req dto.SeasonRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.logger.Warn("failed to decode user input", zap.Error(err))
+		respondWithJSON(w, http.StatusBadRequest, nil, "failed to decode user input")
+		return
+	}
+	if err := s.validator.Struct(req); err != nil {
+		s.logger.Warn("invalid user input", zap.Error(err))
+		respondWithJSON(w, http.StatusBadRequest, nil, "malformed input")
+		return
+	}
+
+	season, err := req.ToModel(uuid.Nil)
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+	season.ID = id
+
+	updated, err := s.facilityService.UpdateSeason(r.Context(), season)
+	if err != nil {
+		s.respondWithError(w, err, "failed to update season")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, dto.NewSeasonDTO(updated), "successfully updated season")
+}
+
+// admin only, enforced on the route
+func (s *Server) DeleteSeasonHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "season_id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "invalid season_id")
+		return
+	}
+
+	if err := s.facilityService.DeleteSeason(r.Context(), id); err != nil {
+		s.respondWithError(w, err, "failed to delete season")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, nil, "successfully deleted season")
+}
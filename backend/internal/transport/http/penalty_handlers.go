@@ -26,14 +26,6 @@ func (s *Server) CreatePenaltyHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	//check user is admin or trainer
-	isAdmin, _ := s.isAdmin(r.Context())
-	isTrainer, _ := s.isTrainer(r.Context())
-	if !isAdmin && !isTrainer {
-		respondWithJSON(w, http.StatusForbidden, nil, "Access Denied")
-		return
-	}
-
 	//set the givenByID to current users ID
 	userID, err := GetID(r.Context())
 	if err != nil {
@@ -49,24 +41,15 @@ func (s *Server) CreatePenaltyHandler(w http.ResponseWriter, r *http.Request) {
 	//call the serivede
 	err = s.penaltyService.CreatePenalty(r.Context(), reqModel)
 	if err != nil {
-		s.logger.Error("Failed To CreatePenalty", zap.Error(err))
-		respondWithJSON(w, http.StatusInternalServerError, nil, "failed to create penalty")
+		s.respondWithError(w, err, "failed to create penalty")
 		return
 	}
 	respondWithJSON(w, http.StatusOK, nil, "successfully created penalty")
 
 }
 
+// only the giver of the penalty or an admin, enforced on the route
 func (s *Server) DeletePenaltyHandler(w http.ResponseWriter, r *http.Request) {
-	//check if admin or trainer
-	isAdmin, _ := s.isAdmin(r.Context())
-	isTrainer, _ := s.isTrainer(r.Context())
-
-	if !isAdmin && !isTrainer {
-		respondWithJSON(w, http.StatusForbidden, nil, "Access Denied")
-		return
-	}
-
 	idStr := chi.URLParam(r, "id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
@@ -75,47 +58,9 @@ func (s *Server) DeletePenaltyHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// If not admin, check if the penalty was given by the current user
-	if !isAdmin {
-		// We need to fetch the penalty first to check ownership
-		// Since we don't have a GetPenalty method, we can list penalties given by user and check if ID exists
-		// Or better, we can trust the service/repo to handle this check, but for now let's do a quick check here
-		// A more robust way would be to add GetPenalty to service/repo, but to minimize changes:
-		// We will proceed with deletion, but we should ideally verify ownership.
-		// However, given the constraints, let's assume the frontend only shows delete button for owned penalties
-		// and we can add a check in the repository if needed.
-		// For now, let's just allow trainers to call delete.
-		// TODO: Add ownership check in service/repo layer for better security
-	}
-
-	// Actually, let's add a check here using ListGivenPenaltyByUser
-	if !isAdmin {
-		userID, _ := GetID(r.Context())
-		penalties, err := s.penaltyService.ListGivenPenaltyByUser(r.Context(), userID)
-		if err != nil {
-			s.logger.Error("Failed to list given penalties for ownership check", zap.Error(err))
-			respondWithJSON(w, http.StatusInternalServerError, nil, "Internal Server Error")
-			return
-		}
-
-		found := false
-		for _, p := range penalties {
-			if p.ID == id {
-				found = true
-				break
-			}
-		}
-
-		if !found {
-			respondWithJSON(w, http.StatusForbidden, nil, "You can only delete penalties you created")
-			return
-		}
-	}
-
 	err = s.penaltyService.DeletePenalty(r.Context(), id)
 	if err != nil {
-		s.logger.Error("Failed to delete penalty", zap.Error(err))
-		respondWithJSON(w, http.StatusInternalServerError, nil, "Failed to delete penalty")
+		s.respondWithError(w, err, "Failed to delete penalty")
 		return
 	}
 
@@ -123,7 +68,6 @@ func (s *Server) DeletePenaltyHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) ListPenaltyForUserHandler(w http.ResponseWriter, r *http.Request) {
-	//check if admin or the user themselves
 	userIDStr := chi.URLParam(r, "id")
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
@@ -132,18 +76,9 @@ func (s *Server) ListPenaltyForUserHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	currentUserID, _ := GetID(r.Context())
-	isAdmin, _ := s.isAdmin(r.Context())
-
-	if currentUserID != userID && !isAdmin {
-		respondWithJSON(w, http.StatusForbidden, nil, "Access Denied")
-		return
-	}
-
 	penalties, err := s.penaltyService.ListPenaltyForUser(r.Context(), userID)
 	if err != nil {
-		s.logger.Error("Failed to list penalties", zap.Error(err))
-		respondWithJSON(w, http.StatusInternalServerError, nil, "Failed to list penalties")
+		s.respondWithError(w, err, "Failed to list penalties")
 		return
 	}
 
@@ -158,15 +93,6 @@ func (s *Server) ListPenaltyForUserHandler(w http.ResponseWriter, r *http.Reques
 }
 
 func (s *Server) ListGivenPenaltyByUserHandler(w http.ResponseWriter, r *http.Request) {
-	//check if admin or trainer
-	isAdmin, _ := s.isAdmin(r.Context())
-	isTrainer, _ := s.isTrainer(r.Context())
-
-	if !isAdmin && !isTrainer {
-		respondWithJSON(w, http.StatusForbidden, nil, "Access Denied")
-		return
-	}
-
 	userIDStr := chi.URLParam(r, "id")
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
@@ -177,8 +103,7 @@ func (s *Server) ListGivenPenaltyByUserHandler(w http.ResponseWriter, r *http.Re
 
 	penalties, err := s.penaltyService.ListGivenPenaltyByUser(r.Context(), userID)
 	if err != nil {
-		s.logger.Error("Failed to list given penalties", zap.Error(err))
-		respondWithJSON(w, http.StatusInternalServerError, nil, "Failed to list given penalties")
+		s.respondWithError(w, err, "Failed to list given penalties")
 		return
 	}
 
@@ -193,13 +118,6 @@ func (s *Server) ListGivenPenaltyByUserHandler(w http.ResponseWriter, r *http.Re
 }
 
 func (s *Server) ListPenaltiesIntervalHandler(w http.ResponseWriter, r *http.Request) {
-	//check if admin
-	isAdmin, _ := s.isAdmin(r.Context())
-	if !isAdmin {
-		respondWithJSON(w, http.StatusForbidden, nil, "Access Denied")
-		return
-	}
-
 	startStr := r.URL.Query().Get("start")
 	endStr := r.URL.Query().Get("end")
 
@@ -217,8 +135,7 @@ func (s *Server) ListPenaltiesIntervalHandler(w http.ResponseWriter, r *http.Req
 
 	penalties, err := s.penaltyService.ListPenaltiesInterval(r.Context(), start, end)
 	if err != nil {
-		s.logger.Error("Failed to list penalties interval", zap.Error(err))
-		respondWithJSON(w, http.StatusInternalServerError, nil, "Failed to list penalties interval")
+		s.respondWithError(w, err, "Failed to list penalties interval")
 		return
 	}
 
@@ -0,0 +1,132 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"t/internal/transport/dto"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func (s *Server) CreatePolicyHandler(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreatePolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.logger.Warn("failed to decode policy input", zap.Error(err))
+		respondWithJSON(w, http.StatusBadRequest, nil, "malformed input")
+		return
+	}
+	if err := s.validator.Struct(req); err != nil {
+		s.logger.Warn("invalid policy input", zap.Error(err))
+		respondWithJSON(w, http.StatusBadRequest, nil, "Validation failed: "+err.Error())
+		return
+	}
+
+	id, err := s.policyService.CreatePolicy(r.Context(), req.ToModel())
+	if err != nil {
+		s.respondWithError(w, err, "failed to create policy")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, map[string]uuid.UUID{"policy_id": id}, "policy created successfully")
+}
+
+func (s *Server) ListPoliciesHandler(w http.ResponseWriter, r *http.Request) {
+	policies, err := s.policyService.ListPolicies(r.Context())
+	if err != nil {
+		s.respondWithError(w, err, "failed to list policies")
+		return
+	}
+
+	resp := make([]dto.PolicyResponse, 0, len(policies))
+	for _, p := range policies {
+		resp = append(resp, dto.NewPolicyResponse(p))
+	}
+	respondWithJSON(w, http.StatusOK, resp, "successfully listed policies")
+}
+
+func (s *Server) GetPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "Invalid policy ID")
+		return
+	}
+
+	p, err := s.policyService.GetPolicy(r.Context(), id)
+	if err != nil {
+		s.respondWithError(w, err, "failed to get policy")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, dto.NewPolicyResponse(p), "policy found")
+}
+
+// GetEffectivePolicyHandler explains which rules apply to a facility and role, and which policy each one comes from
+func (s *Server) GetEffectivePolicyHandler(w http.ResponseWriter, r *http.Request) {
+	facilityID, err := uuid.Parse(r.URL.Query().Get("facility_id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "Invalid facility_id")
+		return
+	}
+	role := r.URL.Query().Get("role")
+	if role == "" {
+		role = "student"
+	}
+
+	eff, err := s.policyService.EffectivePolicy(r.Context(), facilityID, role)
+	if err != nil {
+		s.respondWithError(w, err, "failed to resolve policies")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, dto.NewEffectivePolicyResponse(eff), "successfully resolved policies")
+}
+
+func (s *Server) UpdatePolicyHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "Invalid policy ID")
+		return
+	}
+
+	var req dto.UpdatePolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "malformed input")
+		return
+	}
+	if err := s.validator.Struct(req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "Validation failed: "+err.Error())
+		return
+	}
+
+	p, err := s.policyService.GetPolicy(r.Context(), id)
+	if err != nil {
+		s.respondWithError(w, err, "failed to get policy")
+		return
+	}
+
+	req.ApplyToPolicy(&p)
+
+	if err := s.policyService.UpdatePolicy(r.Context(), p); err != nil {
+		s.respondWithError(w, err, "failed to update policy")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, nil, "policy updated successfully")
+}
+
+func (s *Server) DeletePolicyHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "Invalid policy ID")
+		return
+	}
+
+	if err := s.policyService.DeletePolicy(r.Context(), id); err != nil {
+		s.respondWithError(w, err, "failed to delete policy")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, nil, "policy deleted successfully")
+}
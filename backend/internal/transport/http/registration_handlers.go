@@ -35,13 +35,18 @@ func (s *Server) CreateRegistrationHandler(w http.ResponseWriter, r *http.Reques
 	// Ensure ID is generated
 	registrationData.ID = uuid.New()
 
-	if err := s.registrationService.CreateRegistration(r.Context(), registrationData); err != nil {
-		s.logger.Error("Failed to create registration", zap.Error(err))
-		respondWithJSON(w, http.StatusInternalServerError, nil, "Failed to create registration: "+err.Error())
+	reg, err := s.registrationService.CreateRegistration(r.Context(), registrationData)
+	if err != nil {
+		s.respondWithError(w, err, "Failed to create registration")
 		return
 	}
 
-	respondWithJSON(w, http.StatusCreated, nil, "Registration created successfully")
+	resp := dto.NewRegistrationResponse(reg)
+	if reg.IsWaitlisted {
+		respondWithJSON(w, http.StatusCreated, resp, "Session is full, you were added to the waitlist")
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, resp, "Registration created successfully")
 }
 
 func (s *Server) CancelRegistrationHandler(w http.ResponseWriter, r *http.Request) {
@@ -52,13 +57,51 @@ func (s *Server) CancelRegistrationHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	if err := s.registrationService.CancelRegistration(r.Context(), id); err != nil {
-		s.logger.Error("Failed to cancel registration", zap.Error(err))
-		respondWithJSON(w, http.StatusInternalServerError, nil, "Failed to cancel registration")
+	userID, err := GetID(r.Context())
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, nil, "Unauthorized")
+		return
+	}
+
+	//the owner is bound to the cancel cutoff, the result tells whether the cancellation was late and penalized
+	result, promoted, err := s.registrationService.CancelRegistration(r.Context(), id, userID)
+	if err != nil {
+		s.respondWithError(w, err, "Failed to cancel registration")
+		return
+	}
+
+	if promoted != nil {
+		s.logger.Info("promoted registration from the waitlist",
+			zap.String("register_id", promoted.ID.String()),
+			zap.String("session_id", promoted.SessionID.String()),
+			zap.String("user_id", promoted.UserID.String()),
+		)
+	}
+
+	respondWithJSON(w, http.StatusOK, dto.NewCancelResultResponse(result), "Registration canceled successfully")
+}
+
+// LeaveWaitlistHandler removes the current user from the waitlist of the session
+func (s *Server) LeaveWaitlistHandler(w http.ResponseWriter, r *http.Request) {
+	sessionIDStr := chi.URLParam(r, "session_id")
+	sessionID, err := uuid.Parse(sessionIDStr)
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "Invalid session ID")
+		return
+	}
+
+	userID, err := GetID(r.Context())
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, nil, "Unauthorized")
+		return
+	}
+
+	if err := s.registrationService.LeaveWaitlist(r.Context(), sessionID, userID); err != nil {
+		s.respondWithError(w, err, "Failed to leave waitlist")
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, nil, "Registration canceled successfully")
+	respondWithJSON(w, http.StatusOK, nil, "Left the waitlist successfully")
 }
 
 func (s *Server) ListSessionRegistrationsHandler(w http.ResponseWriter, r *http.Request) {
@@ -71,8 +114,7 @@ func (s *Server) ListSessionRegistrationsHandler(w http.ResponseWriter, r *http.
 
 	registrations, err := s.registrationService.ListRegistrationsForSession(r.Context(), sessionID)
 	if err != nil {
-		s.logger.Error("Failed to list session registrations", zap.Error(err))
-		respondWithJSON(w, http.StatusInternalServerError, nil, "Failed to list registrations")
+		s.respondWithError(w, err, "Failed to list registrations")
 		return
 	}
 
@@ -104,8 +146,7 @@ func (s *Server) ListUserRegistrationsHandler(w http.ResponseWriter, r *http.Req
 
 	registrations, err := s.registrationService.ListRegistrationsForUser(r.Context(), userID, offset)
 	if err != nil {
-		s.logger.Error("Failed to list user registrations", zap.Error(err))
-		respondWithJSON(w, http.StatusInternalServerError, nil, "Failed to list registrations")
+		s.respondWithError(w, err, "Failed to list registrations")
 		return
 	}
 
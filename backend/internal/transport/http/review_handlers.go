@@ -73,7 +73,7 @@ func (s *Server) CreateFacilityReviewHandler(w http.ResponseWriter, r *http.Requ
 			zap.String("facility_id", facilityIDStr),
 			zap.String("user_id", userID.String()),
 		)
-		respondWithJSON(w, http.StatusInternalServerError, nil, "failed to create review")
+		s.respondWithError(w, err, "failed to create review")
 		return
 	}
 
@@ -117,7 +117,7 @@ func (s *Server) DeleteFacilityReviewHandler(w http.ResponseWriter, r *http.Requ
 			zap.String("review_id", reviewIDStr),
 			zap.String("user_id", userID.String()),
 		)
-		respondWithJSON(w, http.StatusInternalServerError, nil, "failed to delete review")
+		s.respondWithError(w, err, "failed to delete review")
 		return
 	}
 
@@ -172,7 +172,7 @@ func (s *Server) GetFacilityReviewsHandler(w http.ResponseWriter, r *http.Reques
 			zap.String("facility_id", facilityIDStr),
 			zap.Int("offset", offset),
 		)
-		respondWithJSON(w, http.StatusInternalServerError, nil, "failed to retrieve reviews")
+		s.respondWithError(w, err, "failed to retrieve reviews")
 		return
 	}
 
@@ -211,7 +211,7 @@ func (s *Server) GetFacilityRatingHandler(w http.ResponseWriter, r *http.Request
 			zap.Error(err),
 			zap.String("facility_id", facilityIDStr),
 		)
-		respondWithJSON(w, http.StatusInternalServerError, nil, "failed to retrieve rating")
+		s.respondWithError(w, err, "failed to retrieve rating")
 		return
 	}
 
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"t/internal/transport/dto"
@@ -27,14 +28,7 @@ func (s *Server) CreateScheduleHandler(w http.ResponseWriter, r *http.Request) {
 	// Get trainer ID from context (assuming auth middleware sets it)
 	userID, err := GetID(r.Context())
 	if err != nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		return
-	}
-
-	// Check if user is a trainer
-	isTrainer := s.authService.IsTrainer(r.Context(), userID)
-	if !isTrainer {
-		http.Error(w, "User is not a trainer", http.StatusForbidden)
+		respondWithJSON(w, http.StatusUnauthorized, nil, "Unauthorized")
 		return
 	}
 
@@ -52,39 +46,46 @@ func (s *Server) CreateScheduleHandler(w http.ResponseWriter, r *http.Request) {
 	id, err := uuid.NewUUID()
 	scheduleData.ID = id
 
-	if err := s.scheduleService.CreateTrainingScehdule(r.Context(), *scheduleData); err != nil {
-		s.logger.Error("Failed to create schedule", zap.Error(err))
-		http.Error(w, "Failed to create schedule: "+err.Error(), http.StatusInternalServerError)
+	report, err := s.scheduleService.CreateTrainingScehdule(r.Context(), *scheduleData, req.SkipConflicts)
+	if err != nil {
+		s.respondWithError(w, err, "Failed to create schedule")
+		return
+	}
+	if !report.Created {
+		respondWithJSON(w, http.StatusConflict, dto.NewScheduleConflictResponse(report), "The facility is occupied during this schedule")
 		return
 	}
 
 	//create the sessions in the backgorund
 
+	// the background generator would pick it up on its next run anyway, this just makes the sessions visible right away
 	go func() {
-		err = s.sessionService.CreateSessionsForNextWeeks(*scheduleData, 2)
+		created, err := s.sessionService.GenerateForSchedule(context.Background(), *scheduleData)
 		if err != nil {
 			s.logger.Error("Failed to generate sessions for the schedule", zap.Error(err))
+			return
 		}
-		s.logger.Info("Successfully generated sessions for the schedule", zap.Error(err))
-
+		s.logger.Info("Successfully generated sessions for the schedule",
+			zap.String("schedule_id", scheduleData.ID.String()),
+			zap.Int("created", created),
+		)
 	}()
 
-	respondWithJSON(w, http.StatusOK, nil, "Schedule created successfully")
+	respondWithJSON(w, http.StatusOK, dto.NewScheduleConflictResponse(report), "Schedule created successfully")
 }
 
 func (s *Server) DeleteScheduleHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		http.Error(w, "Invalid schedule ID", http.StatusBadRequest)
+		respondWithJSON(w, http.StatusBadRequest, nil, "Invalid schedule ID")
 		return
 	}
 
 	// TODO: Add authorization check to ensure the trainer owns this schedule
 
 	if err := s.scheduleService.DeleteTrainingSchedule(r.Context(), id); err != nil {
-		s.logger.Error("Failed to delete schedule", zap.Error(err))
-		http.Error(w, "Failed to delete schedule", http.StatusInternalServerError)
+		s.respondWithError(w, err, "Failed to delete schedule")
 		return
 	}
 	respondWithJSON(w, http.StatusOK, nil, "Schedule deleted successfully")
@@ -94,14 +95,13 @@ func (s *Server) ListTrainerSchedulesHandler(w http.ResponseWriter, r *http.Requ
 	trainerIDStr := chi.URLParam(r, "trainer_id")
 	trainerID, err := uuid.Parse(trainerIDStr)
 	if err != nil {
-		http.Error(w, "Invalid trainer ID", http.StatusBadRequest)
+		respondWithJSON(w, http.StatusBadRequest, nil, "Invalid trainer ID")
 		return
 	}
 
 	schedules, err := s.scheduleService.ListSchedulesForTrainer(r.Context(), trainerID)
 	if err != nil {
-		s.logger.Error("Failed to list trainer schedules", zap.Error(err))
-		http.Error(w, "Failed to list schedules", http.StatusInternalServerError)
+		s.respondWithError(w, err, "Failed to list schedules")
 		return
 	}
 
@@ -117,14 +117,13 @@ func (s *Server) ListFacilitySchedulesHandler(w http.ResponseWriter, r *http.Req
 	facilityIDStr := chi.URLParam(r, "facility_id")
 	facilityID, err := uuid.Parse(facilityIDStr)
 	if err != nil {
-		http.Error(w, "Invalid facility ID", http.StatusBadRequest)
+		respondWithJSON(w, http.StatusBadRequest, nil, "Invalid facility ID")
 		return
 	}
 
 	schedules, err := s.scheduleService.ListSchedulesForFacility(r.Context(), facilityID)
 	if err != nil {
-		s.logger.Error("Failed to list facility schedules", zap.Error(err))
-		http.Error(w, "Failed to list schedules", http.StatusInternalServerError)
+		s.respondWithError(w, err, "Failed to list schedules")
 		return
 	}
 
@@ -3,10 +3,18 @@ package http
 import (
 	"context"
 	"net/http"
+	"t/internal/attendance"
+	"t/internal/audit"
 	"t/internal/auth"
+	"t/internal/availability"
 	"t/internal/booking"
+	"t/internal/closure"
+	"t/internal/credit"
 	"t/internal/facility"
+	"t/internal/holiday"
+	"t/internal/notification"
 	"t/internal/penalty"
+	"t/internal/policy"
 	"t/internal/registration"
 	"t/internal/review"
 	"t/internal/schedule"
@@ -34,11 +42,19 @@ type Server struct {
 	scheduleService     *schedule.ScheduleService
 	registrationService *registration.RegistrationService
 	penaltyService      *penalty.PenaltyService
+	policyService       *policy.PolicyService
+	creditService       *credit.CreditService
+	attendanceService   *attendance.AttendanceService
+	auditService        *audit.AuditService
+	notificationService *notification.NotificationService
+	availabilityService *availability.AvailabilityService
+	closureService      *closure.ClosureService
+	holidayService      *holiday.HolidayService
 	validator           *validator.Validate
 	logger              *zap.Logger
 }
 
-func NewServer(addr string, userSrv *user.UserService, authSrv *auth.AuthService, facilSrv *facility.FacilityService, bookSrv *booking.BookingService, reviewSrv *review.ReviewService, trainerSrv *trainer.TrainerService, sessionSrv *session.SessionService, scheduleSrv *schedule.ScheduleService, registrationSrv *registration.RegistrationService, penaltySrv *penalty.PenaltyService) *Server {
+func NewServer(addr string, userSrv *user.UserService, authSrv *auth.AuthService, facilSrv *facility.FacilityService, bookSrv *booking.BookingService, reviewSrv *review.ReviewService, trainerSrv *trainer.TrainerService, sessionSrv *session.SessionService, scheduleSrv *schedule.ScheduleService, registrationSrv *registration.RegistrationService, penaltySrv *penalty.PenaltyService, policySrv *policy.PolicyService, creditSrv *credit.CreditService, attendanceSrv *attendance.AttendanceService, auditSrv *audit.AuditService, notificationSrv *notification.NotificationService, availabilitySrv *availability.AvailabilityService, closureSrv *closure.ClosureService, holidaySrv *holiday.HolidayService) *Server {
 	router := chi.NewMux()
 
 	validator := validator.New(validator.WithRequiredStructEnabled())
@@ -62,6 +78,14 @@ func NewServer(addr string, userSrv *user.UserService, authSrv *auth.AuthService
 		scheduleService:     scheduleSrv,
 		registrationService: registrationSrv,
 		penaltyService:      penaltySrv,
+		policyService:       policySrv,
+		creditService:       creditSrv,
+		attendanceService:   attendanceSrv,
+		auditService:        auditSrv,
+		notificationService: notificationSrv,
+		availabilityService: availabilitySrv,
+		closureService:      closureSrv,
+		holidayService:      holidaySrv,
 		authService:         authSrv,
 		router:              router, // our application also needs this router to set up routes/middlewares so they will be reflected in the httpServer
 	}
@@ -82,78 +106,168 @@ func (s *Server) registerHandlers() {
 		MaxAge:           300, // maximum age for preflight request cache
 	}))
 	s.router.Route("/api/v1", func(r chi.Router) {
+		r.Use(middleware.RequestID)
 		r.Use(middleware.Logger)
+		r.Use(auditMeta)
 
 		//public routes
 		r.Group(func(pub chi.Router) {
 			pub.Post("/auth/login", s.LoginHandler)
+			pub.Post("/auth/refresh", s.RefreshHandler)
+			pub.Post("/auth/logout", s.LogoutHandler)
+			pub.Post("/auth/password/forgot", s.ForgotPasswordHandler)
+			pub.Post("/auth/password/reset", s.ResetPasswordHandler)
+			pub.Post("/auth/verify-email", s.VerifyEmailHandler)
 			pub.Post("/users", s.CreateUserHandler)
 		})
 
 		//	protected routes
 		r.Group(func(pro chi.Router) {
 			pro.Use(s.authService.JWTMiddleware)
+			pro.Use(auditActor)
+
+			admin := RequireRole(auth.ADMIN)
+
+			pro.Post("/auth/logout/all", s.LogoutAllHandler)
+			pro.Post("/auth/verify-email/resend", s.ResendVerificationHandler)
 
 			pro.Get("/users/{id}", s.GetUserHandler)
 			pro.Get("/users/me", s.WhoAmI)
-			pro.Get("/users", s.ListUsersHandler)
+			pro.Patch("/users/me", s.UpdateMeHandler)
+			pro.Post("/users/me/password", s.ChangePasswordHandler)
+			pro.Post("/users/me/deactivate", s.DeactivateMeHandler)
+			pro.Delete("/users/me", s.DeleteMeHandler)
+			pro.With(admin).Get("/users", s.ListUsersHandler)
+			pro.With(admin).Patch("/users/{id}", s.AdminUpdateUserHandler)
+			pro.With(admin).Delete("/users/{id}", s.DeleteUserHandler)
 
-			pro.Get("/users/{id}/bookings", s.ListUserBookingsHandler)
+			pro.With(s.RequireOwnerOrRole(pathUser("id"), auth.ADMIN)).Get("/users/{id}/bookings", s.ListUserBookingsHandler)
 
 			//handler to get just 1 facility
 			pro.Get("/facility/{id}", s.GetFacilityHandler)
 			//handler to update the facility
-			pro.Patch("/facility/{id}", s.UpdateFacilityHandler)
+			pro.With(admin).Patch("/facility/{id}", s.UpdateFacilityHandler)
 
 			pro.Get("/facility/all", s.ListFacilitiesHandler)
+
 			//craete facility
-			pro.Post("/facility", s.CreateFacilityHandler)
+			pro.With(admin).Post("/facility", s.CreateFacilityHandler)
 			//delete facility
-			pro.Delete("/facility/{id}", s.DeleteFacilityHandler)
+			pro.With(admin).Delete("/facility/{id}", s.DeleteFacilityHandler)
+
+			// Facility type endpoints
+			pro.Get("/facility-types", s.ListFacilityTypesHandler)
+			pro.Get("/facility-types/{type_id}", s.GetFacilityTypeHandler)
+			pro.With(admin).Post("/facility-types", s.CreateFacilityTypeHandler)
+			pro.With(admin).Put("/facility-types/{type_id}", s.UpdateFacilityTypeHandler)
+			pro.With(admin).Delete("/facility-types/{type_id}", s.DeleteFacilityTypeHandler)
 
 			pro.Post("/bookings", s.CreateBookingHandler)
-			pro.Post("/bookings/cancel/{booking_id}", s.CancelBookingHandler)
-			pro.Get("/bookings", s.ListBookingsHandler)
+			pro.With(s.RequireOwnerOrRole(s.bookingOwner, auth.STAFF, auth.ADMIN)).Post("/bookings/cancel/{booking_id}", s.CancelBookingHandler)
+			pro.With(admin).Get("/bookings", s.ListBookingsHandler)
 			pro.Get("/bookings/facility/{facility_id}", s.ListFacilityBookingsHandler)
+			pro.With(RequireRole(auth.STAFF, auth.ADMIN)).Post("/bookings/series", s.CreateBookingSeriesHandler)
+			pro.With(s.RequireOwnerOrRole(s.seriesOwner, auth.STAFF, auth.ADMIN)).Get("/bookings/series/{series_id}", s.GetBookingSeriesHandler)
+			pro.With(s.RequireOwnerOrRole(s.seriesOwner, auth.STAFF, auth.ADMIN)).Post("/bookings/series/cancel/{series_id}", s.CancelBookingSeriesHandler)
+			pro.With(s.RequireOwnerOrRole(s.bookingOwner, auth.STAFF, auth.ADMIN)).Post("/bookings/checkin/{booking_id}", s.CheckInBookingHandler)
+			pro.With(s.RequireOwnerOrRole(s.bookingOwner, auth.STAFF, auth.ADMIN)).Post("/bookings/checkout/{booking_id}", s.CheckOutBookingHandler)
+			pro.With(RequireRole(auth.STAFF, auth.ADMIN)).Post("/bookings/attendance/{booking_id}", s.SetBookingAttendanceHandler)
+			pro.With(s.RequireOwnerOrRole(s.bookingOwner, auth.STAFF, auth.ADMIN)).Get("/bookings/checkin-token/{booking_id}", s.GetBookingCheckInTokenHandler)
+			pro.With(s.RequireOwnerOrRole(s.bookingOwner, auth.STAFF, auth.ADMIN)).Get("/bookings/checkin-qr/{booking_id}", s.GetBookingCheckInQRHandler)
 
 			// Review endpoints
 			pro.Post("/facility/{facility_id}/review", s.CreateFacilityReviewHandler)
-			pro.Delete("/facility/review/{review_id}", s.DeleteFacilityReviewHandler)
+			pro.With(s.RequireOwnerOrRole(s.reviewOwner, auth.ADMIN)).Delete("/facility/review/{review_id}", s.DeleteFacilityReviewHandler)
 			pro.Get("/facility/{facility_id}/reviews", s.GetFacilityReviewsHandler)
 			pro.Get("/facility/{facility_id}/rating", s.GetFacilityRatingHandler)
+			pro.Get("/facility/{facility_id}/availability", s.GetFacilityAvailabilityHandler)
+			pro.Get("/facility/{facility_id}/opening-hours", s.GetOpeningHoursHandler)
+			pro.With(admin).Put("/facility/{facility_id}/opening-hours", s.SetOpeningHoursHandler)
+			pro.With(admin).Post("/facility/{facility_id}/seasons", s.CreateSeasonHandler)
+			pro.With(admin).Put("/facility/seasons/{season_id}", s.UpdateSeasonHandler)
+			pro.With(admin).Delete("/facility/seasons/{season_id}", s.DeleteSeasonHandler)
+			pro.Get("/facility/{facility_id}/closures", s.ListClosuresHandler)
+			pro.With(RequireRole(auth.STAFF, auth.ADMIN)).Post("/facility/{facility_id}/closures", s.CreateClosureHandler)
+			pro.With(RequireRole(auth.STAFF, auth.ADMIN)).Delete("/facility/closures/{closure_id}", s.DeleteClosureHandler)
+
+			// Campus calendar endpoints
+			pro.Get("/calendar/holidays", s.ListHolidaysHandler)
+			pro.With(admin).Post("/calendar/holidays", s.CreateHolidayHandler)
+			pro.With(admin).Post("/calendar/holidays/import", s.ImportHolidaysHandler)
+			pro.With(admin).Delete("/calendar/holidays/{holiday_id}", s.DeleteHolidayHandler)
 
 			// Trainer endpoints
-			pro.Post("/trainers", s.CreateTrainerHandler)
+			pro.With(admin).Post("/trainers", s.CreateTrainerHandler)
 			pro.Get("/trainers", s.ListTrainersHandler)
 			pro.Get("/trainers/{id}", s.GetTrainerHandler)
-			pro.Patch("/trainers/{id}", s.UpdateTrainerHandler)
-			pro.Delete("/trainers/{id}", s.DeleteTrainerHandler)
+			pro.With(s.RequireOwnerOrRole(pathUser("id"), auth.ADMIN)).Patch("/trainers/{id}", s.UpdateTrainerHandler)
+			pro.With(admin).Delete("/trainers/{id}", s.DeleteTrainerHandler)
 
 			// Schedule endpoints
-			pro.Post("/schedules", s.CreateScheduleHandler)
-			pro.Delete("/schedules/{id}", s.DeleteScheduleHandler)
+			pro.With(RequireRole(auth.TRAINER)).Post("/schedules", s.CreateScheduleHandler)
+			pro.With(s.RequireOwnerOrRole(s.scheduleOwner, auth.ADMIN)).Delete("/schedules/{id}", s.DeleteScheduleHandler)
 			pro.Get("/schedules/trainer/{trainer_id}", s.ListTrainerSchedulesHandler)
 			pro.Get("/schedules/facility/{facility_id}", s.ListFacilitySchedulesHandler)
 
 			// Session endpoints
-			pro.Post("/sessions", s.CreateSessionHandler)
-			pro.Delete("/sessions/{id}", s.DeleteSessionHandler)
-			pro.Post("/sessions/cancel/{id}", s.CancelSessionHandler)
+			pro.With(RequireRole(auth.TRAINER, auth.ADMIN)).Post("/sessions", s.CreateSessionHandler)
+			pro.With(admin).Post("/sessions/generate", s.GenerateSessionsHandler)
+			pro.With(s.RequireOwnerOrRole(s.sessionOwner("id"), auth.ADMIN)).Delete("/sessions/{id}", s.DeleteSessionHandler)
+			pro.With(s.RequireOwnerOrRole(s.sessionOwner("id"), auth.ADMIN)).Post("/sessions/cancel/{id}", s.CancelSessionHandler)
 			pro.Get("/sessions/facility/{facility_id}", s.ListFacilitySessionsHandler)
 			pro.Get("/sessions/trainer/{trainer_id}", s.ListTrainerSessionsHandler)
+			pro.With(s.RequireOwnerOrRole(s.sessionOwner("id"), auth.STAFF, auth.ADMIN)).Get("/sessions/roster/{id}", s.GetSessionRosterHandler)
 
 			// Registration endpoints
 			pro.Post("/registrations", s.CreateRegistrationHandler)
-			pro.Post("/registrations/cancel/{id}", s.CancelRegistrationHandler)
-			pro.Get("/registrations/session/{session_id}", s.ListSessionRegistrationsHandler)
-			pro.Get("/registrations/user/{user_id}", s.ListUserRegistrationsHandler)
+			pro.With(s.RequireOwnerOrRole(s.registrationOwner, auth.ADMIN)).Post("/registrations/cancel/{id}", s.CancelRegistrationHandler)
+			pro.Post("/registrations/waitlist/leave/{session_id}", s.LeaveWaitlistHandler)
+			pro.With(s.RequireOwnerOrRole(s.sessionOwner("session_id"), auth.ADMIN)).Get("/registrations/session/{session_id}", s.ListSessionRegistrationsHandler)
+			pro.With(s.RequireOwnerOrRole(pathUser("user_id"), auth.ADMIN)).Get("/registrations/user/{user_id}", s.ListUserRegistrationsHandler)
+			pro.With(s.RequireAnyOwnerOrRole([]ownerResolver{s.registrationOwner, s.registrationTrainer}, auth.STAFF, auth.ADMIN)).Post("/registrations/checkin/{id}", s.CheckInRegistrationHandler)
+			pro.With(s.RequireAnyOwnerOrRole([]ownerResolver{s.registrationOwner, s.registrationTrainer}, auth.STAFF, auth.ADMIN)).Post("/registrations/checkout/{id}", s.CheckOutRegistrationHandler)
+			pro.With(s.RequireOwnerOrRole(s.registrationTrainer, auth.STAFF, auth.ADMIN)).Post("/registrations/attendance/{id}", s.SetRegistrationAttendanceHandler)
+			pro.With(s.RequireOwnerOrRole(s.registrationOwner, auth.STAFF, auth.ADMIN)).Get("/registrations/checkin-token/{id}", s.GetRegistrationCheckInTokenHandler)
+			pro.With(s.RequireOwnerOrRole(s.registrationOwner, auth.STAFF, auth.ADMIN)).Get("/registrations/checkin-qr/{id}", s.GetRegistrationCheckInQRHandler)
+
+			// QR check-in, whoever scans the code
+			pro.With(RequireRole(auth.TRAINER, auth.STAFF, auth.ADMIN)).Post("/checkin", s.TokenCheckInHandler)
 
 			// Penalty endpoints
-			pro.Post("/penalties", s.CreatePenaltyHandler)
-			pro.Delete("/penalties/{id}", s.DeletePenaltyHandler)
-			pro.Get("/penalties/user/{id}", s.ListPenaltyForUserHandler)
-			pro.Get("/penalties/given/{id}", s.ListGivenPenaltyByUserHandler)
-			pro.Get("/penalties/interval", s.ListPenaltiesIntervalHandler)
+			pro.With(RequireRole(auth.TRAINER, auth.ADMIN)).Post("/penalties", s.CreatePenaltyHandler)
+			pro.With(s.RequireOwnerOrRole(s.penaltyGiver, auth.ADMIN)).Delete("/penalties/{id}", s.DeletePenaltyHandler)
+			pro.With(s.RequireOwnerOrRole(pathUser("id"), auth.ADMIN)).Get("/penalties/user/{id}", s.ListPenaltyForUserHandler)
+			pro.With(RequireRole(auth.TRAINER, auth.ADMIN)).Get("/penalties/given/{id}", s.ListGivenPenaltyByUserHandler)
+			pro.With(admin).Get("/penalties/interval", s.ListPenaltiesIntervalHandler)
+			pro.With(admin).Post("/attendance/close", s.CloseAttendanceHandler)
+
+			// Credit score endpoints
+			pro.With(s.RequireOwnerOrRole(pathUser("id"), auth.ADMIN)).Get("/credit/history/{id}", s.ListCreditHistoryHandler)
+			pro.With(admin).Post("/credit/recover", s.RecoverCreditHandler)
+
+			// Notification inbox of the caller
+			pro.Get("/notifications", s.ListNotificationsHandler)
+			pro.Get("/notifications/unread-count", s.CountUnreadNotificationsHandler)
+			pro.Post("/notifications/read/{id}", s.MarkNotificationReadHandler)
+			pro.Post("/notifications/read-all", s.MarkAllNotificationsReadHandler)
+			pro.Get("/notifications/preferences", s.GetNotificationPreferencesHandler)
+			pro.Put("/notifications/preferences", s.UpdateNotificationPreferencesHandler)
+			pro.Get("/notifications/deliveries/{id}", s.ListNotificationDeliveriesHandler)
+			pro.With(admin).Post("/notifications/deliver", s.DeliverNotificationsHandler)
+
+			// Audit log, admin only
+			pro.With(admin).Get("/audit", s.ListAuditLogHandler)
+
+			// Booking policy endpoints, admin only
+			pro.Route("/policies", func(pol chi.Router) {
+				pol.Use(admin)
+				pol.Post("/", s.CreatePolicyHandler)
+				pol.Get("/", s.ListPoliciesHandler)
+				pol.Get("/effective", s.GetEffectivePolicyHandler)
+				pol.Get("/{id}", s.GetPolicyHandler)
+				pol.Patch("/{id}", s.UpdatePolicyHandler)
+				pol.Delete("/{id}", s.DeletePolicyHandler)
+			})
 		})
 
 	})
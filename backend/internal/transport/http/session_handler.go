@@ -2,7 +2,11 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"strings"
+	"t/internal/auth"
 	"t/internal/transport/dto"
 	"time"
 
@@ -33,8 +37,7 @@ func (s *Server) ListFacilitySessionsHandler(w http.ResponseWriter, r *http.Requ
 
 	sessions, err := s.sessionService.ListFacilitySessions(r.Context(), facilityID, date)
 	if err != nil {
-		s.logger.Error("Failed to list facility sessions", zap.Error(err))
-		respondWithJSON(w, http.StatusInternalServerError, nil, "Failed to list sessions")
+		s.respondWithError(w, err, "Failed to list sessions")
 		return
 	}
 
@@ -70,9 +73,18 @@ func (s *Server) CreateSessionHandler(w http.ResponseWriter, r *http.Request) {
 	// Ensure ID is generated
 	sessionData.ID = uuid.New()
 
+	// trainers can only create sessions for themselves
+	if !hasRole(r.Context(), auth.ADMIN) {
+		userID, err := GetID(r.Context())
+		if err != nil {
+			respondWithJSON(w, http.StatusUnauthorized, nil, "Unauthorized")
+			return
+		}
+		sessionData.TrainerID = userID
+	}
+
 	if err := s.sessionService.CreateSession(r.Context(), *sessionData); err != nil {
-		s.logger.Error("Failed to create session", zap.Error(err))
-		respondWithJSON(w, http.StatusInternalServerError, nil, "Failed to create session")
+		s.respondWithError(w, err, "Failed to create session")
 		return
 	}
 
@@ -87,12 +99,17 @@ func (s *Server) DeleteSessionHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := s.sessionService.DeleteSession(r.Context(), id); err != nil {
-		s.logger.Error("Failed to delete session", zap.Error(err))
-		respondWithJSON(w, http.StatusInternalServerError, nil, "Failed to delete session")
+	canceled, err := s.sessionService.DeleteSession(r.Context(), id)
+	if err != nil {
+		s.respondWithError(w, err, "Failed to delete session")
 		return
 	}
 
+	//sessions with registrations are canceled instead of deleted
+	if canceled != nil {
+		respondWithJSON(w, http.StatusOK, dto.NewCancellationResponse(*canceled), "Session has registrations, canceled instead of deleted")
+		return
+	}
 	respondWithJSON(w, http.StatusOK, nil, "Session deleted successfully")
 }
 
@@ -104,13 +121,24 @@ func (s *Server) CancelSessionHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := s.sessionService.CancelSession(r.Context(), id); err != nil {
-		s.logger.Error("Failed to cancel session", zap.Error(err))
-		respondWithJSON(w, http.StatusInternalServerError, nil, "Failed to cancel session")
+	//the body is optional, without it the session is canceled without a reason
+	var req dto.CancelSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondWithJSON(w, http.StatusBadRequest, nil, "malformed input")
+		return
+	}
+	if err := s.validator.Struct(req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "Validation failed: "+err.Error())
+		return
+	}
+
+	c, err := s.sessionService.CancelSession(r.Context(), id, strings.TrimSpace(req.Reason))
+	if err != nil {
+		s.respondWithError(w, err, "Failed to cancel session")
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, nil, "Session canceled successfully")
+	respondWithJSON(w, http.StatusOK, dto.NewCancellationResponse(c), "Session canceled successfully")
 }
 
 func (s *Server) ListTrainerSessionsHandler(w http.ResponseWriter, r *http.Request) {
@@ -135,8 +163,7 @@ func (s *Server) ListTrainerSessionsHandler(w http.ResponseWriter, r *http.Reque
 
 	sessions, err := s.sessionService.ListTrainerSessions(r.Context(), trainerID, date)
 	if err != nil {
-		s.logger.Error("Failed to list trainer sessions", zap.Error(err))
-		respondWithJSON(w, http.StatusInternalServerError, nil, "Failed to list sessions")
+		s.respondWithError(w, err, "Failed to list sessions")
 		return
 	}
 
@@ -147,3 +174,19 @@ func (s *Server) ListTrainerSessionsHandler(w http.ResponseWriter, r *http.Reque
 
 	respondWithJSON(w, http.StatusOK, response, "Successfully listed sessions")
 }
+
+// GenerateSessionsHandler triggers the session generator right away, admin only
+func (s *Server) GenerateSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	report, err := s.sessionService.GenerateSessions(r.Context())
+	if err != nil {
+		s.respondWithError(w, err, "Failed to generate sessions")
+		return
+	}
+
+	if !report.LockAcquired {
+		respondWithJSON(w, http.StatusConflict, dto.NewGenerationReportResponse(report), "Generation is already running on another instance")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, dto.NewGenerationReportResponse(report), "Successfully generated sessions")
+}
@@ -31,22 +31,9 @@ func (s *Server) CreateTrainerHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	//check if the user is admin
-	isAdmin, err := s.isAdmin(r.Context())
-	if err != nil {
-		s.logger.Warn("failed on checking admin permissions", zap.Error(err))
-		respondWithJSON(w, http.StatusInternalServerError, nil, "failure on checking admin permissions")
-		return
-	}
-	if !isAdmin {
-		respondWithJSON(w, http.StatusForbidden, nil, "required admin access")
-		return
-	}
-
 	//call the service layer
 	if err := s.trainerService.CreateTrainer(r.Context(), userID); err != nil {
-		s.logger.Warn("failed to create the trainer", zap.Error(err))
-		respondWithJSON(w, http.StatusInternalServerError, nil, "failed to create the trainer")
+		s.respondWithError(w, err, "failed to create the trainer")
 		return
 	}
 
@@ -72,7 +59,7 @@ func (s *Server) GetTrainerHandler(w http.ResponseWriter, r *http.Request) {
 	trainer, err := s.trainerService.GetTrainer(r.Context(), trainerID)
 	if err != nil {
 		s.logger.Error("Failed to get trainer", zap.Error(err))
-		respondWithJSON(w, http.StatusNotFound, nil, "Trainer not found")
+		s.respondWithError(w, err, "Failed to get trainer")
 		return
 	}
 
@@ -96,8 +83,7 @@ func (s *Server) ListTrainersHandler(w http.ResponseWriter, r *http.Request) {
 
 	trainers, err := s.trainerService.ListTrainers(r.Context(), offset)
 	if err != nil {
-		s.logger.Error("Failed to list trainers", zap.Error(err))
-		respondWithJSON(w, http.StatusInternalServerError, nil, "Failed to list trainers")
+		s.respondWithError(w, err, "Failed to list trainers")
 		return
 	}
 
@@ -140,26 +126,11 @@ func (s *Server) UpdateTrainerHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if the requesting user is the trainer themselves or an admin
-	requestingUserID, err := GetID(r.Context())
-	if err != nil {
-		s.logger.Error("Failed to get user ID from context", zap.Error(err))
-		respondWithJSON(w, http.StatusUnauthorized, nil, "Unauthorized")
-		return
-	}
-
-	isAdmin, _ := s.isAdmin(r.Context())
-	if !isAdmin && requestingUserID != trainerID {
-		respondWithJSON(w, http.StatusForbidden, nil, "You can only update your own trainer profile")
-		return
-	}
-
 	trainer := updateDto.ToModel()
 	trainer.ID = trainerID
 
 	if err := s.trainerService.UpdateTrainer(r.Context(), trainer); err != nil {
-		s.logger.Error("Failed to update trainer", zap.Error(err))
-		respondWithJSON(w, http.StatusInternalServerError, nil, "Failed to update trainer")
+		s.respondWithError(w, err, "Failed to update trainer")
 		return
 	}
 
@@ -182,21 +153,8 @@ func (s *Server) DeleteTrainerHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if user is admin
-	isAdmin, err := s.isAdmin(r.Context())
-	if err != nil {
-		s.logger.Error("Failed to check if user is admin", zap.Error(err))
-		respondWithJSON(w, http.StatusInternalServerError, nil, "Failed to check admin permissions")
-		return
-	}
-	if !isAdmin {
-		respondWithJSON(w, http.StatusForbidden, nil, "Admin access required")
-		return
-	}
-
 	if err := s.trainerService.DeleteTrainer(r.Context(), trainerID); err != nil {
-		s.logger.Error("Failed to delete trainer", zap.Error(err))
-		respondWithJSON(w, http.StatusInternalServerError, nil, "Failed to delete trainer")
+		s.respondWithError(w, err, "Failed to delete trainer")
 		return
 	}
 
@@ -1,13 +1,18 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"t/internal/auth"
+	"t/internal/registration"
 	"t/internal/transport/dto"
+	"t/internal/user"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"go.uber.org/zap"
 )
 
@@ -58,7 +63,7 @@ func (s *Server) CreateUserHandler(w http.ResponseWriter, r *http.Request) {
 			zap.Error(err),
 			zap.Any("userModel", userModel),
 		)
-		respondWithJSON(w, http.StatusInternalServerError, nil, "failed to create user")
+		s.respondWithError(w, err, "failed to create user")
 		return
 	}
 
@@ -66,6 +71,11 @@ func (s *Server) CreateUserHandler(w http.ResponseWriter, r *http.Request) {
 		zap.String("user_id", id.String()),
 	)
 
+	//the account is usable even if the email fails, the user can ask for a new link
+	if err := s.authService.SendVerificationEmail(r.Context(), id); err != nil {
+		s.logger.Error("failed to send the verification email", zap.String("user_id", id.String()), zap.Error(err))
+	}
+
 	respondWithJSON(w, http.StatusOK, map[string]uuid.UUID{"user_id": id}, "user created successfully")
 }
 
@@ -85,7 +95,7 @@ func (s *Server) WhoAmI(w http.ResponseWriter, r *http.Request) {
 			zap.String("user_id", id.String()),
 			zap.Error(err),
 		)
-		respondWithJSON(w, http.StatusNotFound, nil, "user not found")
+		s.respondWithError(w, err, "failed to get user")
 		return
 	}
 
@@ -101,7 +111,7 @@ func (s *Server) GetUserHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	if idStr == "" {
 		s.logger.Warn("missing user id")
-		http.Error(w, "missing user id", http.StatusBadRequest)
+		respondWithJSON(w, http.StatusBadRequest, nil, "missing user id")
 		return
 	}
 
@@ -111,7 +121,7 @@ func (s *Server) GetUserHandler(w http.ResponseWriter, r *http.Request) {
 			zap.String("id", idStr),
 			zap.Error(err),
 		)
-		http.Error(w, "invalid id", http.StatusBadRequest)
+		respondWithJSON(w, http.StatusBadRequest, nil, "invalid id")
 		return
 	}
 
@@ -121,7 +131,7 @@ func (s *Server) GetUserHandler(w http.ResponseWriter, r *http.Request) {
 			zap.String("user_id", id.String()),
 			zap.Error(err),
 		)
-		respondWithJSON(w, http.StatusNotFound, nil, "user not found")
+		s.respondWithError(w, err, "failed to get user")
 		return
 	}
 
@@ -135,22 +145,8 @@ func (s *Server) GetUserHandler(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, userResponse, "user found")
 }
 
+// admin only, enforced on the route
 func (s *Server) ListUsersHandler(w http.ResponseWriter, r *http.Request) {
-	//check is the user is admin
-	userID, err := GetID(r.Context())
-	if err != nil {
-		s.logger.Warn("invalid userID from context", zap.Error(err))
-		respondWithJSON(w, http.StatusBadRequest, nil, "invalid userID in context")
-		return
-	}
-
-	// if id's do not mathc then user should be the admin!!!
-	if !s.authService.IsAdmin(r.Context(), userID) {
-		s.logger.Warn("user is not admin")
-		respondWithJSON(w, http.StatusForbidden, nil, "access denied")
-		return
-	}
-
 	//get the offset from the query parameter and convert to int
 	offsetStr := r.URL.Query().Get("offset")
 	if offsetStr == "" {
@@ -168,8 +164,7 @@ func (s *Server) ListUsersHandler(w http.ResponseWriter, r *http.Request) {
 
 	users, err := s.userService.ListUsers(r.Context(), keyword, offset)
 	if err != nil {
-		s.logger.Warn("failed to list users", zap.Error(err))
-		respondWithJSON(w, http.StatusInternalServerError, nil, "failed to list users")
+		s.respondWithError(w, err, "failed to list users")
 		return
 	}
 
@@ -182,3 +177,173 @@ func (s *Server) ListUsersHandler(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, usersDto, "successfully listed users")
 
 }
+
+// UpdateMeHandler lets users change their own names and phone
+func (s *Server) UpdateMeHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := GetID(r.Context())
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, nil, "invalid userID in context")
+		return
+	}
+
+	var req dto.UpdateUserDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "malformed input")
+		return
+	}
+	if err := s.validator.Struct(req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "Validation failed: "+err.Error())
+		return
+	}
+
+	u, err := s.userService.GetByID(r.Context(), id)
+	if err != nil {
+		s.respondWithError(w, err, "failed to get user")
+		return
+	}
+	req.ApplyToUser(&u)
+
+	s.updateUser(w, r, u)
+}
+
+// AdminUpdateUserHandler lets admins change any account including role and is_active, admin only
+func (s *Server) AdminUpdateUserHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "invalid user id")
+		return
+	}
+
+	var req dto.AdminUpdateUserDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "malformed input")
+		return
+	}
+	if err := s.validator.Struct(req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "Validation failed: "+err.Error())
+		return
+	}
+
+	u, err := s.userService.GetByID(r.Context(), id)
+	if err != nil {
+		s.respondWithError(w, err, "failed to get user")
+		return
+	}
+	req.ApplyToUser(&u)
+
+	s.updateUser(w, r, u)
+}
+
+func (s *Server) updateUser(w http.ResponseWriter, r *http.Request, u user.User) {
+	updated, err := s.userService.UpdateUser(r.Context(), u)
+	if err != nil {
+		s.respondWithError(w, err, "failed to update user")
+		return
+	}
+
+	var resp dto.UserResponseDTO
+	resp.FromModel(updated)
+	respondWithJSON(w, http.StatusOK, resp, "user updated successfully")
+}
+
+// ChangePasswordHandler changes the password of the current user, the other sessions are logged out
+func (s *Server) ChangePasswordHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := GetID(r.Context())
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, nil, "invalid userID in context")
+		return
+	}
+	sessionID, err := auth.SessionIDFromContext(r.Context())
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, nil, "invalid session in context")
+		return
+	}
+
+	var req dto.ChangePasswordDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "malformed input")
+		return
+	}
+	if err := s.validator.Struct(req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "Validation failed: "+err.Error())
+		return
+	}
+
+	if err := s.authService.ChangePassword(r.Context(), id, sessionID, req.CurrentPassword, req.NewPassword); err != nil {
+		s.respondWithError(w, err, "failed to change password")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, nil, "password changed successfully")
+}
+
+// DeactivateMeHandler is the self-service soft delete, an admin can reactivate the account later
+func (s *Server) DeactivateMeHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := GetID(r.Context())
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, nil, "invalid userID in context")
+		return
+	}
+
+	if err := s.userService.Deactivate(r.Context(), id); err != nil {
+		s.respondWithError(w, err, "failed to deactivate user")
+		return
+	}
+	if err := s.authService.LogoutAll(r.Context(), id); err != nil {
+		s.logger.Error("failed to revoke sessions", zap.String("user_id", id.String()), zap.Error(err))
+	}
+	respondWithJSON(w, http.StatusOK, nil, "account deactivated")
+}
+
+// DeleteMeHandler erases the account of the current user, the password is asked again
+func (s *Server) DeleteMeHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := GetID(r.Context())
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, nil, "invalid userID in context")
+		return
+	}
+
+	var req dto.DeleteAccountDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "malformed input")
+		return
+	}
+	if err := s.validator.Struct(req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "Validation failed: "+err.Error())
+		return
+	}
+	if err := s.authService.CheckPassword(r.Context(), id, req.Password); err != nil {
+		s.respondWithError(w, err, "failed to check the password")
+		return
+	}
+
+	s.eraseUser(w, r, id)
+}
+
+// DeleteUserHandler erases any account, admin only
+func (s *Server) DeleteUserHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, nil, "invalid user id")
+		return
+	}
+
+	s.eraseUser(w, r, id)
+}
+
+func (s *Server) eraseUser(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	//the seats are freed in the erasure transaction so the waitlists move up, and nothing changes if the erasure fails
+	var canceled registration.Cancellations
+	err := s.userService.DeleteUser(r.Context(), id, func(ctx context.Context, tx pgx.Tx) error {
+		var err error
+		canceled, err = s.registrationService.CancelUpcomingForUser(ctx, tx, id)
+		return err
+	})
+	if err != nil {
+		s.respondWithError(w, err, "failed to delete user")
+		return
+	}
+	s.registrationService.Announce(r.Context(), canceled)
+
+	s.logger.Info("user erased", zap.String("user_id", id.String()), zap.Int("registrations_canceled", canceled.Len()))
+	respondWithJSON(w, http.StatusOK, nil, "user deleted, bookings and penalties were anonymized")
+}
@@ -2,33 +2,33 @@ package http
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"net/http"
+	"t/internal/auth"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type Response struct {
-	Success bool   `json:"success"`
+	Success bool `json:"success"`
+	// machine readable error code, only set on failures, see errorMappings
+	Code    string `json:"code,omitempty"`
 	Message string `json:"message,omitempty"`
 	Data    any    `json:"data,omitempty"`
 }
 
 func respondWithJSON(w http.ResponseWriter, status int, body any, message string) {
-	w.Header().Set("Content-type", "application/json")
-	w.WriteHeader(status)
-
 	resp := Response{
 		Success: status < 400,
 		Message: message,
 		Data:    body,
 	}
-
-	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		http.Error(w, `{"success":false,"message":"failed to encode response"}`, http.StatusInternalServerError)
-		return
+	if !resp.Success {
+		resp.Code = codeForStatus(status)
 	}
+
+	writeResponse(w, status, resp)
 }
 
 func HashPassword(password string) (string, error) {
@@ -43,33 +43,10 @@ func CheckPasswordHash(password, hash string) bool {
 }
 
 func GetID(ctx context.Context) (uuid.UUID, error) {
-	str := ctx.Value("userID").(string)
-	return uuid.Parse(str)
-
-}
-
-func (s *Server) isAdmin(ctx context.Context) (bool, error) {
-	id, err := GetID(ctx)
-	if err != nil {
-		return false, err
-	}
-
-	//check if the user is admin
-	if !s.authService.IsAdmin(ctx, id) {
-		return false, nil
-	}
-	return true, nil
-}
-
-func (s *Server) isTrainer(ctx context.Context) (bool, error) {
-	id, err := GetID(ctx)
-	if err != nil {
-		return false, err
+	str, ok := ctx.Value(auth.CtxUserID).(string)
+	if !ok {
+		return uuid.Nil, errors.New("no userID in context")
 	}
+	return uuid.Parse(str)
 
-	//check if the user is trainer
-	if !s.authService.IsTrainer(ctx, id) {
-		return false, nil
-	}
-	return true, nil
 }
@@ -0,0 +1,21 @@
+package user
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// DeletedUserID is the placeholder row that inherits the bookings, penalties and reviews of erased users
+var DeletedUserID = uuid.MustParse("00000000-0000-0000-0000-000000000000")
+
+// SystemUserID is the account automatic penalties are given in the name of, when there is no trainer to give them
+var SystemUserID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+var (
+	ErrUserNotFound = errors.New("user not found")
+	// trainers own schedules and sessions, they have to be demoted before they can be erased
+	ErrUserIsTrainer = errors.New("user is a trainer, remove the trainer role first")
+	// the trainer role is managed through the /trainers endpoints because it needs a trainer profile
+	ErrRoleChangeNotAllowed = errors.New("the trainer role can only be granted or removed through the trainers endpoints")
+)
@@ -17,6 +17,10 @@ type User struct {
 	CreditScore int
 	IsActive    bool
 	IsTrainer   bool
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	// incremented on role changes, embedded into the JWT
+	TokenVersion int
+	// nil until the user clicks the link from the verification email
+	EmailVerifiedAt *time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
 }
@@ -2,9 +2,11 @@ package user
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -12,10 +14,14 @@ type UserRepostiory interface {
 	GetByID(context.Context, uuid.UUID) (User, error)
 	GetByEmail(context.Context, string) (uuid.UUID, string, error)
 	CreateUser(context.Context, User) (uuid.UUID, error)
-	DeleteUser(context.Context, uuid.UUID) bool
+	// DeleteUser erases the user, the bookings, penalties and reviews are moved to DeletedUserID. before runs inside
+	// the same transaction once the user is known to be erasable, its error rolls the erasure back
+	DeleteUser(ctx context.Context, id uuid.UUID, before func(context.Context, pgx.Tx) error) error
 	UpdateUser(context.Context, User) (User, error)
 	GetRole(context.Context, uuid.UUID) (string, error)
 	ListUsers(ctx context.Context, email string, offset int) ([]User, error)
+	UpdatePassword(ctx context.Context, id uuid.UUID, hash string) error
+	MarkEmailVerified(ctx context.Context, id uuid.UUID) error
 }
 
 //----------------------- this is the implementation of the userRepo, for now i just have 1 , so can keep in the same file, later might change
@@ -34,7 +40,7 @@ func (u *UserRepositoryPostgres) GetByID(ctx context.Context, id uuid.UUID) (Use
 	query := `
 		SELECT 
 			user_id, email, first_name, last_name, password,
-			 phone, credit_score, role, is_active, created_at, updated_at
+			 phone, credit_score, role, is_active, token_version, email_verified_at, created_at, updated_at
 		FROM users 
 		WHERE user_id = $1
 	`
@@ -49,11 +55,16 @@ func (u *UserRepositoryPostgres) GetByID(ctx context.Context, id uuid.UUID) (Use
 		&user.CreditScore,
 		&user.Role,
 		&user.IsActive,
+		&user.TokenVersion,
+		&user.EmailVerifiedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
 
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return User{}, ErrUserNotFound
+		}
 		return User{}, fmt.Errorf("userRepository.GetByID: %w", err)
 	}
 
@@ -117,9 +128,10 @@ func (u *UserRepositoryPostgres) GetByEmail(ctx context.Context, email string) (
 
 func (u *UserRepositoryPostgres) ListUsers(ctx context.Context, email string, offset int) ([]User, error) {
 	query := `SELECT user_id, email, first_name, last_name, password,
-			 phone, credit_score, role, is_active, created_at, updated_at
+			 phone, credit_score, role, is_active, email_verified_at, created_at, updated_at
 			FROM users
-			WHERE ($1 = '' OR email ILIKE '%' || $1 || '%' OR first_name ILIKE '%' || $1 || '%')
+			WHERE user_id NOT IN ('00000000-0000-0000-0000-000000000000', '00000000-0000-0000-0000-000000000001')
+			  AND ($1 = '' OR email ILIKE '%' || $1 || '%' OR first_name ILIKE '%' || $1 || '%')
 			ORDER BY created_at DESC
 			OFFSET $2
 			LIMIT  $3`
@@ -143,6 +155,7 @@ func (u *UserRepositoryPostgres) ListUsers(ctx context.Context, email string, of
 			&user.CreditScore,
 			&user.Role,
 			&user.IsActive,
+			&user.EmailVerifiedAt,
 			&user.CreatedAt,
 			&user.UpdatedAt,
 		)
@@ -155,11 +168,83 @@ func (u *UserRepositoryPostgres) ListUsers(ctx context.Context, email string, of
 
 }
 
-func (u *UserRepositoryPostgres) DeleteUser(ctx context.Context, id uuid.UUID) bool {
-	return false
+func (u *UserRepositoryPostgres) DeleteUser(ctx context.Context, id uuid.UUID, before func(context.Context, pgx.Tx) error) error {
+	//serializable like the registrations, before may promote waitlists
+	tx, err := u.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable})
+	if err != nil {
+		return fmt.Errorf("repository.DeleteUser: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var isTrainer bool
+	err = tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM trainers WHERE trainer_id = $1)`, id).Scan(&isTrainer)
+	if err != nil {
+		return fmt.Errorf("repository.DeleteUser: %w", err)
+	}
+	if isTrainer {
+		return ErrUserIsTrainer
+	}
+
+	if before != nil {
+		if err := before(ctx, tx); err != nil {
+			return err
+		}
+	}
+
+	//every statement gets only the arguments it uses, pgx refuses extra ones
+	steps := []struct {
+		query string
+		args  []any
+	}{
+		//upcoming bookings free their slot, the past ones are kept for the statistics
+		{`UPDATE bookings SET is_canceled = TRUE, admin_note = 'user deleted', updated_at = NOW()
+		  WHERE user_id = $1 AND date >= CURRENT_DATE AND is_canceled = FALSE`, []any{id}},
+		{`UPDATE bookings SET user_id = $2, note = NULL WHERE user_id = $1`, []any{id, DeletedUserID}},
+		{`UPDATE user_penalties SET user_id = $2 WHERE user_id = $1`, []any{id, DeletedUserID}},
+		{`UPDATE user_penalties SET given_by_id = $2 WHERE given_by_id = $1`, []any{id, DeletedUserID}},
+		{`UPDATE facility_review SET user_id = $2, comment = NULL WHERE user_id = $1`, []any{id, DeletedUserID}},
+		//registrations are unique per (session, user), so they cannot be merged into the placeholder
+		{`DELETE FROM training_session_register WHERE user_id = $1`, []any{id}},
+		//the audit log keeps what the user did but forgets where from, the entry of the erasure itself is written
+		//afterwards and keeps them as the record of the request
+		{`UPDATE audit_log SET ip = '', user_agent = '' WHERE actor_id = $1 AND (ip <> '' OR user_agent <> '')`, []any{id}},
+	}
+	for _, step := range steps {
+		if _, err := tx.Exec(ctx, step.query, step.args...); err != nil {
+			return fmt.Errorf("repository.DeleteUser: %w", err)
+		}
+	}
+
+	tag, err := tx.Exec(ctx, `DELETE FROM users WHERE user_id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("repository.DeleteUser: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+
+	return tx.Commit(ctx)
 }
+
+// UpdateUser saves the profile fields, role and is_active. Changing the role bumps token_version,
+// so the tokens issued with the old role stop working
 func (u *UserRepositoryPostgres) UpdateUser(ctx context.Context, user User) (User, error) {
-	return User{}, nil
+	query := `UPDATE users
+			  SET first_name = $2, last_name = $3, phone = $4, role = $5, is_active = $6,
+			      token_version = token_version + CASE WHEN role <> $5::role THEN 1 ELSE 0 END,
+			      updated_at = NOW()
+			  WHERE user_id = $1
+			  RETURNING token_version, updated_at`
+
+	err := u.pool.QueryRow(ctx, query, user.ID, user.FirstName, user.LastName, user.Phone, user.Role, user.IsActive).
+		Scan(&user.TokenVersion, &user.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return User{}, ErrUserNotFound
+		}
+		return User{}, fmt.Errorf("repository.UpdateUser: %w", err)
+	}
+	return user, nil
 }
 
 func (u *UserRepositoryPostgres) GetRole(ctx context.Context, id uuid.UUID) (string, error) {
@@ -173,3 +258,25 @@ func (u *UserRepositoryPostgres) GetRole(ctx context.Context, id uuid.UUID) (str
 	}
 	return role, nil
 }
+
+func (u *UserRepositoryPostgres) UpdatePassword(ctx context.Context, id uuid.UUID, hash string) error {
+	query := `UPDATE users SET password = $2, updated_at = NOW() WHERE user_id = $1`
+
+	tag, err := u.pool.Exec(ctx, query, id, hash)
+	if err != nil {
+		return fmt.Errorf("repository.UpdatePassword: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("repository.UpdatePassword: user %s not found", id)
+	}
+	return nil
+}
+
+func (u *UserRepositoryPostgres) MarkEmailVerified(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE users SET email_verified_at = COALESCE(email_verified_at, NOW()), updated_at = NOW() WHERE user_id = $1`
+
+	if _, err := u.pool.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("repository.MarkEmailVerified: %w", err)
+	}
+	return nil
+}
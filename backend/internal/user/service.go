@@ -2,16 +2,20 @@ package user
 
 import (
 	"context"
+	"fmt"
+	"t/internal/audit"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 type UserService struct {
 	userRepo UserRepostiory
+	audit    audit.Recorder
 }
 
-func NewUserService(usrRep UserRepostiory) *UserService {
-	return &UserService{userRepo: usrRep}
+func NewUserService(usrRep UserRepostiory, auditRec audit.Recorder) *UserService {
+	return &UserService{userRepo: usrRep, audit: auditRec}
 }
 
 func (s *UserService) CraeteUser(ctx context.Context, user User) (uuid.UUID, error) {
@@ -21,6 +25,8 @@ func (s *UserService) CraeteUser(ctx context.Context, user User) (uuid.UUID, err
 	if err != nil {
 		return uuid.UUID{}, err
 	}
+	user.ID = id
+	s.audit.Record(ctx, "user.create", "user", id, nil, newAuditUser(user))
 	return id, nil
 }
 
@@ -36,3 +42,72 @@ func (s *UserService) GetByID(ctx context.Context, id uuid.UUID) (User, error) {
 func (s *UserService) ListUsers(ctx context.Context, email string, offset int) ([]User, error) {
 	return s.userRepo.ListUsers(ctx, email, offset)
 }
+
+// UpdateUser saves the changed profile, role and active flag of the user
+func (s *UserService) UpdateUser(ctx context.Context, updated User) (User, error) {
+	current, err := s.userRepo.GetByID(ctx, updated.ID)
+	if err != nil {
+		return User{}, err
+	}
+
+	if current.Role != updated.Role && (current.Role == "trainer" || updated.Role == "trainer") {
+		return User{}, ErrRoleChangeNotAllowed
+	}
+
+	u, err := s.userRepo.UpdateUser(ctx, updated)
+	if err != nil {
+		return User{}, fmt.Errorf("UpdateUser: %w", err)
+	}
+	s.audit.Record(ctx, "user.update", "user", u.ID, newAuditUser(current), newAuditUser(u))
+	return u, nil
+}
+
+// Deactivate is the soft delete, the account and its history stay but the user cannot log in anymore
+func (s *UserService) Deactivate(ctx context.Context, id uuid.UUID) error {
+	u, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	before := newAuditUser(u)
+	u.IsActive = false
+	if _, err := s.userRepo.UpdateUser(ctx, u); err != nil {
+		return fmt.Errorf("Deactivate: %w", err)
+	}
+	s.audit.Record(ctx, "user.deactivate", "user", id, before, newAuditUser(u))
+	return nil
+}
+
+// DeleteUser erases the personal data of the user for good, see UserRepostiory.DeleteUser. before runs in the erasure
+// transaction (the registrations are canceled there), so a failed erasure leaves the account as it was
+func (s *UserService) DeleteUser(ctx context.Context, id uuid.UUID, before func(context.Context, pgx.Tx) error) error {
+	if id == DeletedUserID || id == SystemUserID {
+		return ErrUserNotFound
+	}
+	if err := s.userRepo.DeleteUser(ctx, id, before); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "user.delete", "user", id, nil, nil)
+	return nil
+}
+
+// auditUser is what the audit log keeps of a user. names, email, phone and the password hash stay out,
+// the log is append-only and erasure can only clear the ip and user agent of the entries
+type auditUser struct {
+	ID            uuid.UUID
+	Role          string
+	IsActive      bool
+	IsTrainer     bool
+	CreditScore   int
+	EmailVerified bool
+}
+
+func newAuditUser(u User) auditUser {
+	return auditUser{
+		ID:            u.ID,
+		Role:          u.Role,
+		IsActive:      u.IsActive,
+		IsTrainer:     u.IsTrainer,
+		CreditScore:   u.CreditScore,
+		EmailVerified: u.EmailVerifiedAt != nil,
+	}
+}
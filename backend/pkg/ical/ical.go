@@ -0,0 +1,166 @@
+// Package ical reads the events of an iCalendar (RFC 5545) file, enough to import holiday calendars.
+// only the date part of DTSTART/DTEND is used, AllDay tells the all-day events from the timed ones and recurring
+// events are returned with Recurring set and their first date
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCalendar = errors.New("invalid iCalendar file")
+
+// Event is one VEVENT, Start and End are dates and both inclusive. AllDay is set for DATE values and for events
+// running from a local midnight to a local midnight, timed events only carry the dates they touch
+type Event struct {
+	UID       string
+	Summary   string
+	Start     time.Time
+	End       time.Time
+	AllDay    bool
+	Recurring bool
+}
+
+// Parse reads every VEVENT of the calendar
+func Parse(r io.Reader) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]Event, 0)
+	var cur *Event
+	var start, end value
+	var endSet, seenCalendar bool
+	for n, line := range lines {
+		name, params, raw := split(line)
+		switch {
+		case name == "BEGIN" && raw == "VCALENDAR":
+			seenCalendar = true
+		case name == "BEGIN" && raw == "VEVENT":
+			cur = &Event{}
+			start, end, endSet = value{}, value{}, false
+		case name == "END" && raw == "VEVENT":
+			if cur == nil {
+				return nil, fmt.Errorf("%w: line %d: END:VEVENT without BEGIN", ErrInvalidCalendar, n+1)
+			}
+			if start.date.IsZero() {
+				return nil, fmt.Errorf("%w: event %q has no DTSTART", ErrInvalidCalendar, cur.Summary)
+			}
+			cur.Start, cur.End = start.date, start.date
+			switch {
+			case start.isDate:
+				cur.AllDay = true
+				if endSet {
+					//DTEND of all-day events is the day after the last one
+					cur.End = end.date.AddDate(0, 0, -1)
+				}
+			case start.localMidnight() && endSet && end.localMidnight() && end.date.After(start.date):
+				//exported as midnight to midnight, the end is exclusive just the same
+				cur.AllDay = true
+				cur.End = end.date.AddDate(0, 0, -1)
+			case endSet:
+				cur.End = end.date
+				if end.midnight {
+					//a timed event ending at midnight does not touch the next day
+					cur.End = cur.End.AddDate(0, 0, -1)
+				}
+			}
+			if cur.End.Before(cur.Start) {
+				cur.End = cur.Start
+			}
+			events = append(events, *cur)
+			cur = nil
+		case cur == nil:
+			//calendar properties, timezones and other components
+		case name == "UID":
+			cur.UID = raw
+		case name == "SUMMARY":
+			cur.Summary = unescape(raw)
+		case name == "DTSTART":
+			v, err := parseValue(params, raw)
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidCalendar, n+1, err)
+			}
+			start = v
+		case name == "DTEND":
+			v, err := parseValue(params, raw)
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidCalendar, n+1, err)
+			}
+			end, endSet = v, true
+		case name == "RRULE" || name == "RDATE":
+			cur.Recurring = true
+		}
+	}
+
+	if !seenCalendar {
+		return nil, fmt.Errorf("%w: no BEGIN:VCALENDAR", ErrInvalidCalendar)
+	}
+	return events, nil
+}
+
+// unfold joins the continuation lines (starting with a space or a tab) to the line before them
+func unfold(r io.Reader) ([]string, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	lines := make([]string, 0)
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCalendar, err)
+	}
+	return lines, nil
+}
+
+// split cuts "DTSTART;VALUE=DATE:20250101" into the upper-cased name, the parameters and the value
+func split(line string) (string, string, string) {
+	head, value, _ := strings.Cut(line, ":")
+	name, params, _ := strings.Cut(head, ";")
+	return strings.ToUpper(name), strings.ToUpper(params), value
+}
+
+// value is a parsed DATE or DATE-TIME, only its date is kept
+type value struct {
+	date     time.Time
+	isDate   bool // a DATE, the event takes whole days
+	midnight bool // a DATE-TIME at 00:00:00
+	utc      bool // a DATE-TIME in UTC ("Z"), its date is not the local one
+}
+
+// localMidnight is a DATE-TIME at 00:00:00 in the calendar's own time zone
+func (v value) localMidnight() bool {
+	return v.midnight && !v.utc
+}
+
+func parseValue(params, raw string) (value, error) {
+	if len(raw) < 8 {
+		return value{}, fmt.Errorf("bad date %q", raw)
+	}
+	d, err := time.Parse("20060102", raw[:8])
+	if err != nil {
+		return value{}, fmt.Errorf("bad date %q", raw)
+	}
+	return value{
+		date:     d,
+		isDate:   len(raw) == 8 || (strings.Contains(params, "VALUE=DATE") && !strings.Contains(params, "VALUE=DATE-TIME")),
+		midnight: len(raw) >= 15 && raw[8] == 'T' && raw[9:15] == "000000",
+		utc:      strings.HasSuffix(raw, "Z"),
+	}, nil
+}
+
+func unescape(s string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(s)
+}
@@ -0,0 +1,106 @@
+package ical
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func calendar(lines ...string) string {
+	return "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" + strings.Join(lines, "\r\n") + "\r\nEND:VCALENDAR\r\n"
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		event []string
+		want  Event
+	}{
+		{
+			name:  "all-day single date without DTEND",
+			event: []string{"UID:a", "SUMMARY:Founders day", "DTSTART;VALUE=DATE:20251103"},
+			want:  Event{UID: "a", Summary: "Founders day", Start: date("2025-11-03"), End: date("2025-11-03"), AllDay: true},
+		},
+		{
+			name:  "all-day DTEND is exclusive",
+			event: []string{"UID:b", "SUMMARY:Winter break", "DTSTART;VALUE=DATE:20251222", "DTEND;VALUE=DATE:20260103"},
+			want:  Event{UID: "b", Summary: "Winter break", Start: date("2025-12-22"), End: date("2026-01-02"), AllDay: true},
+		},
+		{
+			name:  "bare date values are all-day",
+			event: []string{"SUMMARY:Day off", "DTSTART:20250501", "DTEND:20250502"},
+			want:  Event{Summary: "Day off", Start: date("2025-05-01"), End: date("2025-05-01"), AllDay: true},
+		},
+		{
+			name:  "local midnight to midnight is all-day",
+			event: []string{"SUMMARY:Reading week", "DTSTART;TZID=Europe/Prague:20250310T000000", "DTEND;TZID=Europe/Prague:20250315T000000"},
+			want:  Event{Summary: "Reading week", Start: date("2025-03-10"), End: date("2025-03-14"), AllDay: true},
+		},
+		{
+			name:  "timed event",
+			event: []string{"SUMMARY:Exam", "DTSTART:20250612T090000", "DTEND:20250612T110000"},
+			want:  Event{Summary: "Exam", Start: date("2025-06-12"), End: date("2025-06-12")},
+		},
+		{
+			name:  "utc midnight is not a local all-day event",
+			event: []string{"SUMMARY:Maintenance", "DTSTART:20250612T000000Z", "DTEND:20250613T000000Z"},
+			want:  Event{Summary: "Maintenance", Start: date("2025-06-12"), End: date("2025-06-12")},
+		},
+		{
+			name:  "recurring event",
+			event: []string{"UID:c", "SUMMARY:Weekly cleaning", "DTSTART;VALUE=DATE:20250106", "RRULE:FREQ=WEEKLY;BYDAY=MO"},
+			want:  Event{UID: "c", Summary: "Weekly cleaning", Start: date("2025-01-06"), End: date("2025-01-06"), AllDay: true, Recurring: true},
+		},
+		{
+			name:  "folded lines and escaped text",
+			event: []string{"UID:d", "SUMMARY:Exam\\, spring", "  break", "DTSTART;VALUE=DATE:20250414", "DTEND;VALUE=DATE:2025", "\t0419"},
+			want:  Event{UID: "d", Summary: "Exam, spring break", Start: date("2025-04-14"), End: date("2025-04-18"), AllDay: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := append([]string{"BEGIN:VEVENT"}, tt.event...)
+			lines = append(lines, "END:VEVENT")
+			events, err := Parse(strings.NewReader(calendar(lines...)))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if len(events) != 1 {
+				t.Fatalf("got %d events, want 1", len(events))
+			}
+			if got := events[0]; got != tt.want {
+				t.Errorf("got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"no calendar", "BEGIN:VEVENT\r\nDTSTART:20250101\r\nEND:VEVENT\r\n"},
+		{"no DTSTART", calendar("BEGIN:VEVENT", "SUMMARY:x", "END:VEVENT")},
+		{"bad date", calendar("BEGIN:VEVENT", "DTSTART:2025-01-01", "END:VEVENT")},
+		{"END without BEGIN", calendar("END:VEVENT")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(strings.NewReader(tt.input)); !errors.Is(err, ErrInvalidCalendar) {
+				t.Errorf("got %v, want ErrInvalidCalendar", err)
+			}
+		})
+	}
+}
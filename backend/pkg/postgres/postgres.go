@@ -2,8 +2,10 @@ package postgres
 
 import (
 	"context"
+	"fmt"
 	"log"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -23,3 +25,22 @@ func New(ctx context.Context, connString string) *pgxpool.Pool {
 
 	return pool
 }
+
+// TryAdvisoryXactLock tries to take a transaction scoped advisory lock, the lock is released on commit/rollback.
+// returns false if some other transaction (possibly on another replica) already holds it
+func TryAdvisoryXactLock(ctx context.Context, tx pgx.Tx, key int64) (bool, error) {
+	var ok bool
+	err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`, key).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("TryAdvisoryXactLock: %w", err)
+	}
+	return ok, nil
+}
+
+// AdvisoryXactLock waits for a transaction scoped advisory lock, the lock is released on commit/rollback
+func AdvisoryXactLock(ctx context.Context, tx pgx.Tx, key int64) error {
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, key); err != nil {
+		return fmt.Errorf("AdvisoryXactLock: %w", err)
+	}
+	return nil
+}
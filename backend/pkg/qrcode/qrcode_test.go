@@ -0,0 +1,320 @@
+package qrcode
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReedSolomonDivisor(t *testing.T) {
+	tests := []struct {
+		degree int
+		want   []byte
+	}{
+		//a^87, a^229, a^146, a^149, a^238, a^102, a^21 from the generator polynomial table of the standard
+		{7, []byte{0x7F, 0x7A, 0x9A, 0xA4, 0x0B, 0x44, 0x75}},
+		{2, []byte{0x03, 0x02}},
+	}
+	for _, tt := range tests {
+		if got := reedSolomonDivisor(tt.degree); !bytes.Equal(got, tt.want) {
+			t.Errorf("reedSolomonDivisor(%d) = % X, want % X", tt.degree, got, tt.want)
+		}
+	}
+}
+
+func TestReedSolomonRemainder(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		ecc  int
+		want []byte
+	}{
+		{
+			//"01234567" in numeric mode, 1-M, the worked example of ISO/IEC 18004 annex I
+			name: "01234567 1-M",
+			data: []byte{0x10, 0x20, 0x0C, 0x56, 0x61, 0x80, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11},
+			ecc:  10,
+			want: []byte{0xA5, 0x24, 0xD4, 0xC1, 0xED, 0x36, 0xC7, 0x87, 0x2C, 0x55},
+		},
+		{
+			//"HELLO WORLD" in alphanumeric mode, 1-M
+			name: "HELLO WORLD 1-M",
+			data: []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17},
+			ecc:  10,
+			want: []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23},
+		},
+		{
+			name: "zeros",
+			data: make([]byte, 9),
+			ecc:  17,
+			want: make([]byte, 17),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := reedSolomonRemainder(tt.data, reedSolomonDivisor(tt.ecc))
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("got % X, want % X", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatInfo(t *testing.T) {
+	//the format information table of the standard (annex C)
+	tests := []struct {
+		level Level
+		mask  int
+		want  string
+	}{
+		{L, 0, "111011111000100"},
+		{L, 4, "110011000101111"},
+		{L, 7, "110100101110110"},
+		{M, 0, "101010000010010"},
+		{M, 3, "101101101001011"},
+		{M, 5, "100000011001110"},
+		{Q, 0, "011010101011111"},
+		{Q, 6, "010111011011010"},
+		{H, 0, "001011010001001"},
+		{H, 7, "000100000111011"},
+	}
+	for _, tt := range tests {
+		if got := formatInfo(tt.level, tt.mask); got != parseBits(tt.want) {
+			t.Errorf("formatInfo(%d, %d) = %015b, want %s", tt.level, tt.mask, got, tt.want)
+		}
+	}
+}
+
+func TestVersionInfo(t *testing.T) {
+	//the version information table of the standard (annex D)
+	tests := []struct {
+		version int
+		want    string
+	}{
+		{7, "000111110010010100"},
+		{8, "001000010110111100"},
+		{9, "001001101010011001"},
+		{10, "001010010011010011"},
+	}
+	for _, tt := range tests {
+		if got := versionInfo(tt.version); got != parseBits(tt.want) {
+			t.Errorf("versionInfo(%d) = %018b, want %s", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestDataCodewords(t *testing.T) {
+	//byte mode "A": 0100 00000001 01000001 0000, then the padding bytes
+	want := []byte{0x40, 0x14, 0x10, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC}
+	if got := dataCodewords([]byte("A"), 1, M); !bytes.Equal(got, want) {
+		t.Errorf("got % X, want % X", got, want)
+	}
+}
+
+func TestEncodeMatrix(t *testing.T) {
+	//"A" at level M, version 1 with mask 3
+	want := []string{
+		"#######.#..#..#######",
+		"#.....#.#####.#.....#",
+		"#.###.#...#.#.#.###.#",
+		"#.###.#.##.##.#.###.#",
+		"#.###.#..###..#.###.#",
+		"#.....#..#.##.#.....#",
+		"#######.#.#.#.#######",
+		"........##.##........",
+		"#.##.###..###.#..#.##",
+		".#.##..#.#.####..#...",
+		"##..###.##.#.....##.#",
+		"#.##.#.....#..#####..",
+		"#..####..#..#..#..#..",
+		"........#.##..#..#..#",
+		"#######.#..##..#.#...",
+		"#.....#.##.....##.##.",
+		"#.###.#..##.#####...#",
+		"#.###.#.####..######.",
+		"#.###.#.#.#.#.##.....",
+		"#.....#...#..#.#..#.#",
+		"#######.#....#..#....",
+	}
+
+	c, err := Encode([]byte("A"), M)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Version != 1 || c.Size != 21 {
+		t.Fatalf("got version %d size %d, want 1 and 21", c.Version, c.Size)
+	}
+	for y, row := range want {
+		var got strings.Builder
+		for x := 0; x < c.Size; x++ {
+			if c.Dark(x, y) {
+				got.WriteByte('#')
+			} else {
+				got.WriteByte('.')
+			}
+		}
+		if got.String() != row {
+			t.Errorf("row %2d = %s, want %s", y, got.String(), row)
+		}
+	}
+}
+
+func TestEncodeReadBack(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		level   Level
+		version int
+	}{
+		{"one byte", "A", M, 1},
+		{"check-in token", "AXJ7bXQwd3k3OWZ1bmRhbWVudGFscwAAAABnxk3A.Zm9vYmFyYmF6cXV4", M, 4},
+		{"high", "https://example.com/attendance", H, 4},
+		{"version info", strings.Repeat("x", 120), M, 7},
+		{"largest", strings.Repeat("y", 213), M, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Encode([]byte(tt.data), tt.level)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.Version != tt.version {
+				t.Fatalf("version = %d, want %d", c.Version, tt.version)
+			}
+
+			level, mask := readFormat(t, c)
+			if level != tt.level {
+				t.Fatalf("format level = %d, want %d", level, tt.level)
+			}
+			if c.Version >= 7 {
+				if got := readVersion(c); got != versionInfo(c.Version) {
+					t.Errorf("version bits = %018b, want %018b", got, versionInfo(c.Version))
+				}
+			}
+
+			want := addEccAndInterleave(dataCodewords([]byte(tt.data), tt.version, tt.level), tt.version, tt.level)
+			if got := readCodewords(c, mask, len(want)); !bytes.Equal(got, want) {
+				t.Errorf("codewords read back differ from the encoded ones\ngot  % X\nwant % X", got, want)
+			}
+		})
+	}
+}
+
+func TestEncodeTooLong(t *testing.T) {
+	if _, err := Encode(make([]byte, 214), M); err != ErrDataTooLong {
+		t.Errorf("got %v, want ErrDataTooLong", err)
+	}
+}
+
+// readFormat reads both copies of the format information and looks up the level and the mask
+func readFormat(t *testing.T, c *Code) (Level, int) {
+	t.Helper()
+	first, second := 0, 0
+	for i := 0; i < 15; i++ {
+		var x, y int
+		switch {
+		case i <= 5:
+			x, y = 8, i
+		case i == 6:
+			x, y = 8, 7
+		case i == 7:
+			x, y = 8, 8
+		case i == 8:
+			x, y = 7, 8
+		default:
+			x, y = 14-i, 8
+		}
+		if c.Dark(x, y) {
+			first |= 1 << i
+		}
+
+		if i < 8 {
+			x, y = c.Size-1-i, 8
+		} else {
+			x, y = 8, c.Size-15+i
+		}
+		if c.Dark(x, y) {
+			second |= 1 << i
+		}
+	}
+	if first != second {
+		t.Fatalf("format copies differ: %015b and %015b", first, second)
+	}
+	if !c.Dark(8, c.Size-8) {
+		t.Errorf("the dark module is light")
+	}
+
+	for _, level := range []Level{L, M, Q, H} {
+		for mask := 0; mask < 8; mask++ {
+			if formatInfo(level, mask) == first {
+				return level, mask
+			}
+		}
+	}
+	t.Fatalf("unknown format information %015b", first)
+	return 0, 0
+}
+
+func readVersion(c *Code) int {
+	bits := 0
+	for i := 0; i < 18; i++ {
+		if c.Dark(c.Size-11+i%3, i/3) {
+			bits |= 1 << i
+		}
+	}
+	return bits
+}
+
+// readCodewords undoes the mask and reads the data modules in the zigzag order, the way a scanner would
+func readCodewords(c *Code, mask int, n int) []byte {
+	//a fresh symbol of the same version tells which modules are function patterns
+	ref := &Code{Version: c.Version, Size: c.Size, modules: grid(c.Size), isFunction: grid(c.Size)}
+	ref.drawFunctionPatterns(L)
+
+	masks := [8]func(x, y int) bool{
+		func(x, y int) bool { return (x+y)%2 == 0 },
+		func(x, y int) bool { return y%2 == 0 },
+		func(x, y int) bool { return x%3 == 0 },
+		func(x, y int) bool { return (x+y)%3 == 0 },
+		func(x, y int) bool { return (y/2+x/3)%2 == 0 },
+		func(x, y int) bool { return (x*y)%2+(x*y)%3 == 0 },
+		func(x, y int) bool { return ((x*y)%2+(x*y)%3)%2 == 0 },
+		func(x, y int) bool { return ((x+y)%2+(x*y)%3)%2 == 0 },
+	}
+
+	result := make([]byte, n)
+	i := 0
+	for right := c.Size - 1; right >= 1 && i < n*8; right -= 2 {
+		if right == 6 {
+			right--
+		}
+		upward := ((c.Size-1-right)/2)%2 == 0
+		for k := 0; k < c.Size; k++ {
+			y := k
+			if upward {
+				y = c.Size - 1 - k
+			}
+			for _, x := range []int{right, right - 1} {
+				if ref.isFunction[y][x] || i >= n*8 {
+					continue
+				}
+				if c.Dark(x, y) != masks[mask](x, y) {
+					result[i/8] |= 1 << (7 - i%8)
+				}
+				i++
+			}
+		}
+	}
+	return result
+}
+
+func parseBits(s string) int {
+	v := 0
+	for _, r := range s {
+		v <<= 1
+		if r == '1' {
+			v |= 1
+		}
+	}
+	return v
+}
@@ -0,0 +1,202 @@
+// Package rrule understands the subset of RFC 5545 recurrence rules the booking series need:
+// FREQ=DAILY|WEEKLY with INTERVAL, BYDAY (plain weekdays, no ordinals), COUNT and UNTIL (a date).
+// everything works on dates, the time of day is kept by the caller
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidRule = errors.New("invalid recurrence rule")
+
+type Freq string
+
+const (
+	Daily  Freq = "DAILY"
+	Weekly Freq = "WEEKLY"
+)
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// Rule is a parsed recurrence rule, zero Count/Until mean the rule itself has no end
+type Rule struct {
+	Freq     Freq
+	Interval int
+	ByDay    []time.Weekday
+	Count    int
+	Until    time.Time
+}
+
+// Parse reads a rule like "FREQ=WEEKLY;BYDAY=TU,TH;UNTIL=20250630", the "RRULE:" prefix is optional
+func Parse(s string) (Rule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	r := Rule{Interval: 1}
+
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return Rule{}, fmt.Errorf("%w: %q is not KEY=VALUE", ErrInvalidRule, part)
+		}
+
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			switch f := Freq(strings.ToUpper(value)); f {
+			case Daily, Weekly:
+				r.Freq = f
+			default:
+				return Rule{}, fmt.Errorf("%w: FREQ must be DAILY or WEEKLY", ErrInvalidRule)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return Rule{}, fmt.Errorf("%w: INTERVAL must be a positive number", ErrInvalidRule)
+			}
+			r.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return Rule{}, fmt.Errorf("%w: COUNT must be a positive number", ErrInvalidRule)
+			}
+			r.Count = n
+		case "UNTIL":
+			//a date, or a date-time of which only the date is used
+			if len(value) < 8 {
+				return Rule{}, fmt.Errorf("%w: UNTIL must be YYYYMMDD", ErrInvalidRule)
+			}
+			until, err := time.Parse("20060102", value[:8])
+			if err != nil {
+				return Rule{}, fmt.Errorf("%w: UNTIL must be YYYYMMDD", ErrInvalidRule)
+			}
+			r.Until = until
+		case "BYDAY":
+			r.ByDay = r.ByDay[:0]
+			for _, d := range strings.Split(value, ",") {
+				wd, ok := weekdays[strings.ToUpper(d)]
+				if !ok {
+					return Rule{}, fmt.Errorf("%w: unknown BYDAY %q", ErrInvalidRule, d)
+				}
+				r.ByDay = append(r.ByDay, wd)
+			}
+		case "WKST":
+			//weeks always start on monday here
+		default:
+			return Rule{}, fmt.Errorf("%w: %s is not supported", ErrInvalidRule, key)
+		}
+	}
+
+	if r.Freq == "" {
+		return Rule{}, fmt.Errorf("%w: FREQ is required", ErrInvalidRule)
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return Rule{}, fmt.Errorf("%w: COUNT and UNTIL cannot be used together", ErrInvalidRule)
+	}
+	return r, nil
+}
+
+// Dates expands the rule from start (the first possible occurrence) to until, both inclusive, and the earlier of
+// until and the rule's own UNTIL wins. more than max occurrences is an error, so a typo cannot book a decade
+func (r Rule) Dates(start, until time.Time, max int) ([]time.Time, error) {
+	start = dateOnly(start)
+	until = dateOnly(until)
+	if !r.Until.IsZero() && r.Until.Before(until) {
+		until = dateOnly(r.Until)
+	}
+
+	byDay := make(map[time.Weekday]bool, len(r.ByDay))
+	for _, d := range r.ByDay {
+		byDay[d] = true
+	}
+	if len(byDay) == 0 && r.Freq == Weekly {
+		byDay[start.Weekday()] = true
+	}
+
+	dates := make([]time.Time, 0)
+	add := func(d time.Time) (bool, error) {
+		if len(dates) == max {
+			return false, fmt.Errorf("%w: more than %d occurrences", ErrInvalidRule, max)
+		}
+		dates = append(dates, d)
+		return r.Count > 0 && len(dates) == r.Count, nil
+	}
+
+	switch r.Freq {
+	case Daily:
+		for d := start; !d.After(until); d = d.AddDate(0, 0, r.Interval) {
+			if len(byDay) > 0 && !byDay[d.Weekday()] {
+				continue
+			}
+			done, err := add(d)
+			if err != nil || done {
+				return dates, err
+			}
+		}
+	case Weekly:
+		//walk the weeks from the monday of the start week, every Interval-th week is active
+		monday := start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+		for week := monday; !week.After(until); week = week.AddDate(0, 0, 7*r.Interval) {
+			for i := 0; i < 7; i++ {
+				d := week.AddDate(0, 0, i)
+				if d.Before(start) || d.After(until) || !byDay[d.Weekday()] {
+					continue
+				}
+				done, err := add(d)
+				if err != nil || done {
+					return dates, err
+				}
+			}
+		}
+	}
+	return dates, nil
+}
+
+// Occurs reports whether date is an occurrence of the rule expanded from start, without expanding the whole range
+func (r Rule) Occurs(start, date time.Time) bool {
+	start = dateOnly(start)
+	date = dateOnly(date)
+	if date.Before(start) || (!r.Until.IsZero() && date.After(dateOnly(r.Until))) {
+		return false
+	}
+	if r.Count > 0 {
+		//the count is spent by the earlier occurrences, only expanding tells
+		dates, _ := r.Dates(start, date, r.Count)
+		return len(dates) > 0 && dates[len(dates)-1].Equal(date)
+	}
+
+	days := int(date.Sub(start).Hours() / 24)
+	switch r.Freq {
+	case Daily:
+		if days%r.Interval != 0 {
+			return false
+		}
+		return len(r.ByDay) == 0 || slices.Contains(r.ByDay, date.Weekday())
+	case Weekly:
+		byDay := r.ByDay
+		if len(byDay) == 0 {
+			byDay = []time.Weekday{start.Weekday()}
+		}
+		monday := start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+		weeks := int(date.Sub(monday).Hours()/24) / 7
+		return weeks%r.Interval == 0 && slices.Contains(byDay, date.Weekday())
+	}
+	return false
+}
+
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
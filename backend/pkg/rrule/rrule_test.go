@@ -0,0 +1,227 @@
+package rrule
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func dates(ss ...string) []time.Time {
+	result := make([]time.Time, 0, len(ss))
+	for _, s := range ss {
+		result = append(result, date(s))
+	}
+	return result
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		rule string
+		want Rule
+	}{
+		{"FREQ=DAILY", Rule{Freq: Daily, Interval: 1}},
+		{"RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH", Rule{Freq: Weekly, Interval: 2, ByDay: []time.Weekday{time.Tuesday, time.Thursday}}},
+		{"freq=weekly;byday=mo;count=5", Rule{Freq: Weekly, Interval: 1, ByDay: []time.Weekday{time.Monday}, Count: 5}},
+		{"FREQ=WEEKLY;UNTIL=20250630T235959Z;WKST=MO", Rule{Freq: Weekly, Interval: 1, Until: date("2025-06-30")}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.rule)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.rule, err)
+			continue
+		}
+		if got.Freq != tt.want.Freq || got.Interval != tt.want.Interval || got.Count != tt.want.Count ||
+			!got.Until.Equal(tt.want.Until) || !slices.Equal(got.ByDay, tt.want.ByDay) {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.rule, got, tt.want)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, rule := range []string{
+		"",
+		"BYDAY=MO",
+		"FREQ=MONTHLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=-1",
+		"FREQ=DAILY;UNTIL=2025",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=DAILY;COUNT=3;UNTIL=20250630",
+		"FREQ=DAILY;BYMONTH=1",
+		"FREQ",
+	} {
+		if _, err := Parse(rule); !errors.Is(err, ErrInvalidRule) {
+			t.Errorf("Parse(%q) err = %v, want ErrInvalidRule", rule, err)
+		}
+	}
+}
+
+func TestDates(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  string
+		start string // 2025-03-03 is a monday
+		until string
+		want  []time.Time
+	}{
+		{
+			name:  "daily",
+			rule:  "FREQ=DAILY",
+			start: "2025-03-03", until: "2025-03-06",
+			want: dates("2025-03-03", "2025-03-04", "2025-03-05", "2025-03-06"),
+		},
+		{
+			name:  "daily interval",
+			rule:  "FREQ=DAILY;INTERVAL=3",
+			start: "2025-03-03", until: "2025-03-12",
+			want: dates("2025-03-03", "2025-03-06", "2025-03-09", "2025-03-12"),
+		},
+		{
+			name:  "byday on daily",
+			rule:  "FREQ=DAILY;BYDAY=MO,WE,FR",
+			start: "2025-03-04", until: "2025-03-10",
+			want: dates("2025-03-05", "2025-03-07", "2025-03-10"),
+		},
+		{
+			name:  "byday on daily with interval filters the interval days",
+			rule:  "FREQ=DAILY;INTERVAL=2;BYDAY=MO,TU",
+			start: "2025-03-03", until: "2025-03-18",
+			want: dates("2025-03-03", "2025-03-11", "2025-03-17"),
+		},
+		{
+			name:  "weekly on the start weekday",
+			rule:  "FREQ=WEEKLY",
+			start: "2025-03-05", until: "2025-03-26",
+			want: dates("2025-03-05", "2025-03-12", "2025-03-19", "2025-03-26"),
+		},
+		{
+			name:  "weekly byday skips days before start",
+			rule:  "FREQ=WEEKLY;BYDAY=MO,TH",
+			start: "2025-03-05", until: "2025-03-17",
+			want: dates("2025-03-06", "2025-03-10", "2025-03-13", "2025-03-17"),
+		},
+		{
+			name:  "weekly interval 2",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH",
+			start: "2025-03-03", until: "2025-03-31",
+			want: dates("2025-03-04", "2025-03-06", "2025-03-18", "2025-03-20"),
+		},
+		{
+			name:  "weekly interval 3 counts from the start week",
+			rule:  "FREQ=WEEKLY;INTERVAL=3;BYDAY=MO",
+			start: "2025-03-07", until: "2025-04-30",
+			want: dates("2025-03-24", "2025-04-14"),
+		},
+		{
+			name:  "count ends before until",
+			rule:  "FREQ=WEEKLY;BYDAY=MO,FR;COUNT=3",
+			start: "2025-03-03", until: "2025-12-31",
+			want: dates("2025-03-03", "2025-03-07", "2025-03-10"),
+		},
+		{
+			name:  "until ends before count would",
+			rule:  "FREQ=WEEKLY;BYDAY=MO,FR;COUNT=10",
+			start: "2025-03-03", until: "2025-03-08",
+			want: dates("2025-03-03", "2025-03-07"),
+		},
+		{
+			name:  "rule until earlier than the range",
+			rule:  "FREQ=DAILY;UNTIL=20250305",
+			start: "2025-03-03", until: "2025-12-31",
+			want: dates("2025-03-03", "2025-03-04", "2025-03-05"),
+		},
+		{
+			name:  "range earlier than the rule until",
+			rule:  "FREQ=DAILY;UNTIL=20251231",
+			start: "2025-03-03", until: "2025-03-04",
+			want: dates("2025-03-03", "2025-03-04"),
+		},
+		{
+			name:  "one day range",
+			rule:  "FREQ=DAILY",
+			start: "2025-03-03", until: "2025-03-03",
+			want: dates("2025-03-03"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			//the times of day are ignored, only the dates count
+			start := date(tt.start).Add(18 * time.Hour)
+			got, err := r.Dates(start, date(tt.until).Add(9*time.Hour), 100)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.EqualFunc(got, tt.want, time.Time.Equal) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDatesMax(t *testing.T) {
+	r, _ := Parse("FREQ=DAILY")
+	if _, err := r.Dates(date("2025-01-01"), date("2025-01-10"), 10); err != nil {
+		t.Errorf("exactly max occurrences: %v", err)
+	}
+	if _, err := r.Dates(date("2025-01-01"), date("2025-01-11"), 10); !errors.Is(err, ErrInvalidRule) {
+		t.Errorf("more than max occurrences: err = %v, want ErrInvalidRule", err)
+	}
+
+	//a count of max is fine even if the range would be longer
+	r, _ = Parse("FREQ=DAILY;COUNT=10")
+	if got, err := r.Dates(date("2025-01-01"), date("2025-12-31"), 10); err != nil || len(got) != 10 {
+		t.Errorf("count of max: got %d dates, err %v", len(got), err)
+	}
+}
+
+// Occurs must agree with Dates on every day around the expanded range
+func TestOccursMatchesDates(t *testing.T) {
+	rules := []string{
+		"FREQ=DAILY",
+		"FREQ=DAILY;INTERVAL=3",
+		"FREQ=DAILY;BYDAY=SA,SU",
+		"FREQ=DAILY;INTERVAL=2;BYDAY=MO,TU",
+		"FREQ=WEEKLY",
+		"FREQ=WEEKLY;BYDAY=MO,TH",
+		"FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,SU",
+		"FREQ=WEEKLY;INTERVAL=3",
+		"FREQ=WEEKLY;BYDAY=MO,WE;COUNT=5",
+		"FREQ=DAILY;INTERVAL=4;COUNT=7",
+		"FREQ=WEEKLY;INTERVAL=2;BYDAY=FR;UNTIL=20250501",
+	}
+	starts := []time.Time{date("2025-03-03"), date("2025-03-06"), date("2025-03-09")}
+	end := date("2025-06-30")
+
+	for _, s := range rules {
+		r, err := Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, start := range starts {
+			expanded, err := r.Dates(start, end, 1000)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for d := start.AddDate(0, 0, -7); !d.After(end); d = d.AddDate(0, 0, 1) {
+				want := slices.ContainsFunc(expanded, d.Equal)
+				if got := r.Occurs(start, d); got != want {
+					t.Errorf("%s from %s: Occurs(%s) = %v, Dates has it: %v",
+						s, start.Format("2006-01-02"), d.Format("2006-01-02"), got, want)
+				}
+			}
+		}
+	}
+}